@@ -9,8 +9,10 @@ import (
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 
+	"github.com/tsamsiyu/themelio/api/internal/api/grpcserver"
 	"github.com/tsamsiyu/themelio/api/internal/api/server"
 	"github.com/tsamsiyu/themelio/api/internal/app"
+	"github.com/tsamsiyu/themelio/api/internal/config"
 )
 
 func main() {
@@ -22,7 +24,7 @@ func main() {
 	app.Run()
 }
 
-func startServer(srv *server.Server, logger *zap.Logger) {
+func startServer(cfg *config.Config, srv *server.Server, grpcSrv *grpcserver.Server, logger *zap.Logger) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -36,6 +38,15 @@ func startServer(srv *server.Server, logger *zap.Logger) {
 	}()
 
 	logger.Info("Starting Themelio API")
+	if cfg.GRPC.Enabled {
+		go func() {
+			if err := grpcSrv.Start(ctx); err != nil {
+				logger.Error("Failed to start gRPC server", zap.Error(err))
+				cancel()
+			}
+		}()
+	}
+
 	if err := srv.Start(ctx); err != nil {
 		logger.Error("Failed to start server", zap.Error(err))
 		os.Exit(1)
@@ -0,0 +1,72 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/tsamsiyu/themelio/api/internal/api/middleware"
+)
+
+// grpcCodes translates the HTTP statuses middleware.MapError gives errors, anything else is Internal
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest: codes.InvalidArgument,
+	http.StatusForbidden:  codes.PermissionDenied,
+	http.StatusNotFound:   codes.NotFound,
+	http.StatusConflict:   codes.Aborted,
+	http.StatusGone:       codes.OutOfRange,
+}
+
+// unaryErrorInterceptor and streamErrorInterceptor are the gRPC counterparts of middleware.ErrorMapper
+func unaryErrorInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, statusFromError(err, logger)
+		}
+		return resp, nil
+	}
+}
+
+func streamErrorInterceptor(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, stream); err != nil {
+			return statusFromError(err, logger)
+		}
+		return nil
+	}
+}
+
+// statusFromError maps an error to the status of the HTTP response it would get, validation details
+// are carried as BadRequest field violations
+func statusFromError(err error, logger *zap.Logger) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	httpStatus, body := middleware.MapError(err, logger)
+
+	code, ok := grpcCodes[httpStatus]
+	if !ok {
+		code = codes.Internal
+	}
+	message, _ := body["error"].(string)
+	st := status.New(code, message)
+
+	if details, ok := body["details"].([]string); ok && len(details) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(details))
+		for _, detail := range details {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Description: detail})
+		}
+		if withDetails, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+			st = withDetails
+		}
+	}
+
+	return st.Err()
+}
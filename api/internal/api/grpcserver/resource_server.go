@@ -0,0 +1,324 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tsamsiyu/themelio/api/internal/config"
+	internalerrors "github.com/tsamsiyu/themelio/api/internal/errors"
+	"github.com/tsamsiyu/themelio/api/internal/repository"
+	repositorytypes "github.com/tsamsiyu/themelio/api/internal/repository/types"
+	servicetypes "github.com/tsamsiyu/themelio/api/internal/service/types"
+	"github.com/tsamsiyu/themelio/sdk/pkg/grpcapi"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+var watchEventTypes = map[repositorytypes.WatchEventType]grpcapi.WatchEvent_Type{
+	repositorytypes.WatchEventTypeAdded:    grpcapi.WatchEvent_TYPE_ADDED,
+	repositorytypes.WatchEventTypeModified: grpcapi.WatchEvent_TYPE_MODIFIED,
+	repositorytypes.WatchEventTypeDeleted:  grpcapi.WatchEvent_TYPE_DELETED,
+	repositorytypes.WatchEventTypeError:    grpcapi.WatchEvent_TYPE_ERROR,
+	repositorytypes.WatchEventTypeBookmark: grpcapi.WatchEvent_TYPE_BOOKMARK,
+}
+
+// ResourceServer serves the ResourceService of the gRPC API, it mirrors ResourceHandler and WatchHandler
+type ResourceServer struct {
+	grpcapi.UnimplementedResourceServiceServer
+	logger          *zap.Logger
+	resourceService servicetypes.ResourceService
+	adminToken      string
+}
+
+func NewResourceServer(
+	cfg *config.Config,
+	logger *zap.Logger,
+	resourceService servicetypes.ResourceService,
+) *ResourceServer {
+	return &ResourceServer{
+		logger:          logger,
+		resourceService: resourceService,
+		adminToken:      cfg.Server.AdminToken,
+	}
+}
+
+func (s *ResourceServer) ReplaceResource(ctx context.Context, req *grpcapi.ReplaceResourceRequest) (*grpcapi.ReplaceResourceResponse, error) {
+	params, err := paramsFromRequest(req.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.resourceService.ReplaceResource(ctx, params, req.GetObject()); err != nil {
+		return nil, err
+	}
+	return &grpcapi.ReplaceResourceResponse{}, nil
+}
+
+func (s *ResourceServer) GetResource(ctx context.Context, req *grpcapi.GetResourceRequest) (*grpcapi.GetResourceResponse, error) {
+	params, err := paramsFromRequest(req.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.resourceService.GetResource(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := marshalObject(resource)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcapi.GetResourceResponse{Object: data}, nil
+}
+
+func (s *ResourceServer) ListResources(ctx context.Context, req *grpcapi.ListResourcesRequest) (*grpcapi.ListResourcesResponse, error) {
+	params, err := paramsFromRequest(req.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	options := repositorytypes.ListOptions{
+		Limit:       int(req.GetLimit()),
+		Continue:    req.GetContinue(),
+		MinRevision: params.MinRevision,
+	}
+
+	batch, err := s.resourceService.ListResources(ctx, params, options)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([][]byte, 0, len(batch.Objects))
+	for _, object := range batch.Objects {
+		data, err := marshalObject(object)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, data)
+	}
+
+	return &grpcapi.ListResourcesResponse{
+		Items:    items,
+		Revision: batch.Revision,
+		Continue: batch.Continue,
+	}, nil
+}
+
+func (s *ResourceServer) DeleteResource(ctx context.Context, req *grpcapi.DeleteResourceRequest) (*grpcapi.DeleteResourceResponse, error) {
+	params, err := paramsFromRequest(req.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	options := repositorytypes.DeleteOptions{PropagationPolicy: sdkmeta.PropagationPolicy(req.GetPropagationPolicy())}
+	if err := s.resourceService.DeleteResource(ctx, params, options); err != nil {
+		return nil, err
+	}
+	return &grpcapi.DeleteResourceResponse{}, nil
+}
+
+func (s *ResourceServer) PatchResource(ctx context.Context, req *grpcapi.PatchResourceRequest) (*grpcapi.PatchResourceResponse, error) {
+	params, err := paramsFromRequest(req.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.resourceService.PatchResource(ctx, params, req.GetPatch())
+	if err != nil {
+		return nil, err
+	}
+	return patchResponse(resource)
+}
+
+func (s *ResourceServer) ReplaceResourceStatus(ctx context.Context, req *grpcapi.ReplaceResourceRequest) (*grpcapi.ReplaceResourceResponse, error) {
+	params, err := paramsFromRequest(req.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.resourceService.ReplaceResourceStatus(ctx, params, req.GetObject()); err != nil {
+		return nil, err
+	}
+	return &grpcapi.ReplaceResourceResponse{}, nil
+}
+
+func (s *ResourceServer) PatchResourceStatus(ctx context.Context, req *grpcapi.PatchResourceRequest) (*grpcapi.PatchResourceResponse, error) {
+	params, err := paramsFromRequest(req.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.resourceService.PatchResourceStatus(ctx, params, req.GetPatch())
+	if err != nil {
+		return nil, err
+	}
+	return patchResponse(resource)
+}
+
+func (s *ResourceServer) RemoveFinalizer(ctx context.Context, req *grpcapi.RemoveFinalizerRequest) (*grpcapi.RemoveFinalizerResponse, error) {
+	params, err := paramsFromRequest(req.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.resourceService.RemoveFinalizer(ctx, params, req.GetFinalizer())
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := marshalObject(resource)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcapi.RemoveFinalizerResponse{Object: data}, nil
+}
+
+// WatchResource streams the events of the watch, a stream that ends with UNAVAILABLE can be resumed
+// from the last received revision and one that ends with OUT_OF_RANGE needs a new list
+func (s *ResourceServer) WatchResource(req *grpcapi.WatchResourceRequest, stream grpcapi.ResourceService_WatchResourceServer) error {
+	params, err := paramsFromRequest(req.GetParams())
+	if err != nil {
+		return err
+	}
+
+	// every object is watched only with the admin token, the same as the /watch route
+	if params.Kind == "" && params.Namespace == "" && !hasAdminToken(stream.Context(), s.adminToken) {
+		return status.Error(codes.PermissionDenied, "Admin token required")
+	}
+
+	if req.GetSendInitialEvents() && req.GetRevision() > 0 {
+		return internalerrors.NewInvalidInputError("sendInitialEvents can't be combined with revision")
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	options := repositorytypes.WatchOptions{Revision: req.GetRevision(), SendInitialEvents: req.GetSendInitialEvents()}
+	events, err := s.resourceService.WatchResource(ctx, params, options)
+	if err != nil {
+		s.logger.Error("Failed to start gRPC resource watch",
+			zap.String("group", params.Group),
+			zap.String("version", params.Version),
+			zap.String("kind", params.Kind),
+			zap.String("namespace", params.Namespace),
+			zap.Error(err))
+		return err
+	}
+
+	// headers tell the client the watch started before any event arrives
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "Watch ended")
+			}
+
+			if event.Type == repositorytypes.WatchEventTypeError && repository.IsExpiredError(event.Error) {
+				return event.Error
+			}
+
+			message, err := watchEventToProto(event)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func paramsFromRequest(params *grpcapi.ResourceParams) (servicetypes.Params, error) {
+	if params.GetExpectedRevision() < 0 {
+		return servicetypes.Params{}, internalerrors.NewInvalidInputError("invalid expected revision")
+	}
+	if params.GetMinRevision() < 0 {
+		return servicetypes.Params{}, internalerrors.NewInvalidInputError("invalid min revision")
+	}
+
+	return servicetypes.Params{
+		Group:            params.GetGroup(),
+		Version:          params.GetVersion(),
+		Kind:             params.GetKind(),
+		Namespace:        params.GetNamespace(),
+		Name:             params.GetName(),
+		ExpectedRevision: params.GetExpectedRevision(),
+		MinRevision:      params.GetMinRevision(),
+		LabelSelector:    params.GetLabelSelector(),
+		FieldSelector:    params.GetFieldSelector(),
+	}, nil
+}
+
+func patchResponse(resource *sdkmeta.Object) (*grpcapi.PatchResourceResponse, error) {
+	data, err := marshalObject(resource)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcapi.PatchResourceResponse{Object: data}, nil
+}
+
+func watchEventToProto(event repositorytypes.WatchEvent) (*grpcapi.WatchEvent, error) {
+	message := &grpcapi.WatchEvent{
+		Type:             watchEventTypes[event.Type],
+		Revision:         event.Revision,
+		Timestamp:        timestamppb.New(event.Timestamp),
+		InitialEventsEnd: event.InitialEventsEnd,
+		ObjectKey: &grpcapi.ObjectKey{
+			Group:     event.ObjectKey.Group,
+			Version:   event.ObjectKey.Version,
+			Kind:      event.ObjectKey.Kind,
+			Namespace: event.ObjectKey.Namespace,
+			Name:      event.ObjectKey.Name,
+		},
+	}
+
+	if event.Object != nil {
+		data, err := marshalObject(event.Object)
+		if err != nil {
+			return nil, err
+		}
+		message.Object = data
+	}
+
+	if event.Error != nil {
+		message.Error = event.Error.Error()
+	}
+
+	return message, nil
+}
+
+// marshalObject encodes objects the way the REST API returns them
+func marshalObject(object interface{}) ([]byte, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, internalerrors.NewMarshalingError("Failed to marshal resource")
+	}
+	return data, nil
+}
+
+// hasAdminToken reports whether the call carries the admin bearer token, see middleware.HasAdminToken
+func hasAdminToken(ctx context.Context, token string) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		provided := strings.TrimPrefix(value, "Bearer ")
+		if token != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
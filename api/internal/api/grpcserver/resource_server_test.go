@@ -0,0 +1,230 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/tsamsiyu/themelio/api/internal/config"
+	"github.com/tsamsiyu/themelio/api/internal/repository"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	servicetypes "github.com/tsamsiyu/themelio/api/internal/service/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+	"github.com/tsamsiyu/themelio/sdk/pkg/grpcapi"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+func newResourceTestClient(t *testing.T, mockService *mocks.MockResourceService) grpcapi.ResourceServiceClient {
+	cfg := &config.Config{Server: config.ServerConfig{AdminToken: "secret", ShutdownTimeout: time.Second}}
+	logger := zap.NewNop()
+	server := NewServer(cfg, logger, NewResourceServer(cfg, logger, mockService), NewSchemaServer(logger, mocks.NewMockSchemaService(t)))
+
+	listener := bufconn.Listen(1024 * 1024)
+	go server.server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return grpcapi.NewResourceServiceClient(conn)
+}
+
+func TestResourceServer_GetResource(t *testing.T) {
+	mockService := mocks.NewMockResourceService(t)
+	client := newResourceTestClient(t, mockService)
+
+	// Given: A stored object
+	params := servicetypes.Params{Group: "example.com", Version: "v1", Kind: "Network", Name: "main", MinRevision: 7}
+	mockService.EXPECT().GetResource(mock.Anything, params).Return(&sdkmeta.Object{
+		ObjectKey:  &sdkmeta.ObjectKey{Name: "main"},
+		SystemMeta: &sdkmeta.SystemMeta{ModRevision: 42},
+	}, nil)
+
+	// When: It is read over gRPC
+	resp, err := client.GetResource(context.Background(), &grpcapi.GetResourceRequest{Params: &grpcapi.ResourceParams{
+		Group: "example.com", Version: "v1", Kind: "Network", Name: "main", MinRevision: 7,
+	}})
+
+	// Then: The object comes back as the JSON the REST API returns
+	require.NoError(t, err)
+	assert.Contains(t, string(resp.GetObject()), `"name":"main"`)
+	assert.Contains(t, string(resp.GetObject()), `"modRevision":42`)
+}
+
+func TestResourceServer_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "not found", err: repository.NewNotFoundError("/example.com/v1/Network/main"), wantCode: codes.NotFound},
+		{name: "conflict", err: repository.NewConflictError("/example.com/v1/Network/main"), wantCode: codes.Aborted},
+		{name: "expired", err: repository.NewExpiredError(3), wantCode: codes.OutOfRange},
+		{name: "unhandled", err: assert.AnError, wantCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockResourceService(t)
+			client := newResourceTestClient(t, mockService)
+
+			// Given: The service fails
+			mockService.EXPECT().DeleteResource(mock.Anything, mock.Anything, mock.Anything).Return(tt.err)
+
+			// When: The object is deleted over gRPC
+			_, err := client.DeleteResource(context.Background(), &grpcapi.DeleteResourceRequest{Params: &grpcapi.ResourceParams{
+				Group: "example.com", Version: "v1", Kind: "Network", Name: "main",
+			}})
+
+			// Then: The call fails with the code matching the HTTP status of the error
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestResourceServer_ValidationDetails(t *testing.T) {
+	mockService := mocks.NewMockResourceService(t)
+	client := newResourceTestClient(t, mockService)
+
+	// Given: An object that fails validation
+	validationErr := validator.New().Struct(struct {
+		Name string `validate:"required"`
+	}{})
+	mockService.EXPECT().ReplaceResource(mock.Anything, mock.Anything, mock.Anything).Return(validationErr)
+
+	// When: It is replaced over gRPC
+	_, err := client.ReplaceResource(context.Background(), &grpcapi.ReplaceResourceRequest{
+		Params: &grpcapi.ResourceParams{Group: "example.com", Version: "v1", Kind: "Network"},
+		Object: []byte(`{}`),
+	})
+
+	// Then: The failed fields are carried as BadRequest violations
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 1)
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	assert.Len(t, badRequest.GetFieldViolations(), 1)
+}
+
+func TestResourceServer_WatchResource(t *testing.T) {
+	mockService := mocks.NewMockResourceService(t)
+	client := newResourceTestClient(t, mockService)
+
+	// Given: A watch with an event and a bookmark that then ends
+	events := make(chan types.WatchEvent, 2)
+	events <- types.WatchEvent{
+		Type:      types.WatchEventTypeAdded,
+		ObjectKey: sdkmeta.ObjectKey{ObjectType: sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Network"}, Name: "main"},
+		Object:    &sdkmeta.Object{ObjectKey: &sdkmeta.ObjectKey{Name: "main"}},
+		Revision:  10,
+	}
+	events <- types.WatchEvent{Type: types.WatchEventTypeBookmark, Revision: 11}
+	close(events)
+
+	params := servicetypes.Params{Group: "example.com", Version: "v1", Kind: "Network"}
+	mockService.EXPECT().WatchResource(mock.Anything, params, types.WatchOptions{Revision: 5}).
+		Return((<-chan types.WatchEvent)(events), nil)
+
+	// When: The kind is watched over gRPC
+	stream, err := client.WatchResource(context.Background(), &grpcapi.WatchResourceRequest{
+		Params:   &grpcapi.ResourceParams{Group: "example.com", Version: "v1", Kind: "Network"},
+		Revision: 5,
+	})
+	require.NoError(t, err)
+
+	// Then: The events are streamed and the end of the watch is reported as Unavailable
+	added, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, grpcapi.WatchEvent_TYPE_ADDED, added.GetType())
+	assert.Equal(t, "main", added.GetObjectKey().GetName())
+	assert.Equal(t, int64(10), added.GetRevision())
+
+	bookmark, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, grpcapi.WatchEvent_TYPE_BOOKMARK, bookmark.GetType())
+	assert.Equal(t, int64(11), bookmark.GetRevision())
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestResourceServer_WatchResource_Expired(t *testing.T) {
+	mockService := mocks.NewMockResourceService(t)
+	client := newResourceTestClient(t, mockService)
+
+	// Given: A watch from a compacted revision
+	events := make(chan types.WatchEvent, 1)
+	events <- types.WatchEvent{Type: types.WatchEventTypeError, Error: repository.NewExpiredError(3)}
+	mockService.EXPECT().WatchResource(mock.Anything, mock.Anything, mock.Anything).
+		Return((<-chan types.WatchEvent)(events), nil)
+
+	// When: It is opened over gRPC
+	stream, err := client.WatchResource(context.Background(), &grpcapi.WatchResourceRequest{
+		Params:   &grpcapi.ResourceParams{Group: "example.com", Version: "v1", Kind: "Network"},
+		Revision: 3,
+	})
+	require.NoError(t, err)
+
+	// Then: The stream ends with OutOfRange
+	_, err = stream.Recv()
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+}
+
+func TestResourceServer_WatchResource_AdminToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		wantCode codes.Code
+	}{
+		{name: "without token", wantCode: codes.PermissionDenied},
+		{name: "wrong token", token: "other", wantCode: codes.PermissionDenied},
+		{name: "admin token", token: "secret", wantCode: codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockResourceService(t)
+			client := newResourceTestClient(t, mockService)
+
+			if tt.wantCode != codes.PermissionDenied {
+				events := make(chan types.WatchEvent)
+				close(events)
+				mockService.EXPECT().WatchResource(mock.Anything, servicetypes.Params{}, types.WatchOptions{}).
+					Return((<-chan types.WatchEvent)(events), nil)
+			}
+
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tt.token)
+			}
+
+			// When: Every object is watched
+			stream, err := client.WatchResource(ctx, &grpcapi.WatchResourceRequest{Params: &grpcapi.ResourceParams{}})
+			require.NoError(t, err)
+
+			// Then: Only the admin token opens the watch
+			_, err = stream.Recv()
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
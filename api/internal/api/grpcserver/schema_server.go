@@ -0,0 +1,71 @@
+package grpcserver
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	sharedservice "github.com/tsamsiyu/themelio/api/internal/service/shared"
+	"github.com/tsamsiyu/themelio/sdk/pkg/grpcapi"
+)
+
+// SchemaServer serves the SchemaService of the gRPC API, it mirrors SchemaHandler
+type SchemaServer struct {
+	grpcapi.UnimplementedSchemaServiceServer
+	logger        *zap.Logger
+	schemaService sharedservice.SchemaService
+}
+
+func NewSchemaServer(
+	logger *zap.Logger,
+	schemaService sharedservice.SchemaService,
+) *SchemaServer {
+	return &SchemaServer{
+		logger:        logger,
+		schemaService: schemaService,
+	}
+}
+
+func (s *SchemaServer) ReplaceSchema(ctx context.Context, req *grpcapi.ReplaceSchemaRequest) (*grpcapi.ReplaceSchemaResponse, error) {
+	if err := s.schemaService.Replace(ctx, req.GetSchema()); err != nil {
+		return nil, err
+	}
+	return &grpcapi.ReplaceSchemaResponse{}, nil
+}
+
+func (s *SchemaServer) GetSchema(ctx context.Context, req *grpcapi.GetSchemaRequest) (*grpcapi.GetSchemaResponse, error) {
+	schema, err := s.schemaService.Get(ctx, req.GetGroup(), req.GetKind())
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := marshalObject(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcapi.GetSchemaResponse{Schema: data}, nil
+}
+
+func (s *SchemaServer) ListSchemas(ctx context.Context, req *grpcapi.ListSchemasRequest) (*grpcapi.ListSchemasResponse, error) {
+	schemas, err := s.schemaService.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([][]byte, 0, len(schemas))
+	for _, schema := range schemas {
+		data, err := marshalObject(schema)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, data)
+	}
+	return &grpcapi.ListSchemasResponse{Schemas: items}, nil
+}
+
+func (s *SchemaServer) DeleteSchema(ctx context.Context, req *grpcapi.DeleteSchemaRequest) (*grpcapi.DeleteSchemaResponse, error) {
+	if err := s.schemaService.Delete(ctx, req.GetGroup(), req.GetKind()); err != nil {
+		return nil, err
+	}
+	return &grpcapi.DeleteSchemaResponse{}, nil
+}
@@ -0,0 +1,82 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/tsamsiyu/themelio/api/internal/config"
+	"github.com/tsamsiyu/themelio/sdk/pkg/grpcapi"
+)
+
+// Server is the gRPC API, it runs next to the REST server and serves the same services
+type Server struct {
+	config          *config.GRPCConfig
+	shutdownTimeout time.Duration
+	logger          *zap.Logger
+	server          *grpc.Server
+}
+
+func NewServer(
+	cfg *config.Config,
+	logger *zap.Logger,
+	resourceServer *ResourceServer,
+	schemaServer *SchemaServer,
+) *Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryErrorInterceptor(logger)),
+		grpc.ChainStreamInterceptor(streamErrorInterceptor(logger)),
+	)
+	grpcapi.RegisterResourceServiceServer(server, resourceServer)
+	grpcapi.RegisterSchemaServiceServer(server, schemaServer)
+
+	return &Server{
+		config:          &cfg.GRPC,
+		shutdownTimeout: cfg.Server.ShutdownTimeout,
+		logger:          logger,
+		server:          server,
+	}
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s.logger.Info("Starting gRPC server",
+		zap.String("addr", addr),
+		zap.Int("port", s.config.Port))
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+			s.logger.Error("gRPC server failed", zap.Error(err))
+		}
+	}()
+
+	<-ctx.Done()
+	s.Stop()
+	return nil
+}
+
+// Stop waits for running calls up to the shutdown timeout, watches don't end on their own so they are cut then
+func (s *Server) Stop() {
+	s.logger.Info("Stopping gRPC server")
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(s.shutdownTimeout):
+		s.server.Stop()
+	}
+}
@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 
 	"github.com/tsamsiyu/themelio/api/internal/api/errors"
+	internalerrors "github.com/tsamsiyu/themelio/api/internal/errors"
+	repositorytypes "github.com/tsamsiyu/themelio/api/internal/repository/types"
 	servicetypes "github.com/tsamsiyu/themelio/api/internal/service/types"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
 )
 
 type ResourceHandler struct {
@@ -64,6 +70,10 @@ func (h *ResourceHandler) GetResource(c *gin.Context) {
 		return
 	}
 
+	if resource.SystemMeta != nil {
+		c.Header("ETag", fmt.Sprintf(`"%d"`, resource.SystemMeta.ModRevision))
+	}
+
 	c.JSON(http.StatusOK, resource)
 }
 
@@ -74,15 +84,28 @@ func (h *ResourceHandler) ListResources(c *gin.Context) {
 		return
 	}
 
-	resources, err := h.resourceService.ListResources(c.Request.Context(), params)
+	options, err := getListOptionsFromContext(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	batch, err := h.resourceService.ListResources(c.Request.Context(), params, options)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
+	items := batch.Objects
+	if items == nil {
+		items = []*sdkmeta.Object{}
+	}
+
 	response := gin.H{
-		"items": resources,
-		"total": len(resources),
+		"items":    items,
+		"total":    len(items),
+		"revision": batch.Revision,
+		"continue": batch.Continue,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -95,7 +118,11 @@ func (h *ResourceHandler) DeleteResource(c *gin.Context) {
 		return
 	}
 
-	err = h.resourceService.DeleteResource(c.Request.Context(), params)
+	options := repositorytypes.DeleteOptions{
+		PropagationPolicy: sdkmeta.PropagationPolicy(c.Query("propagationPolicy")),
+	}
+
+	err = h.resourceService.DeleteResource(c.Request.Context(), params, options)
 	if err != nil {
 		c.Error(err)
 		return
@@ -126,6 +153,69 @@ func (h *ResourceHandler) PatchResource(c *gin.Context) {
 	c.JSON(http.StatusOK, patchedResource)
 }
 
+func (h *ResourceHandler) ReplaceResourceStatus(c *gin.Context) {
+	params, err := getParamsFromContext(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	jsonData, err := c.GetRawData()
+	if err != nil {
+		c.Error(errors.NewSerializationError("reading request body", err))
+		return
+	}
+
+	err = h.resourceService.ReplaceResourceStatus(c.Request.Context(), params, jsonData)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": true})
+}
+
+func (h *ResourceHandler) PatchResourceStatus(c *gin.Context) {
+	params, err := getParamsFromContext(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	patchData, err := c.GetRawData()
+	if err != nil {
+		c.Error(errors.NewSerializationError("reading request body", err))
+		return
+	}
+
+	patchedResource, err := h.resourceService.PatchResourceStatus(c.Request.Context(), params, patchData)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, patchedResource)
+}
+
+// RemoveFinalizer takes the finalizer from the rest of the path, as finalizer names usually hold a slash
+func (h *ResourceHandler) RemoveFinalizer(c *gin.Context) {
+	params, err := getParamsFromContext(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	finalizer := strings.TrimPrefix(c.Param("finalizer"), "/")
+
+	resource, err := h.resourceService.RemoveFinalizer(c.Request.Context(), params, finalizer)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resource)
+}
+
 func getParamsFromContext(c *gin.Context) (servicetypes.Params, error) {
 	group := c.Param("group")
 	version := c.Param("version")
@@ -133,11 +223,76 @@ func getParamsFromContext(c *gin.Context) (servicetypes.Params, error) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 
+	expectedRevision, err := parseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		return servicetypes.Params{}, err
+	}
+
+	minRevision, err := parseMinRevision(c)
+	if err != nil {
+		return servicetypes.Params{}, err
+	}
+
 	return servicetypes.Params{
-		Group:     group,
-		Version:   version,
-		Kind:      kind,
-		Namespace: namespace,
-		Name:      name,
+		Group:            group,
+		Version:          version,
+		Kind:             kind,
+		Namespace:        namespace,
+		Name:             name,
+		ExpectedRevision: expectedRevision,
+		MinRevision:      minRevision,
+		LabelSelector:    c.Query("labelSelector"),
+		FieldSelector:    c.Query("fieldSelector"),
 	}, nil
 }
+
+func getListOptionsFromContext(c *gin.Context) (repositorytypes.ListOptions, error) {
+	options := repositorytypes.ListOptions{
+		Continue: c.Query("continue"),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 0 {
+			return repositorytypes.ListOptions{}, internalerrors.NewInvalidInputError(fmt.Sprintf("invalid limit %q", limit))
+		}
+		options.Limit = value
+	}
+
+	minRevision, err := parseMinRevision(c)
+	if err != nil {
+		return repositorytypes.ListOptions{}, err
+	}
+	options.MinRevision = minRevision
+
+	return options, nil
+}
+
+// parseMinRevision reads the revision a read may be served from the cache at or after, zero asks for a quorum read
+func parseMinRevision(c *gin.Context) (int64, error) {
+	value := c.Query("minRevision")
+	if value == "" {
+		return 0, nil
+	}
+
+	minRevision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || minRevision < 0 {
+		return 0, internalerrors.NewInvalidInputError(fmt.Sprintf("invalid minRevision %q", value))
+	}
+	return minRevision, nil
+}
+
+// parseIfMatch reads the mod revision from an If-Match header, the value is the ETag returned by GET
+func parseIfMatch(header string) (int64, error) {
+	if header == "" {
+		return 0, nil
+	}
+
+	value := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || revision <= 0 {
+		return 0, internalerrors.NewInvalidInputError(fmt.Sprintf("invalid If-Match header %q", header))
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/api/middleware"
+	"github.com/tsamsiyu/themelio/api/internal/repository"
+	servicetypes "github.com/tsamsiyu/themelio/api/internal/service/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+func newResourceTestRouter(handler *ResourceHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorMapper(zap.NewNop()))
+	router.PUT("/resources/:group/:version/:kind", handler.ReplaceResource)
+	router.GET("/resources/:group/:version/:kind/:name", handler.GetResource)
+	router.DELETE("/resources/:group/:version/:kind/:name/finalizers/*finalizer", handler.RemoveFinalizer)
+	return router
+}
+
+func TestResourceHandler_GetResource_ETag(t *testing.T) {
+	mockService := mocks.NewMockResourceService(t)
+	router := newResourceTestRouter(NewResourceHandler(zap.NewNop(), mockService, validator.New()))
+
+	params := servicetypes.Params{Group: "example.com", Version: "v1", Kind: "Network", Name: "main"}
+	mockService.EXPECT().GetResource(mock.Anything, params).Return(&sdkmeta.Object{
+		SystemMeta: &sdkmeta.SystemMeta{ModRevision: 42},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/resources/example.com/v1/Network/main", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"42"`, w.Header().Get("ETag"))
+}
+
+func TestResourceHandler_ReplaceResource_IfMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		ifMatch    string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "matching revision", ifMatch: `"42"`, wantStatus: http.StatusOK},
+		{name: "weak etag", ifMatch: `W/"42"`, wantStatus: http.StatusOK},
+		{name: "outdated revision", ifMatch: `"42"`, serviceErr: repository.NewConflictError("/example.com/v1/Network/main"), wantStatus: http.StatusConflict},
+		{name: "invalid header", ifMatch: `"abc"`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockResourceService(t)
+			router := newResourceTestRouter(NewResourceHandler(zap.NewNop(), mockService, validator.New()))
+
+			body := []byte(`{"key":{"group":"example.com","version":"v1","kind":"Network","name":"main"}}`)
+			if tt.wantStatus != http.StatusBadRequest {
+				params := servicetypes.Params{Group: "example.com", Version: "v1", Kind: "Network", ExpectedRevision: 42}
+				mockService.EXPECT().ReplaceResource(mock.Anything, params, body).Return(tt.serviceErr)
+			}
+
+			req, _ := http.NewRequest("PUT", "/resources/example.com/v1/Network", bytes.NewReader(body))
+			req.Header.Set("If-Match", tt.ifMatch)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestResourceHandler_GetResource_MinRevision(t *testing.T) {
+	tests := []struct {
+		name        string
+		minRevision string
+		wantStatus  int
+	}{
+		{name: "cached read", minRevision: "42", wantStatus: http.StatusOK},
+		{name: "negative revision", minRevision: "-1", wantStatus: http.StatusBadRequest},
+		{name: "invalid revision", minRevision: "abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockResourceService(t)
+			router := newResourceTestRouter(NewResourceHandler(zap.NewNop(), mockService, validator.New()))
+
+			if tt.wantStatus == http.StatusOK {
+				params := servicetypes.Params{Group: "example.com", Version: "v1", Kind: "Network", Name: "main", MinRevision: 42}
+				mockService.EXPECT().GetResource(mock.Anything, params).Return(&sdkmeta.Object{
+					SystemMeta: &sdkmeta.SystemMeta{ModRevision: 42},
+				}, nil)
+			}
+
+			req, _ := http.NewRequest("GET", "/resources/example.com/v1/Network/main?minRevision="+tt.minRevision, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestResourceHandler_RemoveFinalizer(t *testing.T) {
+	mockService := mocks.NewMockResourceService(t)
+	router := newResourceTestRouter(NewResourceHandler(zap.NewNop(), mockService, validator.New()))
+
+	// Given: A resource held by a finalizer with a slash in its name
+	params := servicetypes.Params{Group: "example.com", Version: "v1", Kind: "Network", Name: "main"}
+	mockService.EXPECT().RemoveFinalizer(mock.Anything, params, "example.com/cleanup").Return(&sdkmeta.Object{
+		ObjectMeta: &sdkmeta.ObjectMeta{},
+	}, nil)
+
+	// When: The finalizer is removed
+	req, _ := http.NewRequest("DELETE", "/resources/example.com/v1/Network/main/finalizers/example.com/cleanup", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then: The whole rest of the path is taken as the finalizer
+	assert.Equal(t, http.StatusOK, w.Code)
+}
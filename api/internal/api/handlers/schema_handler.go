@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/api/errors"
+	sharedservice "github.com/tsamsiyu/themelio/api/internal/service/shared"
+)
+
+type SchemaHandler struct {
+	logger        *zap.Logger
+	schemaService sharedservice.SchemaService
+}
+
+func NewSchemaHandler(
+	logger *zap.Logger,
+	schemaService sharedservice.SchemaService,
+) *SchemaHandler {
+	return &SchemaHandler{
+		logger:        logger,
+		schemaService: schemaService,
+	}
+}
+
+func (h *SchemaHandler) ReplaceSchema(c *gin.Context) {
+	jsonData, err := c.GetRawData()
+	if err != nil {
+		c.Error(errors.NewSerializationError("reading request body", err))
+		return
+	}
+
+	err = h.schemaService.Replace(c.Request.Context(), jsonData)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": true})
+}
+
+func (h *SchemaHandler) GetSchema(c *gin.Context) {
+	schema, err := h.schemaService.Get(c.Request.Context(), c.Param("group"), c.Param("kind"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+func (h *SchemaHandler) ListSchemas(c *gin.Context) {
+	schemas, err := h.schemaService.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response := gin.H{
+		"items": schemas,
+		"total": len(schemas),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *SchemaHandler) DeleteSchema(c *gin.Context) {
+	err := h.schemaService.Delete(c.Request.Context(), c.Param("group"), c.Param("kind"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schema deleted successfully"})
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/api/middleware"
+	internalerrors "github.com/tsamsiyu/themelio/api/internal/errors"
+	"github.com/tsamsiyu/themelio/api/internal/repository"
+	"github.com/tsamsiyu/themelio/api/mocks"
+	sdkschema "github.com/tsamsiyu/themelio/sdk/pkg/types/schema"
+)
+
+func newSchemaTestRouter(handler *SchemaHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorMapper(zap.NewNop()))
+	router.PUT("/schemas", handler.ReplaceSchema)
+	router.GET("/schemas", handler.ListSchemas)
+	router.GET("/schemas/:group/:kind", handler.GetSchema)
+	router.DELETE("/schemas/:group/:kind", handler.DeleteSchema)
+	return router
+}
+
+func TestSchemaHandler_ReplaceSchema(t *testing.T) {
+	mockService := mocks.NewMockSchemaService(t)
+	router := newSchemaTestRouter(NewSchemaHandler(zap.NewNop(), mockService))
+
+	body := []byte(`{"group":"example.com","kind":"Network","scope":"Cluster","versions":[{"name":"v1","schema":{}}]}`)
+	mockService.EXPECT().Replace(mock.Anything, body).Return(nil)
+
+	req, _ := http.NewRequest("PUT", "/schemas", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSchemaHandler_ReplaceSchema_Invalid(t *testing.T) {
+	mockService := mocks.NewMockSchemaService(t)
+	router := newSchemaTestRouter(NewSchemaHandler(zap.NewNop(), mockService))
+
+	mockService.EXPECT().Replace(mock.Anything, mock.Anything).Return(internalerrors.NewInvalidInputError("invalid schema"))
+
+	req, _ := http.NewRequest("PUT", "/schemas", bytes.NewReader([]byte(`{}`)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid schema")
+}
+
+func TestSchemaHandler_GetSchema(t *testing.T) {
+	mockService := mocks.NewMockSchemaService(t)
+	router := newSchemaTestRouter(NewSchemaHandler(zap.NewNop(), mockService))
+
+	schema := &sdkschema.ObjectSchema{Group: "example.com", Kind: "Network", Scope: sdkschema.ResourceScopeCluster}
+	mockService.EXPECT().Get(mock.Anything, "example.com", "Network").Return(schema, nil)
+
+	req, _ := http.NewRequest("GET", "/schemas/example.com/Network", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"kind":"Network"`)
+}
+
+func TestSchemaHandler_GetSchema_NotFound(t *testing.T) {
+	mockService := mocks.NewMockSchemaService(t)
+	router := newSchemaTestRouter(NewSchemaHandler(zap.NewNop(), mockService))
+
+	mockService.EXPECT().Get(mock.Anything, "example.com", "Missing").Return(nil, repository.NewNotFoundError("/schema/example.com/Missing"))
+
+	req, _ := http.NewRequest("GET", "/schemas/example.com/Missing", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSchemaHandler_ListSchemas(t *testing.T) {
+	mockService := mocks.NewMockSchemaService(t)
+	router := newSchemaTestRouter(NewSchemaHandler(zap.NewNop(), mockService))
+
+	schemas := []*sdkschema.ObjectSchema{
+		{Group: "example.com", Kind: "Network"},
+		{Group: "example.com", Kind: "Subnet"},
+	}
+	mockService.EXPECT().List(mock.Anything).Return(schemas, nil)
+
+	req, _ := http.NewRequest("GET", "/schemas", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":2`)
+}
+
+func TestSchemaHandler_DeleteSchema(t *testing.T) {
+	mockService := mocks.NewMockSchemaService(t)
+	router := newSchemaTestRouter(NewSchemaHandler(zap.NewNop(), mockService))
+
+	mockService.EXPECT().Delete(mock.Anything, "example.com", "Network").Return(nil)
+
+	req, _ := http.NewRequest("DELETE", "/schemas/example.com/Network", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strconv"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"go.uber.org/zap"
 
 	internalerrors "github.com/tsamsiyu/themelio/api/internal/errors"
+	"github.com/tsamsiyu/themelio/api/internal/repository"
 	"github.com/tsamsiyu/themelio/api/internal/repository/types"
 	servicetypes "github.com/tsamsiyu/themelio/api/internal/service/types"
 )
@@ -42,26 +44,18 @@ func (h *WatchHandler) WatchResource(c *gin.Context) {
 		return
 	}
 
-	revisionStr := c.Query("revision")
-	var revision int64
-	if revisionStr != "" {
-		revision, err = strconv.ParseInt(revisionStr, 10, 64)
-		if err != nil {
-			c.Error(internalerrors.NewInvalidInputError("invalid revision parameter"))
-			return
-		}
+	options, err := getWatchOptionsFromContext(c)
+	if err != nil {
+		c.Error(err)
+		return
 	}
-
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
-	c.Header("Access-Control-Allow-Headers", "Cache-Control")
+	revision := options.Revision
 
 	ctx, cancel := context.WithCancel(c.Request.Context())
 	defer cancel()
 
-	watchChan, err := h.resourceService.WatchResource(ctx, params, revision)
+	// errors before the stream starts are plain responses, so a compacted revision is a 410 the client can act on
+	watchChan, err := h.resourceService.WatchResource(ctx, params, options)
 	if err != nil {
 		h.logger.Error("Failed to start resource watch",
 			zap.String("group", params.Group),
@@ -70,10 +64,16 @@ func (h *WatchHandler) WatchResource(c *gin.Context) {
 			zap.String("namespace", params.Namespace),
 			zap.Error(err))
 
-		h.sendSSEError(c, "Failed to start watch", err)
+		c.Error(err)
 		return
 	}
 
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Access-Control-Allow-Headers", "Cache-Control")
+
 	h.logger.Info("Started SSE watch for resource",
 		zap.String("group", params.Group),
 		zap.String("version", params.Version),
@@ -82,24 +82,17 @@ func (h *WatchHandler) WatchResource(c *gin.Context) {
 		zap.Int64("revision", revision))
 
 	connectedEvent := map[string]interface{}{
-		"type": "connected",
-		"payload": map[string]interface{}{
-			"message":   "Watch connection established",
-			"timestamp": time.Now().UTC(),
-			"revision":  revision,
-			"params": map[string]interface{}{
-				"group":     params.Group,
-				"version":   params.Version,
-				"kind":      params.Kind,
-				"namespace": params.Namespace,
-			},
-		},
+		"type":    "connected",
+		"payload": connectedPayload(params, revision),
 	}
-	if err := h.sendSSEEventWithRetry(c, "connected", connectedEvent); err != nil {
+	if err := h.sendSSEEventWithRetry(c, "connected", revision, connectedEvent); err != nil {
 		h.logger.Error("Failed to send connection event", zap.Error(err))
 		return
 	}
 
+	// initial events go without ids, a stream broken before their end is started over instead of resumed
+	sendingInitialEvents := options.SendInitialEvents
+
 	ticker := time.NewTicker(30 * time.Second) // Heartbeat every 30 seconds
 	defer ticker.Stop()
 
@@ -122,7 +115,32 @@ func (h *WatchHandler) WatchResource(c *gin.Context) {
 				return
 			}
 
-			if err := h.sendWatchEventWithRetry(c, event); err != nil {
+			if event.Type == types.WatchEventTypeError && repository.IsExpiredError(event.Error) {
+				h.logger.Warn("Watch revision compacted, ending SSE stream",
+					zap.String("group", params.Group),
+					zap.String("version", params.Version),
+					zap.String("kind", params.Kind))
+				h.sendSSEExpired(c, event.Error)
+				return
+			}
+
+			if event.Type == types.WatchEventTypeBookmark {
+				if event.InitialEventsEnd {
+					sendingInitialEvents = false
+				}
+				if err := h.sendBookmarkWithRetry(c, event); err != nil {
+					h.logger.Error("Failed to send bookmark", zap.Error(err))
+					return
+				}
+				continue
+			}
+
+			eventID := event.Revision
+			if sendingInitialEvents {
+				eventID = 0
+			}
+
+			if err := h.sendWatchEventWithRetry(c, event, eventID); err != nil {
 				h.logger.Error("Failed to send SSE event",
 					zap.String("eventType", string(event.Type)),
 					zap.Error(err))
@@ -136,7 +154,7 @@ func (h *WatchHandler) WatchResource(c *gin.Context) {
 					"timestamp": time.Now().UTC(),
 				},
 			}
-			if err := h.sendSSEEventWithRetry(c, "heartbeat", heartbeatEvent); err != nil {
+			if err := h.sendSSEEventWithRetry(c, "heartbeat", 0, heartbeatEvent); err != nil {
 				h.logger.Error("Failed to send heartbeat", zap.Error(err))
 				return
 			}
@@ -144,38 +162,116 @@ func (h *WatchHandler) WatchResource(c *gin.Context) {
 	}
 }
 
-func (h *WatchHandler) sendWatchEventWithRetry(c *gin.Context, event types.WatchEvent) error {
-	resourceEventData := map[string]interface{}{
+// getWatchOptionsFromContext reads where the watch starts
+// A reconnecting EventSource repeats the original URL, its Last-Event-ID is the more recent resume point
+// and the initial events have already been delivered before it
+func getWatchOptionsFromContext(c *gin.Context) (types.WatchOptions, error) {
+	var options types.WatchOptions
+
+	if sendInitialEvents := c.Query("sendInitialEvents"); sendInitialEvents != "" {
+		value, err := strconv.ParseBool(sendInitialEvents)
+		if err != nil {
+			return types.WatchOptions{}, internalerrors.NewInvalidInputError("invalid sendInitialEvents parameter")
+		}
+		options.SendInitialEvents = value
+	}
+
+	revisionStr := c.Query("revision")
+	if revisionStr != "" && options.SendInitialEvents {
+		return types.WatchOptions{}, internalerrors.NewInvalidInputError("sendInitialEvents can't be combined with revision")
+	}
+
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		revisionStr = lastEventID
+		options.SendInitialEvents = false
+	}
+
+	if revisionStr != "" {
+		revision, err := strconv.ParseInt(revisionStr, 10, 64)
+		if err != nil {
+			return types.WatchOptions{}, internalerrors.NewInvalidInputError("invalid revision parameter")
+		}
+		options.Revision = revision
+	}
+
+	return options, nil
+}
+
+func (h *WatchHandler) sendWatchEventWithRetry(c *gin.Context, event types.WatchEvent, eventID int64) error {
+	watchEvent := map[string]interface{}{
+		"type":    "event",
+		"payload": resourceEventPayload(event),
+	}
+
+	return h.sendSSEEventWithRetry(c, "event", eventID, watchEvent)
+}
+
+// sendBookmarkWithRetry tells the client the revision it can resume from, even when nothing has changed
+func (h *WatchHandler) sendBookmarkWithRetry(c *gin.Context, event types.WatchEvent) error {
+	bookmarkEvent := map[string]interface{}{
+		"type":    "bookmark",
+		"payload": bookmarkPayload(event),
+	}
+
+	return h.sendSSEEventWithRetry(c, "bookmark", event.Revision, bookmarkEvent)
+}
+
+// connectedPayload, resourceEventPayload and bookmarkPayload are the payloads of the watch envelope,
+// shared by the SSE and WebSocket transports
+func connectedPayload(params servicetypes.Params, revision int64) map[string]interface{} {
+	return map[string]interface{}{
+		"message":   "Watch connection established",
+		"timestamp": time.Now().UTC(),
+		"revision":  revision,
+		"params": map[string]interface{}{
+			"group":         params.Group,
+			"version":       params.Version,
+			"kind":          params.Kind,
+			"namespace":     params.Namespace,
+			"labelSelector": params.LabelSelector,
+			"fieldSelector": params.FieldSelector,
+		},
+	}
+}
+
+func resourceEventPayload(event types.WatchEvent) map[string]interface{} {
+	payload := map[string]interface{}{
 		"type":      event.Type,
 		"timestamp": event.Timestamp,
 		"revision":  event.Revision,
+		"objectKey": event.ObjectKey,
 	}
 
 	if event.Object != nil {
-		resourceEventData["object"] = event.Object
-	} else {
-		resourceEventData["objectKey"] = event.ObjectKey
+		payload["object"] = event.Object
 	}
 
 	if event.Error != nil {
-		resourceEventData["error"] = event.Error.Error()
+		payload["error"] = event.Error.Error()
 	}
 
-	watchEvent := map[string]interface{}{
-		"type":    "event",
-		"payload": resourceEventData,
-	}
+	return payload
+}
 
-	return h.sendSSEEventWithRetry(c, "event", watchEvent)
+func bookmarkPayload(event types.WatchEvent) map[string]interface{} {
+	payload := map[string]interface{}{
+		"timestamp": event.Timestamp,
+		"revision":  event.Revision,
+	}
+	if event.InitialEventsEnd {
+		payload["initialEventsEnd"] = true
+	}
+	return payload
 }
 
-func (h *WatchHandler) sendSSEEventWithRetry(c *gin.Context, eventType string, data interface{}) error {
+// sendSSEEventWithRetry sends the event with the revision as its id, a zero revision is sent without one
+func (h *WatchHandler) sendSSEEventWithRetry(c *gin.Context, eventType string, revision int64, data interface{}) error {
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		if attempt > 0 {
 			time.Sleep(retryDelay)
 		}
 
-		err := h.sendSSEEvent(c, eventType, data)
+		err := h.sendSSEEvent(c, eventType, revision, data)
 		if err == nil {
 			return nil
 		}
@@ -190,13 +286,17 @@ func (h *WatchHandler) sendSSEEventWithRetry(c *gin.Context, eventType string, d
 	return fmt.Errorf("failed to send SSE event: retry attempts exceeded")
 }
 
-func (h *WatchHandler) sendSSEEvent(c *gin.Context, eventType string, data interface{}) error {
+func (h *WatchHandler) sendSSEEvent(c *gin.Context, eventType string, revision int64, data interface{}) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("failed to marshal SSE event data: %w", err)
 	}
 
-	_, err = fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", eventType, string(jsonData))
+	if revision > 0 {
+		_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", revision, eventType, string(jsonData))
+	} else {
+		_, err = fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", eventType, string(jsonData))
+	}
 	if err != nil {
 		return fmt.Errorf("failed to write SSE event: %w", err)
 	}
@@ -205,12 +305,14 @@ func (h *WatchHandler) sendSSEEvent(c *gin.Context, eventType string, data inter
 	return nil
 }
 
-func (h *WatchHandler) sendSSEError(c *gin.Context, message string, err error) {
+// sendSSEExpired tells the client to list again, resuming the watch from its revision is no longer possible
+func (h *WatchHandler) sendSSEExpired(c *gin.Context, err error) {
 	errorData := map[string]interface{}{
-		"error":     message,
+		"error":     "Resync required",
 		"details":   err.Error(),
+		"code":      http.StatusGone,
 		"timestamp": time.Now().UTC(),
 	}
 
-	h.sendSSEEvent(c, "error", errorData)
+	h.sendSSEEvent(c, "error", 0, errorData)
 }
@@ -14,6 +14,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/tsamsiyu/themelio/api/internal/api/middleware"
+	"github.com/tsamsiyu/themelio/api/internal/repository"
 	"github.com/tsamsiyu/themelio/api/internal/repository/types"
 	"github.com/tsamsiyu/themelio/api/mocks"
 	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
@@ -27,7 +28,7 @@ func TestWatchHandler_WatchResource(t *testing.T) {
 	handler := NewWatchHandler(logger, mockService)
 
 	eventChan := make(chan types.WatchEvent, 1)
-	mockService.EXPECT().WatchResource(mock.Anything, mock.Anything, int64(0)).Return((<-chan types.WatchEvent)(eventChan), nil)
+	mockService.EXPECT().WatchResource(mock.Anything, mock.Anything, types.WatchOptions{}).Return((<-chan types.WatchEvent)(eventChan), nil)
 
 	router := gin.New()
 	router.GET("/test/:group/:version/:kind/watch", handler.WatchResource)
@@ -67,7 +68,7 @@ func TestWatchHandler_WatchResource_WithRevision(t *testing.T) {
 	handler := NewWatchHandler(logger, mockService)
 
 	eventChan := make(chan types.WatchEvent, 1)
-	mockService.EXPECT().WatchResource(mock.Anything, mock.Anything, int64(123)).Return((<-chan types.WatchEvent)(eventChan), nil)
+	mockService.EXPECT().WatchResource(mock.Anything, mock.Anything, types.WatchOptions{Revision: 123}).Return((<-chan types.WatchEvent)(eventChan), nil)
 
 	router := gin.New()
 	router.GET("/test/:group/:version/:kind/watch", handler.WatchResource)
@@ -85,6 +86,109 @@ func TestWatchHandler_WatchResource_WithRevision(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestWatchHandler_WatchResource_LastEventID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+
+	mockService := mocks.NewMockResourceService(t)
+	handler := NewWatchHandler(logger, mockService)
+
+	eventChan := make(chan types.WatchEvent, 1)
+	mockService.EXPECT().WatchResource(mock.Anything, mock.Anything, types.WatchOptions{Revision: 150}).Return((<-chan types.WatchEvent)(eventChan), nil)
+
+	router := gin.New()
+	router.GET("/test/:group/:version/:kind/watch", handler.WatchResource)
+
+	// The reconnecting EventSource repeats the original revision, the header wins
+	req, _ := http.NewRequest("GET", "/test/testgroup/v1/testkind/watch?revision=123", nil)
+	req.Header.Set("Last-Event-ID", "150")
+	w := httptest.NewRecorder()
+
+	close(eventChan)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWatchHandler_WatchResource_EventIDsAndBookmarks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+
+	mockService := mocks.NewMockResourceService(t)
+	handler := NewWatchHandler(logger, mockService)
+
+	eventChan := make(chan types.WatchEvent, 2)
+	eventChan <- types.WatchEvent{Type: types.WatchEventTypeAdded, Object: &sdkmeta.Object{}, Revision: 7}
+	eventChan <- types.WatchEvent{Type: types.WatchEventTypeBookmark, Revision: 9}
+	close(eventChan)
+	mockService.EXPECT().WatchResource(mock.Anything, mock.Anything, types.WatchOptions{}).Return((<-chan types.WatchEvent)(eventChan), nil)
+
+	router := gin.New()
+	router.GET("/test/:group/:version/:kind/watch", handler.WatchResource)
+
+	req, _ := http.NewRequest("GET", "/test/testgroup/v1/testkind/watch", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "id: 7\nevent: event\n")
+	assert.Contains(t, body, "id: 9\nevent: bookmark\n")
+	assert.Contains(t, body, "\"revision\":9")
+	assert.NotContains(t, body, "id: 0")
+}
+
+func TestWatchHandler_WatchResource_SendInitialEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+
+	mockService := mocks.NewMockResourceService(t)
+	handler := NewWatchHandler(logger, mockService)
+
+	eventChan := make(chan types.WatchEvent, 3)
+	eventChan <- types.WatchEvent{Type: types.WatchEventTypeAdded, Object: &sdkmeta.Object{}, Revision: 7}
+	eventChan <- types.WatchEvent{Type: types.WatchEventTypeBookmark, Revision: 7, InitialEventsEnd: true}
+	eventChan <- types.WatchEvent{Type: types.WatchEventTypeModified, Object: &sdkmeta.Object{}, Revision: 8}
+	close(eventChan)
+	mockService.EXPECT().WatchResource(mock.Anything, mock.Anything, types.WatchOptions{SendInitialEvents: true}).Return((<-chan types.WatchEvent)(eventChan), nil)
+
+	router := gin.New()
+	router.GET("/test/:group/:version/:kind/watch", handler.WatchResource)
+
+	req, _ := http.NewRequest("GET", "/test/testgroup/v1/testkind/watch?sendInitialEvents=true", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	// The initial objects carry no id, a reconnect before their end starts over
+	body := w.Body.String()
+	assert.Contains(t, body, "event: connected\ndata:")
+	assert.Contains(t, body, "\n\nevent: event\n")
+	assert.NotContains(t, body, "id: 7\nevent: event\n")
+	assert.Contains(t, body, "id: 7\nevent: bookmark\n")
+	assert.Contains(t, body, "\"initialEventsEnd\":true")
+	assert.Contains(t, body, "id: 8\nevent: event\n")
+}
+
+func TestWatchHandler_WatchResource_SendInitialEventsWithRevision(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+
+	mockService := mocks.NewMockResourceService(t)
+	handler := NewWatchHandler(logger, mockService)
+
+	router := gin.New()
+	router.Use(middleware.ErrorMapper(logger))
+	router.GET("/test/:group/:version/:kind/watch", handler.WatchResource)
+
+	req, _ := http.NewRequest("GET", "/test/testgroup/v1/testkind/watch?sendInitialEvents=true&revision=5", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestWatchHandler_WatchResource_InvalidRevision(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	logger := zap.NewNop()
@@ -104,6 +208,52 @@ func TestWatchHandler_WatchResource_InvalidRevision(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func TestWatchHandler_WatchResource_CompactedRevision(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+
+	mockService := mocks.NewMockResourceService(t)
+	handler := NewWatchHandler(logger, mockService)
+
+	mockService.EXPECT().WatchResource(mock.Anything, mock.Anything, types.WatchOptions{Revision: 5}).Return(nil, repository.NewExpiredError(5))
+
+	router := gin.New()
+	router.Use(middleware.ErrorMapper(logger))
+	router.GET("/test/:group/:version/:kind/watch", handler.WatchResource)
+
+	req, _ := http.NewRequest("GET", "/test/testgroup/v1/testkind/watch?revision=5", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.NotEqual(t, "text/event-stream", w.Header().Get("Content-Type"))
+}
+
+func TestWatchHandler_WatchResource_CompactedDuringStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+
+	mockService := mocks.NewMockResourceService(t)
+	handler := NewWatchHandler(logger, mockService)
+
+	eventChan := make(chan types.WatchEvent, 1)
+	eventChan <- types.WatchEvent{Type: types.WatchEventTypeError, Error: repository.NewExpiredError(5)}
+	mockService.EXPECT().WatchResource(mock.Anything, mock.Anything, types.WatchOptions{Revision: 5}).Return((<-chan types.WatchEvent)(eventChan), nil)
+
+	router := gin.New()
+	router.GET("/test/:group/:version/:kind/watch", handler.WatchResource)
+
+	req, _ := http.NewRequest("GET", "/test/testgroup/v1/testkind/watch?revision=5", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "event: error")
+	assert.Contains(t, body, "\"code\":410")
+}
+
 func TestWatchHandler_sendSSEEvent(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	logger := zap.NewNop()
@@ -122,7 +272,7 @@ func TestWatchHandler_sendSSEEvent(t *testing.T) {
 			"timestamp": time.Now().UTC(),
 		}
 
-		err := handler.sendSSEEvent(c, "test-event", data)
+		err := handler.sendSSEEvent(c, "test-event", 0, data)
 		assert.NoError(t, err)
 	})
 
@@ -168,7 +318,7 @@ func TestWatchHandler_sendSSEEventWithRetry(t *testing.T) {
 			"timestamp": time.Now().UTC(),
 		}
 
-		err := handler.sendSSEEventWithRetry(c, "test-retry-event", data)
+		err := handler.sendSSEEventWithRetry(c, "test-retry-event", 0, data)
 		assert.NoError(t, err)
 	})
 
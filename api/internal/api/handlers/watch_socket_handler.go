@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/api/middleware"
+	"github.com/tsamsiyu/themelio/api/internal/config"
+	internalerrors "github.com/tsamsiyu/themelio/api/internal/errors"
+	"github.com/tsamsiyu/themelio/api/internal/repository"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	servicetypes "github.com/tsamsiyu/themelio/api/internal/service/types"
+)
+
+const (
+	socketWriteTimeout = 10 * time.Second
+	socketPongTimeout  = 60 * time.Second
+	socketPingInterval = 30 * time.Second
+	socketSendBuffer   = 100
+)
+
+const (
+	socketRequestSubscribe   = "subscribe"
+	socketRequestUnsubscribe = "unsubscribe"
+	socketRequestAck         = "ack"
+)
+
+// socketRequest is a message of the client, Params, Revision and SendInitialEvents apply to subscribe
+// and Revision to ack
+type socketRequest struct {
+	Type              string       `json:"type"`
+	Subscription      string       `json:"subscription"`
+	Params            socketParams `json:"params"`
+	Revision          int64        `json:"revision"`
+	SendInitialEvents bool         `json:"sendInitialEvents"`
+}
+
+type socketParams struct {
+	Group         string `json:"group"`
+	Version       string `json:"version"`
+	Kind          string `json:"kind"`
+	Namespace     string `json:"namespace"`
+	LabelSelector string `json:"labelSelector"`
+	FieldSelector string `json:"fieldSelector"`
+}
+
+// socketMessage is the watch envelope of the SSE stream tagged with the subscription it belongs to
+type socketMessage struct {
+	Subscription string      `json:"subscription,omitempty"`
+	Type         string      `json:"type"`
+	Payload      interface{} `json:"payload"`
+}
+
+// WatchSocketHandler serves watches over a WebSocket, one socket carries any number of subscriptions
+// the client adds and removes as it goes
+type WatchSocketHandler struct {
+	logger          *zap.Logger
+	resourceService servicetypes.ResourceService
+	adminToken      string
+	upgrader        websocket.Upgrader
+}
+
+func NewWatchSocketHandler(
+	cfg *config.Config,
+	logger *zap.Logger,
+	resourceService servicetypes.ResourceService,
+) *WatchSocketHandler {
+	return &WatchSocketHandler{
+		logger:          logger,
+		resourceService: resourceService,
+		adminToken:      cfg.Server.AdminToken,
+		upgrader: websocket.Upgrader{
+			// the API allows any origin, see CORSMiddleware
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+func (h *WatchSocketHandler) Watch(c *gin.Context) {
+	admin := middleware.HasAdminToken(c, h.adminToken)
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader has already replied with the error
+		h.logger.Warn("Failed to upgrade watch socket", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	session := &socketSession{
+		handler:       h,
+		conn:          conn,
+		admin:         admin,
+		outgoing:      make(chan socketMessage, socketSendBuffer),
+		subscriptions: make(map[string]*socketSubscription),
+	}
+
+	h.logger.Info("Started watch socket", zap.String("remote", c.Request.RemoteAddr))
+
+	go session.writeLoop(ctx, cancel)
+	session.readLoop(ctx)
+
+	h.logger.Info("Watch socket closed", zap.String("remote", c.Request.RemoteAddr))
+}
+
+// socketSession is one socket, only writeLoop writes to the connection
+type socketSession struct {
+	handler       *WatchSocketHandler
+	conn          *websocket.Conn
+	admin         bool
+	outgoing      chan socketMessage
+	subscriptions map[string]*socketSubscription
+	mu            sync.Mutex
+}
+
+// socketSubscription is a watch of the session
+// A subscription has at most one bookmark the client hasn't acked, the ones coming before the ack are dropped
+type socketSubscription struct {
+	cancel           context.CancelFunc
+	bookmarkRevision int64
+	bookmarkPending  bool
+	mu               sync.Mutex
+}
+
+func (s *socketSession) readLoop(ctx context.Context) {
+	s.conn.SetReadDeadline(time.Now().Add(socketPongTimeout))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(socketPongTimeout))
+	})
+
+	for {
+		var request socketRequest
+		if err := s.conn.ReadJSON(&request); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				s.handler.logger.Debug("Watch socket read ended", zap.Error(err))
+			}
+			return
+		}
+
+		switch request.Type {
+		case socketRequestSubscribe:
+			s.subscribe(ctx, request)
+		case socketRequestUnsubscribe:
+			s.unsubscribe(request.Subscription)
+		case socketRequestAck:
+			s.ack(request.Subscription, request.Revision)
+		default:
+			s.sendError(ctx, request.Subscription,
+				internalerrors.NewInvalidInputError(fmt.Sprintf("unknown request type %q", request.Type)))
+		}
+	}
+}
+
+// writeLoop writes the messages of all subscriptions and pings the client, a failed write closes the session
+func (s *socketSession) writeLoop(ctx context.Context, cancel context.CancelFunc) {
+	defer cancel()
+
+	ticker := time.NewTicker(socketPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(socketWriteTimeout))
+			return
+
+		case message := <-s.outgoing:
+			s.conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+			if err := s.conn.WriteJSON(message); err != nil {
+				s.handler.logger.Error("Failed to write watch socket message",
+					zap.String("subscription", message.Subscription),
+					zap.String("type", message.Type),
+					zap.Error(err))
+				s.conn.Close()
+				return
+			}
+
+		case <-ticker.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteTimeout)); err != nil {
+				s.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+func (s *socketSession) subscribe(ctx context.Context, request socketRequest) {
+	id := request.Subscription
+	if id == "" {
+		s.sendError(ctx, "", internalerrors.NewInvalidInputError("subscription id is required"))
+		return
+	}
+
+	params := servicetypes.Params{
+		Group:         request.Params.Group,
+		Version:       request.Params.Version,
+		Kind:          request.Params.Kind,
+		Namespace:     request.Params.Namespace,
+		LabelSelector: request.Params.LabelSelector,
+		FieldSelector: request.Params.FieldSelector,
+	}
+
+	// every object is watched only with the admin token, the same as the /watch route
+	if params.Kind == "" && params.Namespace == "" && !s.admin {
+		s.send(ctx, socketMessage{Subscription: id, Type: "error", Payload: socketErrorPayload(http.StatusForbidden, "Admin token required")})
+		return
+	}
+
+	if request.SendInitialEvents && request.Revision > 0 {
+		s.sendError(ctx, id, internalerrors.NewInvalidInputError("sendInitialEvents can't be combined with revision"))
+		return
+	}
+
+	s.mu.Lock()
+	_, exists := s.subscriptions[id]
+	s.mu.Unlock()
+	if exists {
+		s.sendError(ctx, id, internalerrors.NewInvalidInputError(fmt.Sprintf("subscription %q already exists", id)))
+		return
+	}
+
+	options := types.WatchOptions{Revision: request.Revision, SendInitialEvents: request.SendInitialEvents}
+	watchCtx, cancel := context.WithCancel(ctx)
+
+	events, err := s.handler.resourceService.WatchResource(watchCtx, params, options)
+	if err != nil {
+		cancel()
+		s.handler.logger.Error("Failed to start socket watch",
+			zap.String("subscription", id),
+			zap.String("group", params.Group),
+			zap.String("version", params.Version),
+			zap.String("kind", params.Kind),
+			zap.String("namespace", params.Namespace),
+			zap.Error(err))
+		s.sendError(ctx, id, err)
+		return
+	}
+
+	subscription := &socketSubscription{cancel: cancel}
+	s.mu.Lock()
+	s.subscriptions[id] = subscription
+	s.mu.Unlock()
+
+	s.send(ctx, socketMessage{Subscription: id, Type: "connected", Payload: connectedPayload(params, request.Revision)})
+	go s.forward(watchCtx, id, subscription, events)
+}
+
+func (s *socketSession) unsubscribe(id string) {
+	s.mu.Lock()
+	subscription, ok := s.subscriptions[id]
+	s.mu.Unlock()
+
+	if ok {
+		s.remove(id, subscription)
+	}
+}
+
+// remove stops the subscription, the id may already belong to a new subscription of the client
+func (s *socketSession) remove(id string, subscription *socketSubscription) {
+	s.mu.Lock()
+	if s.subscriptions[id] == subscription {
+		delete(s.subscriptions, id)
+	}
+	s.mu.Unlock()
+
+	subscription.cancel()
+}
+
+func (s *socketSession) ack(id string, revision int64) {
+	s.mu.Lock()
+	subscription, ok := s.subscriptions[id]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	subscription.mu.Lock()
+	defer subscription.mu.Unlock()
+	if revision >= subscription.bookmarkRevision {
+		subscription.bookmarkPending = false
+	}
+}
+
+// forward sends the events of a subscription until it is removed or its watch ends
+// A watch that ends on its own is reported as an error, the client subscribes again from its last revision
+func (s *socketSession) forward(ctx context.Context, id string, subscription *socketSubscription, events <-chan types.WatchEvent) {
+	defer s.remove(id, subscription)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-events:
+			if !ok {
+				s.send(ctx, socketMessage{Subscription: id, Type: "error", Payload: socketErrorPayload(http.StatusServiceUnavailable, "Watch ended")})
+				return
+			}
+
+			if event.Type == types.WatchEventTypeError && repository.IsExpiredError(event.Error) {
+				s.sendError(ctx, id, event.Error)
+				return
+			}
+
+			if event.Type == types.WatchEventTypeBookmark {
+				if !subscription.takeBookmark(event) {
+					continue
+				}
+				s.send(ctx, socketMessage{Subscription: id, Type: "bookmark", Payload: bookmarkPayload(event)})
+				continue
+			}
+
+			s.send(ctx, socketMessage{Subscription: id, Type: "event", Payload: resourceEventPayload(event)})
+		}
+	}
+}
+
+// takeBookmark reports whether the bookmark is to be sent, the one ending the initial events always is
+func (s *socketSubscription) takeBookmark(event types.WatchEvent) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.bookmarkPending && !event.InitialEventsEnd {
+		return false
+	}
+	s.bookmarkPending = true
+	s.bookmarkRevision = event.Revision
+	return true
+}
+
+func (s *socketSession) send(ctx context.Context, message socketMessage) {
+	select {
+	case s.outgoing <- message:
+	case <-ctx.Done():
+	}
+}
+
+// sendError reports an error of a subscription with the status and body a REST request would get
+func (s *socketSession) sendError(ctx context.Context, id string, err error) {
+	status, body := middleware.MapError(err, s.handler.logger)
+	message, _ := body["error"].(string)
+
+	payload := socketErrorPayload(status, message)
+	if details, ok := body["details"]; ok {
+		payload["details"] = details
+	}
+
+	s.send(ctx, socketMessage{Subscription: id, Type: "error", Payload: payload})
+}
+
+func socketErrorPayload(status int, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"error":     message,
+		"code":      status,
+		"timestamp": time.Now().UTC(),
+	}
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/config"
+	"github.com/tsamsiyu/themelio/api/internal/repository"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	servicetypes "github.com/tsamsiyu/themelio/api/internal/service/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+type testSocketMessage struct {
+	Subscription string                 `json:"subscription"`
+	Type         string                 `json:"type"`
+	Payload      map[string]interface{} `json:"payload"`
+}
+
+func newSocketTestServer(t *testing.T, mockService *mocks.MockResourceService) *websocket.Conn {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Server: config.ServerConfig{AdminToken: "secret"}}
+	handler := NewWatchSocketHandler(cfg, zap.NewNop(), mockService)
+
+	router := gin.New()
+	router.GET("/ws", handler.Watch)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readSocketMessage(t *testing.T, conn *websocket.Conn) testSocketMessage {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var message testSocketMessage
+	require.NoError(t, conn.ReadJSON(&message))
+	return message
+}
+
+func TestWatchSocketHandler_Subscriptions(t *testing.T) {
+	mockService := mocks.NewMockResourceService(t)
+
+	// Given: Two kinds watched over one socket
+	networks := make(chan types.WatchEvent, 1)
+	subnets := make(chan types.WatchEvent, 1)
+	cancelled := make(chan struct{})
+
+	networkParams := servicetypes.Params{Group: "example.com", Version: "v1", Kind: "Network"}
+	subnetParams := servicetypes.Params{Group: "example.com", Version: "v1", Kind: "Subnet", Namespace: "default", LabelSelector: "tier=db"}
+	mockService.EXPECT().WatchResource(mock.Anything, networkParams, types.WatchOptions{Revision: 5}).
+		RunAndReturn(func(ctx context.Context, _ servicetypes.Params, _ types.WatchOptions) (<-chan types.WatchEvent, error) {
+			go func() {
+				<-ctx.Done()
+				close(cancelled)
+			}()
+			return networks, nil
+		})
+	mockService.EXPECT().WatchResource(mock.Anything, subnetParams, types.WatchOptions{SendInitialEvents: true}).
+		Return((<-chan types.WatchEvent)(subnets), nil)
+
+	conn := newSocketTestServer(t, mockService)
+
+	// When: The client subscribes to both
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{
+		"type": "subscribe", "subscription": "networks", "revision": 5,
+		"params": map[string]string{"group": "example.com", "version": "v1", "kind": "Network"},
+	}))
+	assert.Equal(t, "connected", readSocketMessage(t, conn).Type)
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{
+		"type": "subscribe", "subscription": "subnets", "sendInitialEvents": true,
+		"params": map[string]string{"group": "example.com", "version": "v1", "kind": "Subnet", "namespace": "default", "labelSelector": "tier=db"},
+	}))
+	assert.Equal(t, "subnets", readSocketMessage(t, conn).Subscription)
+
+	// Then: The events of each come tagged with their subscription
+	networks <- types.WatchEvent{Type: types.WatchEventTypeAdded, Object: &sdkmeta.Object{}, Revision: 6}
+	message := readSocketMessage(t, conn)
+	assert.Equal(t, "networks", message.Subscription)
+	assert.Equal(t, "event", message.Type)
+	assert.Equal(t, "added", message.Payload["type"])
+	assert.Equal(t, float64(6), message.Payload["revision"])
+
+	subnets <- types.WatchEvent{Type: types.WatchEventTypeModified, Object: &sdkmeta.Object{}, Revision: 7}
+	message = readSocketMessage(t, conn)
+	assert.Equal(t, "subnets", message.Subscription)
+	assert.Equal(t, "modified", message.Payload["type"])
+
+	// When: The client unsubscribes from one
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "unsubscribe", "subscription": "networks"}))
+
+	// Then: Its watch is stopped
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("watch wasn't stopped")
+	}
+}
+
+func TestWatchSocketHandler_BookmarkAck(t *testing.T) {
+	mockService := mocks.NewMockResourceService(t)
+
+	events := make(chan types.WatchEvent, 3)
+	mockService.EXPECT().WatchResource(mock.Anything, mock.Anything, types.WatchOptions{}).Return((<-chan types.WatchEvent)(events), nil)
+
+	conn := newSocketTestServer(t, mockService)
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{
+		"type": "subscribe", "subscription": "networks",
+		"params": map[string]string{"group": "example.com", "version": "v1", "kind": "Network"},
+	}))
+	readSocketMessage(t, conn)
+
+	// Given: A bookmark the client hasn't acked
+	events <- types.WatchEvent{Type: types.WatchEventTypeBookmark, Revision: 10}
+	message := readSocketMessage(t, conn)
+	assert.Equal(t, "bookmark", message.Type)
+	assert.Equal(t, float64(10), message.Payload["revision"])
+
+	// When: Another bookmark comes before the ack
+	events <- types.WatchEvent{Type: types.WatchEventTypeBookmark, Revision: 11}
+	events <- types.WatchEvent{Type: types.WatchEventTypeAdded, Object: &sdkmeta.Object{}, Revision: 12}
+
+	// Then: It is dropped
+	message = readSocketMessage(t, conn)
+	assert.Equal(t, "event", message.Type)
+
+	// When: The client acks the first bookmark
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "ack", "subscription": "networks", "revision": 10}))
+	time.Sleep(10 * time.Millisecond)
+
+	// Then: The next bookmark is sent
+	events <- types.WatchEvent{Type: types.WatchEventTypeBookmark, Revision: 13}
+	message = readSocketMessage(t, conn)
+	assert.Equal(t, "bookmark", message.Type)
+	assert.Equal(t, float64(13), message.Payload["revision"])
+}
+
+func TestWatchSocketHandler_SubscribeErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		request    map[string]interface{}
+		serviceErr error
+		wantCode   float64
+	}{
+		{
+			name:     "every object without admin token",
+			request:  map[string]interface{}{"type": "subscribe", "subscription": "all"},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "initial events with revision",
+			request: map[string]interface{}{
+				"type": "subscribe", "subscription": "networks", "revision": 5, "sendInitialEvents": true,
+				"params": map[string]string{"group": "example.com", "version": "v1", "kind": "Network"},
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "compacted revision",
+			request: map[string]interface{}{
+				"type": "subscribe", "subscription": "networks", "revision": 3,
+				"params": map[string]string{"group": "example.com", "version": "v1", "kind": "Network"},
+			},
+			serviceErr: repository.NewExpiredError(3),
+			wantCode:   http.StatusGone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockResourceService(t)
+			if tt.serviceErr != nil {
+				mockService.EXPECT().WatchResource(mock.Anything, mock.Anything, mock.Anything).Return(nil, tt.serviceErr)
+			}
+
+			conn := newSocketTestServer(t, mockService)
+			require.NoError(t, conn.WriteJSON(tt.request))
+
+			message := readSocketMessage(t, conn)
+			assert.Equal(t, tt.request["subscription"], message.Subscription)
+			assert.Equal(t, "error", message.Type)
+			assert.Equal(t, tt.wantCode, message.Payload["code"])
+		})
+	}
+}
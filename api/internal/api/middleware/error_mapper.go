@@ -19,13 +19,14 @@ func ErrorMapper(logger *zap.Logger) gin.HandlerFunc {
 
 		if len(c.Errors) > 0 {
 			err := c.Errors.Last().Err
-			status, body := mapError(err, logger)
+			status, body := MapError(err, logger)
 			c.JSON(status, body)
 		}
 	}
 }
 
-func mapError(err error, logger *zap.Logger) (int, interface{}) {
+// MapError returns the HTTP status and response body of an error, for transports that report errors themselves
+func MapError(err error, logger *zap.Logger) (int, gin.H) {
 	switch e := err.(type) {
 	case validator.ValidationErrors:
 		var errors []string
@@ -47,6 +48,10 @@ func mapError(err error, logger *zap.Logger) (int, interface{}) {
 		}
 	case *repository.NotFoundError:
 		return http.StatusNotFound, gin.H{"error": e.Error()}
+	case *repository.ConflictError:
+		return http.StatusConflict, gin.H{"error": e.Error()}
+	case *repository.ExpiredError:
+		return http.StatusGone, gin.H{"error": e.Error()}
 	case *errors.MarshalingError:
 		logger.Error("Marshaling error", zap.String("message", e.Message))
 		return http.StatusInternalServerError, gin.H{
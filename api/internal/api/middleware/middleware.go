@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -46,3 +48,21 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireAdminToken lets through only requests with the admin bearer token, all are refused when no token is configured
+func RequireAdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasAdminToken(c, token) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// HasAdminToken reports whether the request carries the admin bearer token, never when no token is configured
+func HasAdminToken(c *gin.Context, token string) bool {
+	provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return token != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
@@ -20,7 +20,14 @@ type Server struct {
 	server *http.Server
 }
 
-func NewRouter(logger *zap.Logger, resourceHandler *handlers.ResourceHandler, watchHandler *handlers.WatchHandler) *gin.Engine {
+func NewRouter(
+	cfg *config.Config,
+	logger *zap.Logger,
+	resourceHandler *handlers.ResourceHandler,
+	watchHandler *handlers.WatchHandler,
+	watchSocketHandler *handlers.WatchSocketHandler,
+	schemaHandler *handlers.SchemaHandler,
+) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 
@@ -31,6 +38,12 @@ func NewRouter(logger *zap.Logger, resourceHandler *handlers.ResourceHandler, wa
 
 	api := router.Group("/api/v1")
 	{
+		// changes of every object, each event carries the key of its object
+		api.GET("/watch", middleware.RequireAdminToken(cfg.Server.AdminToken), watchHandler.WatchResource)
+		api.GET("/namespaces/:namespace/watch", watchHandler.WatchResource)
+		// one socket for many watches, subscriptions are added and removed by messages of the client
+		api.GET("/ws", watchSocketHandler.Watch)
+
 		resources := api.Group("/resources")
 		{
 			resources.PUT("/:group/:version/:kind", resourceHandler.ReplaceResource)
@@ -39,6 +52,30 @@ func NewRouter(logger *zap.Logger, resourceHandler *handlers.ResourceHandler, wa
 			resources.DELETE("/:group/:version/:kind/:name", resourceHandler.DeleteResource)
 			resources.PATCH("/:group/:version/:kind/:name", resourceHandler.PatchResource)
 			resources.GET("/:group/:version/:kind/watch", watchHandler.WatchResource)
+			resources.PUT("/:group/:version/:kind/:name/status", resourceHandler.ReplaceResourceStatus)
+			resources.PATCH("/:group/:version/:kind/:name/status", resourceHandler.PatchResourceStatus)
+			resources.DELETE("/:group/:version/:kind/:name/finalizers/*finalizer", resourceHandler.RemoveFinalizer)
+
+			namespaced := resources.Group("/:group/:version/namespaces/:namespace")
+			{
+				namespaced.PUT("/:kind", resourceHandler.ReplaceResource)
+				namespaced.GET("/:kind/:name", resourceHandler.GetResource)
+				namespaced.GET("/:kind", resourceHandler.ListResources)
+				namespaced.DELETE("/:kind/:name", resourceHandler.DeleteResource)
+				namespaced.PATCH("/:kind/:name", resourceHandler.PatchResource)
+				namespaced.GET("/:kind/watch", watchHandler.WatchResource)
+				namespaced.PUT("/:kind/:name/status", resourceHandler.ReplaceResourceStatus)
+				namespaced.PATCH("/:kind/:name/status", resourceHandler.PatchResourceStatus)
+				namespaced.DELETE("/:kind/:name/finalizers/*finalizer", resourceHandler.RemoveFinalizer)
+			}
+		}
+
+		schemas := api.Group("/schemas")
+		{
+			schemas.PUT("", schemaHandler.ReplaceSchema)
+			schemas.GET("", schemaHandler.ListSchemas)
+			schemas.GET("/:group/:kind", schemaHandler.GetSchema)
+			schemas.DELETE("/:group/:kind", schemaHandler.DeleteSchema)
 		}
 	}
 
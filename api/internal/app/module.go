@@ -11,6 +11,7 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/tsamsiyu/themelio/api/internal/api/grpcserver"
 	"github.com/tsamsiyu/themelio/api/internal/api/handlers"
 	"github.com/tsamsiyu/themelio/api/internal/api/server"
 	"github.com/tsamsiyu/themelio/api/internal/config"
@@ -44,8 +45,13 @@ var APIModule = fx.Options(
 	fx.Provide(
 		handlers.NewResourceHandler,
 		handlers.NewWatchHandler,
+		handlers.NewWatchSocketHandler,
+		handlers.NewSchemaHandler,
 		server.NewRouter,
 		server.NewServer,
+		grpcserver.NewResourceServer,
+		grpcserver.NewSchemaServer,
+		grpcserver.NewServer,
 	),
 )
 
@@ -53,7 +59,7 @@ var APIModule = fx.Options(
 var WorkerModule = fx.Options(
 	CommonModule,
 	fx.Provide(
-		gc.NewWorker,
+		NewGCWorker,
 	),
 )
 
@@ -105,9 +111,18 @@ func NewETCDClient(cfg *config.Config) (*clientv3.Client, error) {
 	return client, nil
 }
 
-func NewResourceRepository(logger *zap.Logger, store types.ResourceStore, clientWrapper types.ClientWrapper) types.ResourceRepository {
+func NewResourceRepository(
+	cfg *config.Config,
+	logger *zap.Logger,
+	store types.ResourceStore,
+	clientWrapper types.ClientWrapper,
+) types.ResourceRepository {
 	watchConfig := types.WatchConfig{
-		MaxRetries: 5,
+		MaxRetries:           5,
+		HistorySize:          1000,
+		BookmarkInterval:     30 * time.Second,
+		ReadCache:            cfg.Cache.Enabled,
+		ReadCacheWaitTimeout: cfg.Cache.WaitTimeout,
 	}
 	backoffConfig := lib.BackoffConfig{
 		InitialBackoff:    800 * time.Millisecond,
@@ -119,6 +134,13 @@ func NewResourceRepository(logger *zap.Logger, store types.ResourceStore, client
 	return repository.NewResourceRepository(logger, store, clientWrapper, watchConfig, backoffManager)
 }
 
+func NewGCWorker(logger *zap.Logger, repo types.ResourceRepository, clientWrapper types.ClientWrapper) *gc.Worker {
+	gcConfig := gc.DefaultConfig()
+	membership := repository.NewMembership(logger, clientWrapper, gcConfig.MembershipGroup, gcConfig.MembershipTTL)
+	election := repository.NewLeaderElection(logger, clientWrapper, gcConfig.ScanElection, gcConfig.MembershipTTL)
+	return gc.NewWorker(logger, repo, membership, election, gcConfig)
+}
+
 func createTLSConfig(tlsCfg config.TLSConfig) (*tls.Config, error) {
 	// TODO: Implement TLS configuration
 	// This would typically load certificates and create a proper TLS config
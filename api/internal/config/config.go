@@ -8,8 +8,10 @@ import (
 
 type Config struct {
 	Server  ServerConfig  `envPrefix:"SERVER_"`
+	GRPC    GRPCConfig    `envPrefix:"GRPC_"`
 	ETCD    ETCDConfig    `envPrefix:"ETCD_"`
 	Logging LoggingConfig `envPrefix:"LOGGING_"`
+	Cache   CacheConfig   `envPrefix:"CACHE_"`
 }
 
 type ServerConfig struct {
@@ -18,6 +20,15 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration `env:"READ_TIMEOUT" envDefault:"30s"`
 	WriteTimeout    time.Duration `env:"WRITE_TIMEOUT" envDefault:"30s"`
 	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"10s"`
+	// AdminToken is the bearer token of privileged endpoints, they are disabled when it is empty
+	AdminToken string `env:"ADMIN_TOKEN"`
+}
+
+// GRPCConfig is the listener of the gRPC API, the timeouts and admin token of ServerConfig apply to it as well
+type GRPCConfig struct {
+	Enabled bool   `env:"ENABLED" envDefault:"true"`
+	Port    int    `env:"PORT" envDefault:"9090"`
+	Host    string `env:"HOST" envDefault:"0.0.0.0"`
 }
 
 type ETCDConfig struct {
@@ -40,6 +51,12 @@ type TLSConfig struct {
 	InsecureSkipVerify bool   `env:"INSECURE_SKIP_VERIFY" envDefault:"false"`
 }
 
+// CacheConfig enables serving reads that ask for a minimum revision from memory
+type CacheConfig struct {
+	Enabled     bool          `env:"ENABLED" envDefault:"false"`
+	WaitTimeout time.Duration `env:"WAIT_TIMEOUT" envDefault:"3s"`
+}
+
 type LoggingConfig struct {
 	Level            string `env:"LEVEL" envDefault:"info"`
 	Format           string `env:"FORMAT" envDefault:"json"`
@@ -2,7 +2,9 @@ package lib
 
 import (
 	"crypto/rand"
+	"fmt"
 	"math/big"
+	"os"
 )
 
 const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
@@ -18,3 +20,29 @@ func RandString(n int) (string, error) {
 	}
 	return string(result), nil
 }
+
+// NewIdentity returns an identifier unique to the running process, prefixed with the hostname
+func NewIdentity() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+
+	suffix, err := RandString(8)
+	if err != nil {
+		return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	return fmt.Sprintf("%s-%s", hostname, suffix)
+}
+
+// NewUID returns a random version 4 UUID
+func NewUID() string {
+	var b [16]byte
+	// crypto/rand.Read never fails, the program is crashed instead
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
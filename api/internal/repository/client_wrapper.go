@@ -5,6 +5,7 @@ import (
 
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 
@@ -61,20 +62,67 @@ func (c *clientWrapper) Delete(ctx context.Context, key string) error {
 	return err
 }
 
+// maxTxnOps stays below the default limit of operations in one etcd transaction
+const maxTxnOps = 100
+
+func (c *clientWrapper) GetMany(ctx context.Context, keys []string, revision int64) (*types.Batch, error) {
+	batch := &types.Batch{Revision: revision}
+
+	for start := 0; start < len(keys); start += maxTxnOps {
+		end := start + maxTxnOps
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		ops := make([]clientv3.Op, 0, end-start)
+		for _, key := range keys[start:end] {
+			if batch.Revision > 0 {
+				ops = append(ops, clientv3.OpGet(key, clientv3.WithRev(batch.Revision)))
+			} else {
+				ops = append(ops, clientv3.OpGet(key))
+			}
+		}
+
+		resp, err := c.client.Txn(ctx).Then(ops...).Commit()
+		if err != nil {
+			if errors.Is(err, rpctypes.ErrCompacted) {
+				return nil, NewExpiredError(batch.Revision)
+			}
+			return nil, errors.Wrap(err, "failed to get keys from etcd")
+		}
+
+		// later chunks are read at the revision of the first one
+		if batch.Revision == 0 {
+			batch.Revision = resp.Header.Revision
+		}
+
+		for _, opResp := range resp.Responses {
+			for _, kv := range opResp.GetResponseRange().Kvs {
+				batch.KVs = append(batch.KVs, convertClientKV(kv))
+			}
+		}
+	}
+
+	return batch, nil
+}
+
+// List reads keys of the prefix in key order, a page continues after LastKey when it is set
 func (c *clientWrapper) List(ctx context.Context, paging types.Paging) (*types.Batch, error) {
-	opts := make([]clientv3.OpOption, 0, 5)
+	if paging.Prefix == "" {
+		return nil, errors.New("prefix is required")
+	}
 
-	queryKey := paging.Prefix
+	startKey, endKey := listRange(paging)
+
+	opts := make([]clientv3.OpOption, 0, 5)
+	opts = append(opts, clientv3.WithRange(endKey))
 
 	if paging.Limit > 0 {
-		if paging.LastKey != "" {
-			opts = append(opts, clientv3.WithLastKey()...)
-			opts = append(opts, clientv3.WithLimit(int64(paging.Limit+1)))
-			queryKey = paging.LastKey
-		} else {
-			opts = append(opts, clientv3.WithPrefix())
-			opts = append(opts, clientv3.WithLimit(int64(paging.Limit)))
-		}
+		opts = append(opts, clientv3.WithLimit(int64(paging.Limit)))
+	}
+
+	if paging.Revision > 0 {
+		opts = append(opts, clientv3.WithRev(paging.Revision))
 	}
 
 	if paging.MinModRevision > 0 {
@@ -87,29 +135,51 @@ func (c *clientWrapper) List(ctx context.Context, paging types.Paging) (*types.B
 		opts = append(opts, clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
 	}
 
-	if queryKey == "" {
-		return nil, errors.New("prefix or last key is required")
-	}
-
-	resp, err := c.client.Get(ctx, queryKey, opts...)
+	resp, err := c.client.Get(ctx, startKey, opts...)
 	if err != nil {
+		if errors.Is(err, rpctypes.ErrCompacted) {
+			return nil, NewExpiredError(paging.Revision)
+		}
 		return nil, errors.Wrapf(err, "failed to list from etcd with prefix %s", paging.Prefix)
 	}
 
-	var kvs []types.KeyValue
-	for i, kv := range resp.Kvs {
-		if i == 0 && !paging.IncludeLastKeyInBatch {
-			continue
-		}
+	kvs := make([]types.KeyValue, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
 		kvs = append(kvs, convertClientKV(kv))
 	}
 
 	return &types.Batch{
 		Revision: resp.Header.Revision,
 		KVs:      kvs,
+		More:     resp.More,
 	}, nil
 }
 
+// listRange returns the range of keys a page is read from, the range end is exclusive,
+// so a descending page ends at LastKey and an ascending one starts right after it
+func listRange(paging types.Paging) (string, string) {
+	startKey := paging.Prefix
+	endKey := clientv3.GetPrefixRangeEnd(paging.Prefix)
+
+	if paging.LastKey == "" {
+		return startKey, endKey
+	}
+
+	if paging.SortDesc {
+		endKey = paging.LastKey
+		if paging.IncludeLastKeyInBatch {
+			endKey += "\x00"
+		}
+	} else {
+		startKey = paging.LastKey
+		if !paging.IncludeLastKeyInBatch {
+			startKey += "\x00"
+		}
+	}
+
+	return startKey, endKey
+}
+
 func (c *clientWrapper) ExecuteTransaction(ctx context.Context, ops []clientv3.Op) (*clientv3.TxnResponse, error) {
 	txn := c.client.Txn(ctx)
 	txnResp, err := txn.Then(ops...).Commit()
@@ -172,7 +242,7 @@ func (c *clientWrapper) KeepAliveLease(ctx context.Context, leaseID clientv3.Lea
 }
 
 func (c *clientWrapper) Watch(ctx context.Context, prefix string, revision ...int64) (<-chan clientv3.WatchResponse, error) {
-	opts := []clientv3.OpOption{clientv3.WithPrefix()}
+	opts := []clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithPrevKV(), clientv3.WithProgressNotify()}
 
 	if len(revision) > 0 && revision[0] > 0 {
 		opts = append(opts, clientv3.WithRev(revision[0]))
@@ -182,6 +252,15 @@ func (c *clientWrapper) Watch(ctx context.Context, prefix string, revision ...in
 	return watchChan, nil
 }
 
+// RequestProgress makes etcd send a progress notification to the watches of the client, it carries the revision
+// every event up to has been sent
+func (c *clientWrapper) RequestProgress(ctx context.Context) error {
+	if err := c.client.RequestProgress(ctx); err != nil {
+		return errors.Wrap(err, "failed to request watch progress")
+	}
+	return nil
+}
+
 func convertClientKV(kv *mvccpb.KeyValue) types.KeyValue {
 	return types.KeyValue{
 		Key:            string(kv.Key),
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+)
+
+func TestListRange_Continue(t *testing.T) {
+	keys := []string{"/p/a", "/p/b", "/p/c", "/p/d"}
+
+	tests := []struct {
+		name     string
+		sortDesc bool
+		include  bool
+		expected [][]string
+	}{
+		{name: "ascending", expected: [][]string{{"/p/a", "/p/b"}, {"/p/c", "/p/d"}}},
+		{name: "descending", sortDesc: true, expected: [][]string{{"/p/d", "/p/c"}, {"/p/b", "/p/a"}}},
+		{name: "ascending including the last key", include: true, expected: [][]string{{"/p/a", "/p/b"}, {"/p/b", "/p/c"}}},
+		{name: "descending including the last key", sortDesc: true, include: true, expected: [][]string{{"/p/d", "/p/c"}, {"/p/c", "/p/b"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given: The first page of two keys
+			paging := types.Paging{Prefix: "/p/", Limit: 2, SortDesc: tt.sortDesc, IncludeLastKeyInBatch: tt.include}
+			first := readRange(keys, paging)
+
+			// When: Continuing after its last key
+			paging.LastKey = first[len(first)-1]
+			second := readRange(keys, paging)
+
+			// Then: The next page follows in the same order
+			assert.Equal(t, tt.expected, [][]string{first, second})
+		})
+	}
+}
+
+// readRange reads a page of the sorted keys the way etcd reads the range of the paging
+func readRange(keys []string, paging types.Paging) []string {
+	startKey, endKey := listRange(paging)
+
+	var page []string
+	for _, key := range keys {
+		if key >= startKey && key < endKey {
+			page = append(page, key)
+		}
+	}
+	if paging.SortDesc {
+		sort.Sort(sort.Reverse(sort.StringSlice(page)))
+	}
+	if len(page) > paging.Limit {
+		page = page[:paging.Limit]
+	}
+	return page
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	internalerrors "github.com/tsamsiyu/themelio/api/internal/errors"
+)
+
+// continueToken is what the opaque continue token of a list carries between pages
+type continueToken struct {
+	Revision int64  `json:"rev"`
+	LastKey  string `json:"key"`
+}
+
+func encodeContinueToken(revision int64, lastKey string) (string, error) {
+	data, err := json.Marshal(continueToken{Revision: revision, LastKey: lastKey})
+	if err != nil {
+		return "", internalerrors.NewMarshalingError("failed to marshal continue token")
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeContinueToken also makes sure the token was issued for a list of the same prefix
+func decodeContinueToken(token string, prefix string) (*continueToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, internalerrors.NewInvalidInputError("invalid continue token")
+	}
+
+	var decoded continueToken
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, internalerrors.NewInvalidInputError("invalid continue token")
+	}
+
+	if decoded.Revision <= 0 || !strings.HasPrefix(decoded.LastKey, prefix) {
+		return nil, internalerrors.NewInvalidInputError("continue token does not belong to this list")
+	}
+
+	return &decoded, nil
+}
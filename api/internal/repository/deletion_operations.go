@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -38,148 +39,312 @@ func (b *DeletionOpBuilder) BuildMarkDeletionOperation(key sdkmeta.ObjectKey) (*
 	return &op, nil
 }
 
+// BuildChildrenCleanupOps builds the operations that settle the children of a deleted object by its propagation policy,
+// grouped by child so the operations of a child are never split between transactions
+// Orphaned children and the ones held by another blocking owner lose the reference, the rest are marked for deletion
 func (b *DeletionOpBuilder) BuildChildrenCleanupOps(
 	obj *sdkmeta.Object,
 	children []*sdkmeta.Object,
-) ([]clientv3.Op, error) {
-	var allOps []clientv3.Op
+) ([][]clientv3.Op, error) {
+	var allOps [][]clientv3.Op
 
-	refCleanupOps, err := b.buildChildrenReferenceCleanupOps(obj, children)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to build children reference cleanup operations")
-	}
-	allOps = append(allOps, refCleanupOps...)
-
-	markDeletionOps, err := b.buildChildrenMarkDeletionOps(obj, children)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to build children mark deletion operations")
+	for _, child := range children {
+		orphan := obj.SystemMeta.PropagationPolicy == sdkmeta.PropagationPolicyOrphan ||
+			hasOtherBlockingReference(child.ObjectMeta.OwnerReferences, obj)
+
+		var ops []clientv3.Op
+		var err error
+		if orphan {
+			ops, err = b.buildChildReferenceCleanupOps(obj, child)
+		} else {
+			ops, err = b.buildChildMarkDeletionOps(child, sdkmeta.PropagationPolicyBackground)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(ops) > 0 {
+			allOps = append(allOps, ops)
+		}
 	}
-	allOps = append(allOps, markDeletionOps...)
 
 	return allOps, nil
 }
 
-// buildChildrenReferenceCleanupOps builds operations to remove owner references from children
-func (b *DeletionOpBuilder) buildChildrenReferenceCleanupOps(
+// BuildChildrenForegroundOps builds operations marking the children of an object deleted in the foreground grouped by child,
+// it also returns how many of them block the deletion of the object
+// Children held by another blocking owner are left, they only lose the reference once the object is deleted
+func (b *DeletionOpBuilder) BuildChildrenForegroundOps(
 	obj *sdkmeta.Object,
 	children []*sdkmeta.Object,
-) ([]clientv3.Op, error) {
-	var ops []clientv3.Op
+) ([][]clientv3.Op, int, error) {
+	var allOps [][]clientv3.Op
+	pending := 0
 
 	for _, child := range children {
-		hasBlockingParent := hasOtherBlockingReference(child.ObjectMeta.OwnerReferences, obj.SystemMeta.UID)
+		if hasOtherBlockingReference(child.ObjectMeta.OwnerReferences, obj) {
+			continue
+		}
 
-		if hasBlockingParent {
-			newOwnerRefs := removeOwnerReference(child.ObjectMeta.OwnerReferences, obj.SystemMeta.UID)
-			child.ObjectMeta.OwnerReferences = newOwnerRefs
+		if blocksOwnerDeletion(child.ObjectMeta.OwnerReferences, obj) {
+			pending++
+		}
 
-			setOp, err := b.store.BuildPutTxOp(child)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to build set operation for updated child resource")
-			}
-			ops = append(ops, setOp)
+		ops, err := b.buildChildMarkDeletionOps(child, sdkmeta.PropagationPolicyForeground)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(ops) > 0 {
+			allOps = append(allOps, ops)
 		}
 	}
 
-	return ops, nil
+	return allOps, pending, nil
 }
 
-// buildChildrenMarkDeletionOps builds operations to mark children for deletion
-func (b *DeletionOpBuilder) buildChildrenMarkDeletionOps(
-	obj *sdkmeta.Object,
-	children []*sdkmeta.Object,
-) ([]clientv3.Op, error) {
-	var ops []clientv3.Op
+// buildChildReferenceCleanupOps builds operations removing the owner reference to the object from the child
+func (b *DeletionOpBuilder) buildChildReferenceCleanupOps(obj *sdkmeta.Object, child *sdkmeta.Object) ([]clientv3.Op, error) {
+	child.ObjectMeta.OwnerReferences = removeOwnerReference(child.ObjectMeta.OwnerReferences, *obj.ObjectKey, obj.SystemMeta.UID)
 
-	for _, child := range children {
-		hasBlockingParent := hasOtherBlockingReference(child.ObjectMeta.OwnerReferences, obj.SystemMeta.UID)
-		if !hasBlockingParent {
-			deletionOp, err := b.BuildMarkDeletionOperation(*child.ObjectKey)
+	setOp, err := b.store.BuildPutTxOp(child)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build set operation for updated child resource")
+	}
+
+	indexOp := clientv3.OpDelete(buildOwnerReferenceIndexDbKey(*obj.ObjectKey, *child.ObjectKey))
+
+	return []clientv3.Op{setOp, indexOp}, nil
+}
+
+// buildChildMarkDeletionOps builds operations marking the child for deletion with the policy,
+// a child that is already being deleted is left as it is
+func (b *DeletionOpBuilder) buildChildMarkDeletionOps(child *sdkmeta.Object, policy sdkmeta.PropagationPolicy) ([]clientv3.Op, error) {
+	if child.SystemMeta.DeletionTime != nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	child.SystemMeta.DeletionTime = &now
+	child.SystemMeta.PropagationPolicy = policy
+
+	setOp, err := b.store.BuildPutTxOp(child)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build set operation for child marked for deletion")
+	}
+
+	deletionOp, err := b.BuildMarkDeletionOperation(*child.ObjectKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build mark deletion operation for child")
+	}
+
+	return []clientv3.Op{setOp, *deletionOp}, nil
+}
+
+// ListDeletions reads the deletion records page by page at one revision and sorts them oldest first
+func (b *DeletionOpBuilder) ListDeletions(ctx context.Context, batchLimit int) ([]types.DeletionRecord, int64, error) {
+	var records []types.DeletionRecord
+	paging := types.Paging{Prefix: deletionDbKeyPrefix, Limit: batchLimit}
+
+	for {
+		batch, err := b.clientWrapper.List(ctx, paging)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to list deletion records from etcd")
+		}
+
+		for _, kv := range batch.KVs {
+			record, err := parseDeletionRecord(kv.Key, kv.Value)
 			if err != nil {
-				return nil, errors.Wrap(err, "failed to build mark deletion operation for child")
+				return nil, 0, err
 			}
-			ops = append(ops, *deletionOp)
+			records = append(records, record)
 		}
+
+		paging.Revision = batch.Revision
+		if !batch.More || len(batch.KVs) == 0 {
+			break
+		}
+		paging.LastKey = batch.KVs[len(batch.KVs)-1].Key
 	}
 
-	return ops, nil
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+
+	return records, paging.Revision, nil
 }
 
-func (b *DeletionOpBuilder) ListDeletions(ctx context.Context, batchLimit int) (map[sdkmeta.ObjectKey]time.Time, error) {
-	prefix := "/deletion/"
-	batch, err := b.clientWrapper.List(ctx, types.Paging{Prefix: prefix, Limit: batchLimit})
+// WatchDeletions streams the changes of the deletion records made after the revision,
+// the channel is closed after an error event or once ctx is done
+func (b *DeletionOpBuilder) WatchDeletions(ctx context.Context, revision int64) (<-chan types.DeletionEvent, error) {
+	watchChan, err := b.clientWrapper.Watch(ctx, deletionDbKeyPrefix, revision)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to list deletion records from etcd")
+		return nil, errors.Wrap(err, "failed to watch deletion records")
 	}
 
-	records := make(map[sdkmeta.ObjectKey]time.Time)
-	for _, kv := range batch.KVs {
-		key, err := parseDeletionKey(kv.Key)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to parse deletion key")
+	events := make(chan types.DeletionEvent)
+	go func() {
+		defer close(events)
+
+		send := func(event types.DeletionEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
 		}
-		value, err := time.Parse(time.RFC3339, string(kv.Value))
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to parse deletion record")
+
+		for watchResp := range watchChan {
+			if err := watchResp.Err(); err != nil {
+				send(types.DeletionEvent{Revision: watchResp.CompactRevision, Error: err})
+				return
+			}
+
+			for _, ev := range watchResp.Events {
+				event := types.DeletionEvent{Revision: ev.Kv.ModRevision}
+				if ev.Type == clientv3.EventTypeDelete {
+					key, err := parseDeletionKey(string(ev.Kv.Key))
+					if err != nil {
+						b.logger.Warn("Skipping invalid deletion record", zap.String("key", string(ev.Kv.Key)), zap.Error(err))
+						continue
+					}
+					event.Record = types.DeletionRecord{ObjectKey: key}
+					event.Removed = true
+				} else {
+					record, err := parseDeletionRecord(string(ev.Kv.Key), ev.Kv.Value)
+					if err != nil {
+						b.logger.Warn("Skipping invalid deletion record", zap.String("key", string(ev.Kv.Key)), zap.Error(err))
+						continue
+					}
+					event.Record = record
+				}
+
+				if !send(event) {
+					return
+				}
+			}
 		}
-		records[key] = value
-	}
+	}()
 
-	return records, nil
+	return events, nil
 }
 
-func (b *DeletionOpBuilder) AcquireDeletions(ctx context.Context, lockKey string, lockExp time.Duration, batchLimit int) (*types.DeletionBatch, error) {
-	leaseResp, err := b.clientWrapper.GrantLease(ctx, int64(lockExp.Seconds()))
+// AcquireDeletion takes the deletion lock of the object, a lock lockKey holds already is kept with its lease,
+// the lock is bound to a lease so it is released once lockExp passes without the deletion being done
+func (b *DeletionOpBuilder) AcquireDeletion(ctx context.Context, key sdkmeta.ObjectKey, lockKey string, lockExp time.Duration) (bool, error) {
+	lockDbKey := deletionLockDbKey(key)
+
+	ifLockedByItselfOp := clientv3.Compare(clientv3.Value(lockDbKey), "=", lockKey)
+	txnResp, err := b.clientWrapper.Client().Txn(ctx).If(ifLockedByItselfOp).Commit()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to grant lease for deletion lock")
+		return false, errors.Wrap(err, "failed to check deletion lock")
+	}
+	if txnResp.Succeeded {
+		return true, nil
 	}
 
-	deletions, err := b.ListDeletions(ctx, batchLimit)
+	leaseResp, err := b.clientWrapper.GrantLease(ctx, int64(lockExp.Seconds()))
 	if err != nil {
-		b.clientWrapper.RevokeLease(ctx, leaseResp.ID)
-		return nil, err
+		return false, errors.Wrap(err, "failed to grant lease for deletion lock")
 	}
 
-	if len(deletions) == 0 {
-		b.clientWrapper.RevokeLease(ctx, leaseResp.ID)
-		return &types.DeletionBatch{ObjectKeys: []sdkmeta.ObjectKey{}, LeaseID: leaseResp.ID}, nil
+	ifUnlockedOp := clientv3.Compare(clientv3.Version(lockDbKey), "=", 0)
+	leaseOp := clientv3.OpPut(lockDbKey, lockKey, clientv3.WithLease(leaseResp.ID))
+	txnResp, err = b.clientWrapper.Client().Txn(ctx).If(ifUnlockedOp).Then(leaseOp).Commit()
+	if err != nil {
+		b.revokeLease(ctx, leaseResp.ID)
+		return false, errors.Wrap(err, "failed to acquire deletion lock")
+	}
+	if !txnResp.Succeeded {
+		b.revokeLease(ctx, leaseResp.ID)
+		return false, nil
 	}
 
-	var objectKeys []sdkmeta.ObjectKey
+	return true, nil
+}
 
-	for key := range deletions {
-		txn := b.clientWrapper.Client().Txn(ctx)
-		deletionLockDbKey := deletionLockDbKey(key)
+// ReleaseDeletion drops the deletion lock of the object if lockKey holds it, so another worker can take it over
+func (b *DeletionOpBuilder) ReleaseDeletion(ctx context.Context, key sdkmeta.ObjectKey, lockKey string) error {
+	lockDbKey := deletionLockDbKey(key)
+	ifLockedByItselfOp := clientv3.Compare(clientv3.Value(lockDbKey), "=", lockKey)
 
-		ifLockDoesNotExistOp := []clientv3.Cmp{clientv3.Compare(clientv3.Version(deletionLockDbKey), "=", 0)}
-		ifLockedByItselfOp := []clientv3.Cmp{clientv3.Compare(clientv3.Value(deletionLockDbKey), "=", lockKey)}
-		leaseOp := []clientv3.Op{clientv3.OpPut(deletionLockDbKey, lockKey, clientv3.WithLease(leaseResp.ID))}
+	txnResp, err := b.clientWrapper.Client().Txn(ctx).If(ifLockedByItselfOp).Then(b.BuildLockDropOps(key)...).Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to release deletion lock")
+	}
+	b.RevokeLockLease(ctx, key, txnResp)
+	return nil
+}
 
-		txnResp, err := txn.If(ifLockDoesNotExistOp...).Then(leaseOp...).Commit()
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to acquire deletion lock")
-		}
-		if txnResp.Succeeded {
-			objectKeys = append(objectKeys, key)
-			continue
-		}
+// BuildLockDropOps drops the deletion lock of the object, the lock is read first so the transaction
+// response tells RevokeLockLease its lease
+func (b *DeletionOpBuilder) BuildLockDropOps(key sdkmeta.ObjectKey) []clientv3.Op {
+	lockDbKey := deletionLockDbKey(key)
+	return []clientv3.Op{clientv3.OpGet(lockDbKey), clientv3.OpDelete(lockDbKey)}
+}
 
-		txnResp, err = txn.If(ifLockedByItselfOp...).Then(leaseOp...).Commit()
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to acquire deletion lock")
+// RevokeLockLease revokes the lease of the deletion lock dropped by a transaction built with BuildLockDropOps,
+// the lease would otherwise be kept by etcd until the lock expires
+func (b *DeletionOpBuilder) RevokeLockLease(ctx context.Context, key sdkmeta.ObjectKey, txnResp *clientv3.TxnResponse) {
+	if txnResp == nil || !txnResp.Succeeded {
+		return
+	}
+
+	lockDbKey := deletionLockDbKey(key)
+	for _, opResp := range txnResp.Responses {
+		for _, kv := range opResp.GetResponseRange().GetKvs() {
+			if string(kv.Key) == lockDbKey && kv.Lease != 0 {
+				b.revokeLease(ctx, clientv3.LeaseID(kv.Lease))
+			}
 		}
-		if txnResp.Succeeded {
-			objectKeys = append(objectKeys, key)
+	}
+}
+
+// revokeLease revokes a lease of a deletion lock, a lease that can't be revoked expires on its own
+func (b *DeletionOpBuilder) revokeLease(ctx context.Context, leaseID clientv3.LeaseID) {
+	if _, err := b.clientWrapper.RevokeLease(ctx, leaseID); err != nil {
+		b.logger.Warn("Failed to revoke deletion lock lease", zap.Int64("leaseID", int64(leaseID)), zap.Error(err))
+	}
+}
+
+// chunkOps packs the groups of operations into chunks of at most limit operations without splitting a group
+func chunkOps(groups [][]clientv3.Op, limit int) [][]clientv3.Op {
+	var chunks [][]clientv3.Op
+	var chunk []clientv3.Op
+	for _, group := range groups {
+		if len(chunk) > 0 && len(chunk)+len(group) > limit {
+			chunks = append(chunks, chunk)
+			chunk = nil
 		}
+		chunk = append(chunk, group...)
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// countOps returns the number of operations in the groups
+func countOps(groups [][]clientv3.Op) int {
+	count := 0
+	for _, group := range groups {
+		count += len(group)
 	}
+	return count
+}
 
-	return &types.DeletionBatch{ObjectKeys: objectKeys, LeaseID: leaseResp.ID}, nil
+// refersToOwner reports whether the reference points to the owner, an owner without a uid is matched by its key only
+func refersToOwner(ownerRef sdkmeta.OwnerReference, ownerKey sdkmeta.ObjectKey, ownerUID string) bool {
+	if ownerRef.ToObjectKey() != ownerKey {
+		return false
+	}
+	return ownerUID == "" || ownerRef.UID == ownerUID
 }
 
-func removeOwnerReference(ownerRefs []sdkmeta.OwnerReference, exceptID string) []sdkmeta.OwnerReference {
+// removeOwnerReference removes the references to the owner and keeps the ones to other owners
+func removeOwnerReference(ownerRefs []sdkmeta.OwnerReference, ownerKey sdkmeta.ObjectKey, ownerUID string) []sdkmeta.OwnerReference {
 	var newOwnerRefs []sdkmeta.OwnerReference
 	for _, ownerRef := range ownerRefs {
-		if ownerRef.UID == exceptID {
+		if refersToOwner(ownerRef, ownerKey, ownerUID) {
 			continue
 		}
 		newOwnerRefs = append(newOwnerRefs, ownerRef)
@@ -188,9 +353,10 @@ func removeOwnerReference(ownerRefs []sdkmeta.OwnerReference, exceptID string) [
 	return newOwnerRefs
 }
 
-func hasOtherBlockingReference(childOwnerRefs []sdkmeta.OwnerReference, exceptID string) bool {
+// hasOtherBlockingReference reports whether an owner other than the object blocks the deletion of the child
+func hasOtherBlockingReference(childOwnerRefs []sdkmeta.OwnerReference, owner *sdkmeta.Object) bool {
 	for _, ownerRef := range childOwnerRefs {
-		if ownerRef.UID == exceptID {
+		if refersToOwner(ownerRef, *owner.ObjectKey, owner.SystemMeta.UID) {
 			continue
 		}
 		if ownerRef.BlockOwnerDeletion {
@@ -201,6 +367,19 @@ func hasOtherBlockingReference(childOwnerRefs []sdkmeta.OwnerReference, exceptID
 	return false
 }
 
+// blocksOwnerDeletion reports whether the reference to the owner blocks its deletion
+func blocksOwnerDeletion(childOwnerRefs []sdkmeta.OwnerReference, owner *sdkmeta.Object) bool {
+	for _, ownerRef := range childOwnerRefs {
+		if refersToOwner(ownerRef, *owner.ObjectKey, owner.SystemMeta.UID) && ownerRef.BlockOwnerDeletion {
+			return true
+		}
+	}
+
+	return false
+}
+
+const deletionDbKeyPrefix = "/deletion/"
+
 func deletionDbKey(key sdkmeta.ObjectKey) string {
 	return fmt.Sprintf("/deletion%s", objectKeyToDbKey(key))
 }
@@ -213,3 +392,15 @@ func parseDeletionKey(key string) (sdkmeta.ObjectKey, error) {
 	key = strings.TrimPrefix(key, "/deletion")
 	return parseObjectKey(key)
 }
+
+func parseDeletionRecord(key string, value []byte) (types.DeletionRecord, error) {
+	objectKey, err := parseDeletionKey(key)
+	if err != nil {
+		return types.DeletionRecord{}, errors.Wrap(err, "failed to parse deletion key")
+	}
+	markedAt, err := time.Parse(time.RFC3339, string(value))
+	if err != nil {
+		return types.DeletionRecord{}, errors.Wrap(err, "failed to parse deletion record")
+	}
+	return types.DeletionRecord{ObjectKey: objectKey, Time: markedAt}, nil
+}
@@ -24,3 +24,45 @@ func IsNotFoundError(err error) bool {
 	_, ok := err.(*NotFoundError)
 	return ok
 }
+
+// ConflictError represents when a resource was changed since the version the write was based on
+type ConflictError struct {
+	Key string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("resource %s was modified concurrently", e.Key)
+}
+
+func NewConflictError(key string) *ConflictError {
+	return &ConflictError{
+		Key: key,
+	}
+}
+
+// IsConflictError checks if an error is a ConflictError
+func IsConflictError(err error) bool {
+	_, ok := err.(*ConflictError)
+	return ok
+}
+
+// ExpiredError represents when a revision a read was asked to continue from has been compacted
+type ExpiredError struct {
+	Revision int64
+}
+
+func (e *ExpiredError) Error() string {
+	return fmt.Sprintf("revision %d has been compacted", e.Revision)
+}
+
+func NewExpiredError(revision int64) *ExpiredError {
+	return &ExpiredError{
+		Revision: revision,
+	}
+}
+
+// IsExpiredError checks if an error is an ExpiredError
+func IsExpiredError(err error) bool {
+	_, ok := err.(*ExpiredError)
+	return ok
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	internalerrors "github.com/tsamsiyu/themelio/api/internal/errors"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	sdkfields "github.com/tsamsiyu/themelio/sdk/pkg/fields"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+	sdkschema "github.com/tsamsiyu/themelio/sdk/pkg/types/schema"
+)
+
+// FieldIndexOperations maintains indexes of the spec and status fields a schema marks with x-themelio-index
+type FieldIndexOperations struct {
+	clientWrapper types.ClientWrapper
+	logger        *zap.Logger
+}
+
+func NewFieldIndexOperations(clientWrapper types.ClientWrapper, logger *zap.Logger) *FieldIndexOperations {
+	return &FieldIndexOperations{clientWrapper: clientWrapper, logger: logger}
+}
+
+// IndexedFields returns the fields the schema of the type marks indexed, none when the type has no schema
+func (o *FieldIndexOperations) IndexedFields(ctx context.Context, objType *sdkmeta.ObjectType) ([]string, error) {
+	kv, err := o.clientWrapper.Get(ctx, schemaDbKey(objType.Group, objType.Kind))
+	if err != nil {
+		if IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var schema sdkschema.ObjectSchema
+	if err := json.Unmarshal(kv.Value, &schema); err != nil {
+		return nil, internalerrors.NewMarshalingError("failed to unmarshal ObjectSchema")
+	}
+
+	version, ok := schema.Version(objType.Version)
+	if !ok {
+		return nil, nil
+	}
+	return version.IndexedFields(), nil
+}
+
+// BuildFieldIndexUpdateOps moves the index keys of the object to its new values
+// Keys of set values are put on every write, so objects written before a field got indexed are caught up
+func (o *FieldIndexOperations) BuildFieldIndexUpdateOps(
+	objKey sdkmeta.ObjectKey,
+	fields []string,
+	oldObj *sdkmeta.Object,
+	newObj *sdkmeta.Object,
+) []clientv3.Op {
+	var ops []clientv3.Op
+
+	now := time.Now().Format(time.RFC3339)
+	for _, field := range fields {
+		oldValue, oldSet := sdkfields.ObjectValue(oldObj, field)
+		newValue, newSet := sdkfields.ObjectValue(newObj, field)
+
+		if oldSet && (!newSet || oldValue != newValue) {
+			ops = append(ops, clientv3.OpDelete(buildFieldIndexDbKey(objKey, field, oldValue)))
+		}
+		if newSet {
+			ops = append(ops, clientv3.OpPut(buildFieldIndexDbKey(objKey, field, newValue), now))
+		}
+	}
+
+	return ops
+}
+
+func (o *FieldIndexOperations) BuildFieldIndexCleanupOps(objKey sdkmeta.ObjectKey, fields []string, obj *sdkmeta.Object) []clientv3.Op {
+	var ops []clientv3.Op
+
+	for _, field := range fields {
+		if value, ok := sdkfields.ObjectValue(obj, field); ok {
+			ops = append(ops, clientv3.OpDelete(buildFieldIndexDbKey(objKey, field, value)))
+		}
+	}
+
+	return ops
+}
+
+// QueryObjectKeys returns the keys of objects of the type whose field equals the value of the requirement
+func (o *FieldIndexOperations) QueryObjectKeys(
+	ctx context.Context,
+	objType *sdkmeta.ObjectType,
+	requirement sdkfields.Requirement,
+	revision int64,
+) ([]sdkmeta.ObjectKey, int64, error) {
+	indexPrefix := fieldIndexDbPrefix(objType, requirement.Field)
+	valuePrefix := indexPrefix + url.PathEscape(requirement.Value) + "/"
+	return queryIndexedObjectKeys(ctx, o.clientWrapper, objType, indexPrefix, []string{valuePrefix}, revision)
+}
+
+// IndexableFieldRequirement returns the first requirement the indexes of the fields can answer
+// Only set values are indexed, so a requirement on the empty value needs a scan
+func IndexableFieldRequirement(selector sdkfields.Selector, indexedFields []string) (sdkfields.Requirement, bool) {
+	for _, requirement := range selector {
+		if requirement.Operator != sdkfields.OperatorEquals || requirement.Value == "" {
+			continue
+		}
+		for _, field := range indexedFields {
+			if field == requirement.Field {
+				return requirement, true
+			}
+		}
+	}
+	return sdkfields.Requirement{}, false
+}
+
+func mayUseFieldIndex(selector sdkfields.Selector) bool {
+	for _, requirement := range selector {
+		if requirement.Operator == sdkfields.OperatorEquals && requirement.Value != "" && sdkfields.IsSpecOrStatusField(requirement.Field) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildFieldIndexDbKey(objKey sdkmeta.ObjectKey, field string, value string) string {
+	return buildIndexDbKey(fieldIndexDbPrefix(&objKey.ObjectType, field), objKey, value)
+}
+
+func fieldIndexDbPrefix(objType *sdkmeta.ObjectType, field string) string {
+	return indexDbPrefix("field", objType, field)
+}
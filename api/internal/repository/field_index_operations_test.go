@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/lib"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+	sdkfields "github.com/tsamsiyu/themelio/sdk/pkg/fields"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+const testIndexedSchema = `{
+	"group": "example.com",
+	"kind": "TestResource",
+	"scope": "Namespaced",
+	"versions": [{
+		"name": "v1",
+		"schema": {
+			"type": "object",
+			"properties": {
+				"spec": {"type": "object", "properties": {"region": {"type": "string", "x-themelio-index": true}}}
+			}
+		}
+	}]
+}`
+
+func TestFieldIndexOperations_IndexedFields(t *testing.T) {
+	mockClient := mocks.NewMockClientWrapper(t)
+	fieldIndexOps := NewFieldIndexOperations(mockClient, zap.NewNop())
+	ctx := context.Background()
+
+	mockClient.EXPECT().Get(ctx, "/schema/example.com/TestResource").Return(&types.KeyValue{Value: []byte(testIndexedSchema)}, nil)
+
+	fields, err := fieldIndexOps.IndexedFields(ctx, &sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"spec.region"}, fields)
+}
+
+func TestFieldIndexOperations_BuildFieldIndexUpdateOps(t *testing.T) {
+	fieldIndexOps := NewFieldIndexOperations(nil, zap.NewNop())
+
+	objKey := sdkmeta.ObjectKey{
+		ObjectType: sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: "default"},
+		Name:       "test-resource",
+	}
+	oldObj := &sdkmeta.Object{ObjectKey: &objKey, Spec: map[string]interface{}{"region": "eu-west-1", "zone": "a"}}
+	newObj := &sdkmeta.Object{ObjectKey: &objKey, Spec: map[string]interface{}{"region": "us-east-1"}}
+
+	ops := fieldIndexOps.BuildFieldIndexUpdateOps(objKey, []string{"spec.region", "spec.zone"}, oldObj, newObj)
+
+	// The old region and the removed zone are deleted, the new region is put
+	assert.Len(t, ops, 3)
+	assert.True(t, ops[0].IsDelete())
+	assert.Equal(t, "/index/field/example.com/v1/TestResource/spec.region/eu-west-1/default/test-resource", string(ops[0].KeyBytes()))
+	assert.True(t, ops[1].IsPut())
+	assert.Equal(t, "/index/field/example.com/v1/TestResource/spec.region/us-east-1/default/test-resource", string(ops[1].KeyBytes()))
+	assert.True(t, ops[2].IsDelete())
+	assert.Equal(t, "/index/field/example.com/v1/TestResource/spec.zone/a/default/test-resource", string(ops[2].KeyBytes()))
+}
+
+func TestResourceRepository_List_FieldIndex(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+
+	ctx := context.Background()
+	objType := &sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource"}
+	newKey := func(namespace string, name string) sdkmeta.ObjectKey {
+		return sdkmeta.ObjectKey{ObjectType: sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: namespace}, Name: name}
+	}
+	newObject := func(key sdkmeta.ObjectKey, region string) *sdkmeta.Object {
+		return &sdkmeta.Object{ObjectKey: &key, ObjectMeta: &sdkmeta.ObjectMeta{}, Spec: map[string]interface{}{"region": region}}
+	}
+	selector, err := sdkfields.Parse("spec.region=eu-west-1")
+	assert.NoError(t, err)
+
+	// Given: The region index points to objects of two namespaces, one of them moved away at a later revision
+	indexPrefix := "/index/field/example.com/v1/TestResource/spec.region/eu-west-1/"
+	mockClient.EXPECT().Get(ctx, "/schema/example.com/TestResource").Return(&types.KeyValue{Value: []byte(testIndexedSchema)}, nil)
+	mockClient.EXPECT().List(ctx, types.Paging{Prefix: indexPrefix}).Return(&types.Batch{Revision: 7, KVs: []types.KeyValue{
+		{Key: indexPrefix + "ns2/b"},
+		{Key: indexPrefix + "ns1/a"},
+	}}, nil)
+	mockStore.EXPECT().GetMany(ctx, []sdkmeta.ObjectKey{newKey("ns1", "a"), newKey("ns2", "b")}, int64(7)).
+		Return(&types.ObjectBatch{Revision: 7, Objects: []*sdkmeta.Object{newObject(newKey("ns1", "a"), "eu-west-1"), newObject(newKey("ns2", "b"), "us-east-1")}}, nil)
+
+	// When: Listing across namespaces with the field selector
+	result, err := repo.List(ctx, objType, types.ListOptions{FieldSelector: selector})
+
+	// Then: Only the objects the index points to are read and checked again
+	assert.NoError(t, err)
+	assert.Len(t, result.Objects, 1)
+	assert.Equal(t, "a", result.Objects[0].ObjectKey.Name)
+	assert.Equal(t, int64(7), result.Revision)
+}
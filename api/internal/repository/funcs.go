@@ -25,6 +25,46 @@ func objectTypeToDbKey(objType *sdkmeta.ObjectType) string {
 	return fmt.Sprintf("/%s/%s/%s/%s", objType.Group, objType.Version, objType.Kind, objType.Namespace)
 }
 
+// systemDbKeyPrefixes are the prefixes of keys that share the key space with objects but don't hold one
+var systemDbKeyPrefixes = []string{"/schema/", "/index/", "/deletion/", "/deletion-lock/", "/election/", "/membership/"}
+
+// isWildcardType reports whether the type spans several kinds, an empty type selects the objects of every kind
+// and one with only the namespace set selects the objects of every kind in the namespace
+func isWildcardType(objType *sdkmeta.ObjectType) bool {
+	return objType.Kind == ""
+}
+
+// typePrefixDbKey returns the prefix of the keys of all objects of the type
+func typePrefixDbKey(objType *sdkmeta.ObjectType) string {
+	if isWildcardType(objType) {
+		return "/"
+	}
+	return objectTypeToDbKey(objType)
+}
+
+// parseResourceDbKey parses the key of an object, false is returned for keys of schemas, indexes and other records
+func parseResourceDbKey(key string) (sdkmeta.ObjectKey, bool) {
+	for _, prefix := range systemDbKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return sdkmeta.ObjectKey{}, false
+		}
+	}
+
+	objectKey, err := parseObjectKey(key)
+	if err != nil {
+		return sdkmeta.ObjectKey{}, false
+	}
+	return objectKey, true
+}
+
+// typeContainsKey reports whether the object belongs to the type, a key prefix alone matches longer kind and namespace names too
+func typeContainsKey(objType *sdkmeta.ObjectType, key sdkmeta.ObjectKey) bool {
+	if !isWildcardType(objType) && (key.Group != objType.Group || key.Version != objType.Version || key.Kind != objType.Kind) {
+		return false
+	}
+	return objType.Namespace == "" || key.Namespace == objType.Namespace
+}
+
 func schemaDbKey(group, kind string) string {
 	return fmt.Sprintf("/schema/%s/%s", group, kind)
 }
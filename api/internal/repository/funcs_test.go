@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+func TestParseResourceDbKey(t *testing.T) {
+	key, ok := parseResourceDbKey("/example.com/v1/Network/default/main")
+	assert.True(t, ok)
+	assert.Equal(t, sdkmeta.ObjectKey{
+		ObjectType: sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Network", Namespace: "default"},
+		Name:       "main",
+	}, key)
+
+	key, ok = parseResourceDbKey("/example.com/v1/Cluster/main")
+	assert.True(t, ok)
+	assert.Equal(t, "", key.Namespace)
+
+	for _, systemKey := range []string{
+		"/schema/example.com/Network",
+		"/index/label/example.com/v1/Network/app/web/%2Fexample.com%2Fv1%2FNetwork%2Fdefault%2Fmain",
+		"/deletion/example.com/v1/Network/default/main",
+		"/deletion-lock//example.com/v1/Network/default/main",
+		"/election/gc-worker",
+	} {
+		_, ok := parseResourceDbKey(systemKey)
+		assert.False(t, ok, systemKey)
+	}
+}
+
+func TestTypeContainsKey(t *testing.T) {
+	key := sdkmeta.ObjectKey{
+		ObjectType: sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Network", Namespace: "default"},
+		Name:       "main",
+	}
+
+	tests := []struct {
+		name    string
+		objType sdkmeta.ObjectType
+		want    bool
+	}{
+		{"same type", sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Network", Namespace: "default"}, true},
+		{"kind in all namespaces", sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Network"}, true},
+		{"all kinds of the namespace", sdkmeta.ObjectType{Namespace: "default"}, true},
+		{"everything", sdkmeta.ObjectType{}, true},
+		{"kind sharing the prefix", sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Net"}, false},
+		{"namespace sharing the prefix", sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Network", Namespace: "def"}, false},
+		{"other namespace", sdkmeta.ObjectType{Namespace: "prod"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, typeContainsKey(&tt.objType, key))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+// Index keys point from a label or field value to the objects having it:
+// /index/<label|field>/<group>/<version>/<kind>/<name>/<value>/<namespace>/<object name>
+// The namespace comes after the value, so one prefix finds the objects of all namespaces
+
+// indexDbPrefix returns the prefix of index keys of all values of an indexed label or field
+// Names and values are escaped in index keys as they may contain slashes
+func indexDbPrefix(index string, objType *sdkmeta.ObjectType, name string) string {
+	typeKey := objectTypeToDbKey(&sdkmeta.ObjectType{
+		Group:   objType.Group,
+		Version: objType.Version,
+		Kind:    objType.Kind,
+	})
+	return fmt.Sprintf("/index/%s%s/%s/", index, typeKey, url.PathEscape(name))
+}
+
+// parseIndexDbKey returns the db key of the object the index key under the prefix points to
+func parseIndexDbKey(prefix string, objType *sdkmeta.ObjectType, indexKey string) (string, bool) {
+	rest := strings.TrimPrefix(indexKey, prefix)
+	if rest == indexKey {
+		return "", false
+	}
+
+	// skip the value segment, what is left is either namespace/name or name
+	i := strings.Index(rest, "/")
+	if i < 0 {
+		return "", false
+	}
+
+	typeKey := objectTypeToDbKey(&sdkmeta.ObjectType{
+		Group:   objType.Group,
+		Version: objType.Version,
+		Kind:    objType.Kind,
+	})
+	return typeKey + rest[i:], true
+}
+
+// buildIndexDbKey returns the index key under the prefix pointing from the value to the object
+func buildIndexDbKey(prefix string, objKey sdkmeta.ObjectKey, value string) string {
+	prefix += url.PathEscape(value) + "/"
+	if objKey.Namespace == "" {
+		return prefix + objKey.Name
+	}
+	return fmt.Sprintf("%s%s/%s", prefix, objKey.Namespace, objKey.Name)
+}
+
+// queryIndexedObjectKeys lists the value prefixes of one index at a single revision and returns the keys of
+// objects of the type they point to sorted by their db keys, a zero revision reads the latest one
+func queryIndexedObjectKeys(
+	ctx context.Context,
+	clientWrapper types.ClientWrapper,
+	objType *sdkmeta.ObjectType,
+	indexPrefix string,
+	valuePrefixes []string,
+	revision int64,
+) ([]sdkmeta.ObjectKey, int64, error) {
+	typePrefix := objectTypeToDbKey(objType) + "/"
+	var dbKeys []string
+
+	for _, prefix := range valuePrefixes {
+		batch, err := clientWrapper.List(ctx, types.Paging{Prefix: prefix, Revision: revision})
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to query index")
+		}
+		if revision == 0 {
+			revision = batch.Revision
+		}
+
+		for _, kv := range batch.KVs {
+			dbKey, ok := parseIndexDbKey(indexPrefix, objType, kv.Key)
+			if ok && strings.HasPrefix(dbKey, typePrefix) {
+				dbKeys = append(dbKeys, dbKey)
+			}
+		}
+	}
+
+	sort.Strings(dbKeys)
+
+	keys := make([]sdkmeta.ObjectKey, 0, len(dbKeys))
+	for _, dbKey := range dbKeys {
+		key, err := parseObjectKey(dbKey)
+		if err != nil {
+			return nil, 0, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, revision, nil
+}
@@ -1,22 +1,25 @@
 package repository
 
 import (
-	"fmt"
+	"context"
+	"net/url"
 	"time"
 
 	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	sdklabels "github.com/tsamsiyu/themelio/sdk/pkg/labels"
 	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 )
 
 type LabelsOperations struct {
-	store  types.ResourceStore
-	logger *zap.Logger
+	store         types.ResourceStore
+	clientWrapper types.ClientWrapper
+	logger        *zap.Logger
 }
 
-func NewLabelsOperations(store types.ResourceStore, logger *zap.Logger) *LabelsOperations {
-	return &LabelsOperations{store: store, logger: logger}
+func NewLabelsOperations(store types.ResourceStore, clientWrapper types.ClientWrapper, logger *zap.Logger) *LabelsOperations {
+	return &LabelsOperations{store: store, clientWrapper: clientWrapper, logger: logger}
 }
 
 func (o *LabelsOperations) BuildLabelsUpdateOps(
@@ -57,9 +60,44 @@ func (o *LabelsOperations) BuildLabelsCleanupOps(objKey sdkmeta.ObjectKey, label
 	return ops
 }
 
+// QueryObjectKeys returns the keys of objects of the type matching the requirement, sorted by their db keys
+// Only requirements accepted by IsIndexableRequirement can be answered, a zero revision reads the latest one
+func (o *LabelsOperations) QueryObjectKeys(
+	ctx context.Context,
+	objType *sdkmeta.ObjectType,
+	requirement sdklabels.Requirement,
+	revision int64,
+) ([]sdkmeta.ObjectKey, int64, error) {
+	var prefixes []string
+	if requirement.Operator == sdklabels.OperatorExists {
+		prefixes = append(prefixes, labelIndexDbPrefix(objType, requirement.Key))
+	} else {
+		for _, value := range requirement.Values {
+			prefixes = append(prefixes, labelIndexDbPrefix(objType, requirement.Key)+url.PathEscape(value)+"/")
+		}
+	}
+
+	return queryIndexedObjectKeys(ctx, o.clientWrapper, objType, labelIndexDbPrefix(objType, requirement.Key), prefixes, revision)
+}
+
+// IsIndexableRequirement reports whether the label index can answer the requirement
+func IsIndexableRequirement(requirement sdklabels.Requirement) bool {
+	switch requirement.Operator {
+	case sdklabels.OperatorEquals, sdklabels.OperatorIn, sdklabels.OperatorExists:
+		return true
+	default:
+		return false
+	}
+}
+
+// buildLabelIndexDbKey puts the namespace after the label, so one prefix finds the objects of all namespaces
 func buildLabelIndexDbKey(objKey sdkmeta.ObjectKey, label string, labelValue string) string {
-	typeKey := objectTypeToDbKey(&objKey.ObjectType)
-	return fmt.Sprintf("/index/label%s/%s/%s/%s", typeKey, label, labelValue, objKey.Name)
+	return buildIndexDbKey(labelIndexDbPrefix(&objKey.ObjectType, label), objKey, labelValue)
+}
+
+// labelIndexDbPrefix returns the prefix of index keys of all values of the label
+func labelIndexDbPrefix(objType *sdkmeta.ObjectType, label string) string {
+	return indexDbPrefix("label", objType, label)
 }
 
 func CalculateLabelsDiff(oldLabels map[string]string, newLabels map[string]string) (map[string]string, map[string]string) {
@@ -13,7 +13,7 @@ import (
 func TestLabelsOperations_BuildLabelsUpdateOps_NewLabels(t *testing.T) {
 	logger := zap.NewNop()
 	mockStore := mocks.NewMockResourceStore(t)
-	labelsOpBuilder := NewLabelsOperations(mockStore, logger)
+	labelsOpBuilder := NewLabelsOperations(mockStore, nil, logger)
 
 	objKey := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
@@ -44,9 +44,9 @@ func TestLabelsOperations_BuildLabelsUpdateOps_NewLabels(t *testing.T) {
 
 	// Check that the keys are correctly formatted
 	expectedKeys := []string{
-		"/index/label/example.com/v1/TestResource/default/app/test-app/test-resource",
-		"/index/label/example.com/v1/TestResource/default/version/v1.0/test-resource",
-		"/index/label/example.com/v1/TestResource/default/env/dev/test-resource",
+		"/index/label/example.com/v1/TestResource/app/test-app/default/test-resource",
+		"/index/label/example.com/v1/TestResource/version/v1.0/default/test-resource",
+		"/index/label/example.com/v1/TestResource/env/dev/default/test-resource",
 	}
 
 	actualKeys := make([]string, len(ops))
@@ -62,7 +62,7 @@ func TestLabelsOperations_BuildLabelsUpdateOps_NewLabels(t *testing.T) {
 func TestLabelsOperations_BuildLabelsUpdateOps_RemovedLabels(t *testing.T) {
 	logger := zap.NewNop()
 	mockStore := mocks.NewMockResourceStore(t)
-	labelsOpBuilder := NewLabelsOperations(mockStore, logger)
+	labelsOpBuilder := NewLabelsOperations(mockStore, nil, logger)
 
 	objKey := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
@@ -93,9 +93,9 @@ func TestLabelsOperations_BuildLabelsUpdateOps_RemovedLabels(t *testing.T) {
 
 	// Check that the keys are correctly formatted
 	expectedKeys := []string{
-		"/index/label/example.com/v1/TestResource/default/app/test-app/test-resource",
-		"/index/label/example.com/v1/TestResource/default/version/v1.0/test-resource",
-		"/index/label/example.com/v1/TestResource/default/env/dev/test-resource",
+		"/index/label/example.com/v1/TestResource/app/test-app/default/test-resource",
+		"/index/label/example.com/v1/TestResource/version/v1.0/default/test-resource",
+		"/index/label/example.com/v1/TestResource/env/dev/default/test-resource",
 	}
 
 	actualKeys := make([]string, len(ops))
@@ -111,7 +111,7 @@ func TestLabelsOperations_BuildLabelsUpdateOps_RemovedLabels(t *testing.T) {
 func TestLabelsOperations_BuildLabelsUpdateOps_MixedChanges(t *testing.T) {
 	logger := zap.NewNop()
 	mockStore := mocks.NewMockResourceStore(t)
-	labelsOpBuilder := NewLabelsOperations(mockStore, logger)
+	labelsOpBuilder := NewLabelsOperations(mockStore, nil, logger)
 
 	objKey := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
@@ -161,19 +161,19 @@ func TestLabelsOperations_BuildLabelsUpdateOps_MixedChanges(t *testing.T) {
 	}
 
 	// Should delete the env label
-	assert.Contains(t, actualKeys, "/index/label/example.com/v1/TestResource/default/env/dev/test-resource")
+	assert.Contains(t, actualKeys, "/index/label/example.com/v1/TestResource/env/dev/default/test-resource")
 
 	// Should put the tier label
-	assert.Contains(t, actualKeys, "/index/label/example.com/v1/TestResource/default/tier/frontend/test-resource")
+	assert.Contains(t, actualKeys, "/index/label/example.com/v1/TestResource/tier/frontend/default/test-resource")
 
 	// Should put the version label (changed)
-	assert.Contains(t, actualKeys, "/index/label/example.com/v1/TestResource/default/version/v2.0/test-resource")
+	assert.Contains(t, actualKeys, "/index/label/example.com/v1/TestResource/version/v2.0/default/test-resource")
 }
 
 func TestLabelsOperations_BuildLabelsUpdateOps_NoChanges(t *testing.T) {
 	logger := zap.NewNop()
 	mockStore := mocks.NewMockResourceStore(t)
-	labelsOpBuilder := NewLabelsOperations(mockStore, logger)
+	labelsOpBuilder := NewLabelsOperations(mockStore, nil, logger)
 
 	objKey := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
@@ -200,7 +200,7 @@ func TestLabelsOperations_BuildLabelsUpdateOps_NoChanges(t *testing.T) {
 func TestLabelsOperations_BuildLabelsUpdateOps_NilLabels(t *testing.T) {
 	logger := zap.NewNop()
 	mockStore := mocks.NewMockResourceStore(t)
-	labelsOpBuilder := NewLabelsOperations(mockStore, logger)
+	labelsOpBuilder := NewLabelsOperations(mockStore, nil, logger)
 
 	objKey := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
@@ -301,7 +301,7 @@ func TestBuildLabelIndexDbKey(t *testing.T) {
 	}
 
 	key := buildLabelIndexDbKey(objKey, "app", "test-app")
-	expected := "/index/label/example.com/v1/TestResource/default/app/test-app/test-resource"
+	expected := "/index/label/example.com/v1/TestResource/app/test-app/default/test-resource"
 
 	assert.Equal(t, expected, key)
 }
@@ -309,7 +309,7 @@ func TestBuildLabelIndexDbKey(t *testing.T) {
 func TestLabelsOperations_BuildLabelsCleanupOps(t *testing.T) {
 	logger := zap.NewNop()
 	mockStore := mocks.NewMockResourceStore(t)
-	labelsOpBuilder := NewLabelsOperations(mockStore, logger)
+	labelsOpBuilder := NewLabelsOperations(mockStore, nil, logger)
 
 	objKey := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
@@ -339,9 +339,9 @@ func TestLabelsOperations_BuildLabelsCleanupOps(t *testing.T) {
 
 	// Check that the keys are correctly formatted
 	expectedKeys := []string{
-		"/index/label/example.com/v1/TestResource/default/app/test-app/test-resource",
-		"/index/label/example.com/v1/TestResource/default/version/v1.0/test-resource",
-		"/index/label/example.com/v1/TestResource/default/env/dev/test-resource",
+		"/index/label/example.com/v1/TestResource/app/test-app/default/test-resource",
+		"/index/label/example.com/v1/TestResource/version/v1.0/default/test-resource",
+		"/index/label/example.com/v1/TestResource/env/dev/default/test-resource",
 	}
 
 	actualKeys := make([]string, len(ops))
@@ -357,7 +357,7 @@ func TestLabelsOperations_BuildLabelsCleanupOps(t *testing.T) {
 func TestLabelsOperations_BuildLabelsCleanupOps_NilLabels(t *testing.T) {
 	logger := zap.NewNop()
 	mockStore := mocks.NewMockResourceStore(t)
-	labelsOpBuilder := NewLabelsOperations(mockStore, logger)
+	labelsOpBuilder := NewLabelsOperations(mockStore, nil, logger)
 
 	objKey := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
@@ -378,7 +378,7 @@ func TestLabelsOperations_BuildLabelsCleanupOps_NilLabels(t *testing.T) {
 func TestLabelsOperations_BuildLabelsCleanupOps_EmptyLabels(t *testing.T) {
 	logger := zap.NewNop()
 	mockStore := mocks.NewMockResourceStore(t)
-	labelsOpBuilder := NewLabelsOperations(mockStore, logger)
+	labelsOpBuilder := NewLabelsOperations(mockStore, nil, logger)
 
 	objKey := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
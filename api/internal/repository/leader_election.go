@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/lib"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+)
+
+// leaderElection holds leadership by owning the election key with a lease that is kept alive while the process runs
+type leaderElection struct {
+	clientWrapper types.ClientWrapper
+	logger        *zap.Logger
+	key           string
+	identity      string
+	ttl           int64
+
+	mu              sync.Mutex
+	leaseID         clientv3.LeaseID
+	cancelKeepAlive context.CancelFunc
+	done            chan struct{}
+}
+
+func NewLeaderElection(
+	logger *zap.Logger,
+	clientWrapper types.ClientWrapper,
+	name string,
+	ttl time.Duration,
+) types.LeaderElection {
+	done := make(chan struct{})
+	close(done)
+
+	return &leaderElection{
+		clientWrapper: clientWrapper,
+		logger:        logger,
+		key:           electionDbKey(name),
+		identity:      lib.NewIdentity(),
+		ttl:           int64(ttl.Seconds()),
+		done:          done,
+	}
+}
+
+func (e *leaderElection) Identity() string {
+	return e.identity
+}
+
+func (e *leaderElection) Done() <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.done
+}
+
+func (e *leaderElection) Campaign(ctx context.Context) error {
+	leaseResp, err := e.clientWrapper.GrantLease(ctx, e.ttl)
+	if err != nil {
+		return errors.Wrap(err, "failed to grant election lease")
+	}
+
+	keepAliveCtx, cancelKeepAlive := context.WithCancel(context.Background())
+	keepAlive, err := e.clientWrapper.KeepAliveLease(keepAliveCtx, leaseResp.ID)
+	if err != nil {
+		cancelKeepAlive()
+		e.revokeLease(leaseResp.ID)
+		return errors.Wrap(err, "failed to keep election lease alive")
+	}
+
+	for {
+		acquired, revision, err := e.tryAcquire(ctx, leaseResp.ID)
+		if err != nil {
+			cancelKeepAlive()
+			e.revokeLease(leaseResp.ID)
+			return err
+		}
+
+		if acquired {
+			e.becomeLeader(leaseResp.ID, keepAlive, cancelKeepAlive)
+			return nil
+		}
+
+		if err := e.waitForVacancy(ctx, revision); err != nil {
+			cancelKeepAlive()
+			e.revokeLease(leaseResp.ID)
+			return err
+		}
+	}
+}
+
+func (e *leaderElection) Resign(ctx context.Context) error {
+	e.mu.Lock()
+	leaseID := e.leaseID
+	cancelKeepAlive := e.cancelKeepAlive
+	e.leaseID = 0
+	e.cancelKeepAlive = nil
+	e.mu.Unlock()
+
+	if leaseID == 0 {
+		return nil
+	}
+
+	defer cancelKeepAlive()
+
+	ifLeaderOp := clientv3.Compare(clientv3.LeaseValue(e.key), "=", leaseID)
+	_, err := e.clientWrapper.Client().Txn(ctx).If(ifLeaderOp).Then(clientv3.OpDelete(e.key)).Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to delete election key")
+	}
+
+	if _, err := e.clientWrapper.RevokeLease(ctx, leaseID); err != nil {
+		return err
+	}
+
+	e.logger.Info("Resigned from leadership",
+		zap.String("election", e.key),
+		zap.String("identity", e.identity))
+
+	return nil
+}
+
+func (e *leaderElection) Leader(ctx context.Context) (string, error) {
+	kv, err := e.clientWrapper.Get(ctx, e.key)
+	if err != nil {
+		if IsNotFoundError(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(kv.Value), nil
+}
+
+func (e *leaderElection) Observe(ctx context.Context) (<-chan string, error) {
+	leader := ""
+	revision := int64(0)
+
+	kv, err := e.clientWrapper.Get(ctx, e.key)
+	if err != nil && !IsNotFoundError(err) {
+		return nil, err
+	}
+	if kv != nil {
+		leader = string(kv.Value)
+		revision = kv.ModRevision + 1
+	}
+
+	watchChan, err := e.clientWrapper.Watch(ctx, e.key, revision)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to watch election key")
+	}
+
+	leaderChan := make(chan string, 1)
+	leaderChan <- leader
+
+	go func() {
+		defer close(leaderChan)
+
+		for watchResp := range watchChan {
+			for _, ev := range watchResp.Events {
+				if string(ev.Kv.Key) != e.key {
+					continue
+				}
+
+				next := ""
+				if ev.Type == clientv3.EventTypePut {
+					next = string(ev.Kv.Value)
+				}
+				if next == leader {
+					continue
+				}
+				leader = next
+
+				select {
+				case leaderChan <- leader:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return leaderChan, nil
+}
+
+// tryAcquire creates the election key if it does not exist, the returned revision is the one the check was made at
+func (e *leaderElection) tryAcquire(ctx context.Context, leaseID clientv3.LeaseID) (bool, int64, error) {
+	ifVacantOp := clientv3.Compare(clientv3.CreateRevision(e.key), "=", 0)
+	putOp := clientv3.OpPut(e.key, e.identity, clientv3.WithLease(leaseID))
+
+	txnResp, err := e.clientWrapper.Client().Txn(ctx).If(ifVacantOp).Then(putOp).Commit()
+	if err != nil {
+		return false, 0, errors.Wrap(err, "failed to acquire election key")
+	}
+
+	var revision int64
+	if txnResp.Header != nil {
+		revision = txnResp.Header.Revision
+	}
+
+	return txnResp.Succeeded, revision, nil
+}
+
+// waitForVacancy blocks until the election key is deleted after the revision
+// it also gives up after one lease ttl, so a missed event only delays the next attempt
+func (e *leaderElection) waitForVacancy(ctx context.Context, revision int64) error {
+	watchCtx, cancel := context.WithTimeout(ctx, time.Duration(e.ttl)*time.Second)
+	defer cancel()
+
+	if revision > 0 {
+		revision++
+	}
+
+	watchChan, err := e.clientWrapper.Watch(watchCtx, e.key, revision)
+	if err != nil {
+		return errors.Wrap(err, "failed to watch election key")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-watchCtx.Done():
+			return nil
+		case watchResp, ok := <-watchChan:
+			if !ok {
+				return nil
+			}
+			for _, ev := range watchResp.Events {
+				if ev.Type == clientv3.EventTypeDelete && string(ev.Kv.Key) == e.key {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+func (e *leaderElection) becomeLeader(
+	leaseID clientv3.LeaseID,
+	keepAlive <-chan *clientv3.LeaseKeepAliveResponse,
+	cancelKeepAlive context.CancelFunc,
+) {
+	done := make(chan struct{})
+
+	e.mu.Lock()
+	e.leaseID = leaseID
+	e.cancelKeepAlive = cancelKeepAlive
+	e.done = done
+	e.mu.Unlock()
+
+	e.logger.Info("Acquired leadership",
+		zap.String("election", e.key),
+		zap.String("identity", e.identity))
+
+	go func() {
+		for range keepAlive {
+		}
+
+		e.mu.Lock()
+		if e.leaseID == leaseID {
+			e.leaseID = 0
+			e.cancelKeepAlive = nil
+		}
+		e.mu.Unlock()
+
+		cancelKeepAlive()
+		close(done)
+
+		e.logger.Info("Leadership lost",
+			zap.String("election", e.key),
+			zap.String("identity", e.identity))
+	}()
+}
+
+func (e *leaderElection) revokeLease(leaseID clientv3.LeaseID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := e.clientWrapper.RevokeLease(ctx, leaseID); err != nil {
+		e.logger.Warn("Failed to revoke election lease",
+			zap.String("election", e.key),
+			zap.Error(err))
+	}
+}
+
+func electionDbKey(name string) string {
+	return fmt.Sprintf("/election/%s", name)
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+)
+
+func TestLeaderElection_CampaignAndLoseLeadership(t *testing.T) {
+	logger := zap.NewNop()
+	mockClient := mocks.NewMockClientWrapper(t)
+	mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+	mockTxn := mocks.NewMockTxn(t)
+
+	ctx := context.Background()
+	leaseID := clientv3.LeaseID(42)
+	keepAlive := make(chan *clientv3.LeaseKeepAliveResponse)
+
+	election := NewLeaderElection(logger, mockClient, "gc-worker", 10*time.Second)
+
+	// Given: the election key is vacant
+	mockClient.EXPECT().GrantLease(ctx, int64(10)).Return(&clientv3.LeaseGrantResponse{ID: leaseID}, nil)
+	mockClient.EXPECT().KeepAliveLease(mock.Anything, leaseID).Return((<-chan *clientv3.LeaseKeepAliveResponse)(keepAlive), nil)
+	mockClient.EXPECT().Client().Return(mockEtcdClient)
+	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
+
+	// When: campaigning
+	err := election.Campaign(ctx)
+
+	// Then: leadership is acquired
+	assert.NoError(t, err)
+	select {
+	case <-election.Done():
+		t.Fatal("Done() closed while leading")
+	default:
+	}
+
+	// When: the lease can no longer be kept alive
+	close(keepAlive)
+
+	// Then: leadership is reported as lost
+	select {
+	case <-election.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done() not closed after keep alive stopped")
+	}
+}
+
+func TestLeaderElection_CampaignWaitsForVacancy(t *testing.T) {
+	logger := zap.NewNop()
+	mockClient := mocks.NewMockClientWrapper(t)
+	mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+	mockTxn := mocks.NewMockTxn(t)
+
+	ctx := context.Background()
+	leaseID := clientv3.LeaseID(42)
+	keepAlive := make(chan *clientv3.LeaseKeepAliveResponse)
+	watchChan := make(chan clientv3.WatchResponse, 1)
+
+	election := NewLeaderElection(logger, mockClient, "gc-worker", 10*time.Second)
+
+	// Given: the key is held by another candidate and is deleted afterwards
+	mockClient.EXPECT().GrantLease(ctx, int64(10)).Return(&clientv3.LeaseGrantResponse{ID: leaseID}, nil)
+	mockClient.EXPECT().KeepAliveLease(mock.Anything, leaseID).Return((<-chan *clientv3.LeaseKeepAliveResponse)(keepAlive), nil)
+	mockClient.EXPECT().Client().Return(mockEtcdClient)
+	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{
+		Succeeded: false,
+		Header:    &etcdserverpb.ResponseHeader{Revision: 7},
+	}, nil).Once()
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil).Once()
+	mockClient.EXPECT().Watch(mock.Anything, "/election/gc-worker", int64(8)).Return((<-chan clientv3.WatchResponse)(watchChan), nil)
+
+	watchChan <- clientv3.WatchResponse{Events: []*clientv3.Event{
+		{Type: clientv3.EventTypeDelete, Kv: &mvccpb.KeyValue{Key: []byte("/election/gc-worker")}},
+	}}
+
+	// When: campaigning
+	err := election.Campaign(ctx)
+
+	// Then: leadership is acquired after the key was released
+	assert.NoError(t, err)
+	close(keepAlive)
+}
+
+func TestLeaderElection_Leader(t *testing.T) {
+	logger := zap.NewNop()
+	mockClient := mocks.NewMockClientWrapper(t)
+	ctx := context.Background()
+
+	election := NewLeaderElection(logger, mockClient, "gc-worker", 10*time.Second)
+
+	mockClient.EXPECT().Get(ctx, "/election/gc-worker").Return(&types.KeyValue{Value: []byte("host-abc")}, nil).Once()
+	leader, err := election.Leader(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "host-abc", leader)
+
+	mockClient.EXPECT().Get(ctx, "/election/gc-worker").Return(nil, NewNotFoundError("/election/gc-worker")).Once()
+	leader, err = election.Leader(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "", leader)
+}
+
+func TestLeaderElection_ResignWithoutLeadership(t *testing.T) {
+	mockClient := mocks.NewMockClientWrapper(t)
+	election := NewLeaderElection(zap.NewNop(), mockClient, "gc-worker", 10*time.Second)
+
+	assert.NoError(t, election.Resign(context.Background()))
+	assert.NotEmpty(t, election.Identity())
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/lib"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+)
+
+// membership registers the process under a key of the group with a lease that is kept alive while the process runs
+type membership struct {
+	clientWrapper types.ClientWrapper
+	logger        *zap.Logger
+	prefix        string
+	identity      string
+	ttl           int64
+
+	mu              sync.Mutex
+	leaseID         clientv3.LeaseID
+	cancelKeepAlive context.CancelFunc
+	done            chan struct{}
+}
+
+func NewMembership(
+	logger *zap.Logger,
+	clientWrapper types.ClientWrapper,
+	group string,
+	ttl time.Duration,
+) types.Membership {
+	done := make(chan struct{})
+	close(done)
+
+	return &membership{
+		clientWrapper: clientWrapper,
+		logger:        logger,
+		prefix:        membershipDbKeyPrefix(group),
+		identity:      lib.NewIdentity(),
+		ttl:           int64(ttl.Seconds()),
+		done:          done,
+	}
+}
+
+func (m *membership) Identity() string {
+	return m.identity
+}
+
+func (m *membership) Done() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.done
+}
+
+func (m *membership) Join(ctx context.Context) error {
+	leaseResp, err := m.clientWrapper.GrantLease(ctx, m.ttl)
+	if err != nil {
+		return errors.Wrap(err, "failed to grant membership lease")
+	}
+
+	keepAliveCtx, cancelKeepAlive := context.WithCancel(context.Background())
+	keepAlive, err := m.clientWrapper.KeepAliveLease(keepAliveCtx, leaseResp.ID)
+	if err != nil {
+		cancelKeepAlive()
+		m.revokeLease(leaseResp.ID)
+		return errors.Wrap(err, "failed to keep membership lease alive")
+	}
+
+	putOp := clientv3.OpPut(m.prefix+m.identity, m.identity, clientv3.WithLease(leaseResp.ID))
+	if _, err := m.clientWrapper.Client().Txn(ctx).Then(putOp).Commit(); err != nil {
+		cancelKeepAlive()
+		m.revokeLease(leaseResp.ID)
+		return errors.Wrap(err, "failed to register member")
+	}
+
+	done := make(chan struct{})
+
+	m.mu.Lock()
+	m.leaseID = leaseResp.ID
+	m.cancelKeepAlive = cancelKeepAlive
+	m.done = done
+	m.mu.Unlock()
+
+	m.logger.Info("Joined group",
+		zap.String("group", m.prefix),
+		zap.String("identity", m.identity))
+
+	go func() {
+		for range keepAlive {
+		}
+
+		m.mu.Lock()
+		if m.leaseID == leaseResp.ID {
+			m.leaseID = 0
+			m.cancelKeepAlive = nil
+		}
+		m.mu.Unlock()
+
+		cancelKeepAlive()
+		close(done)
+
+		m.logger.Info("Membership lost",
+			zap.String("group", m.prefix),
+			zap.String("identity", m.identity))
+	}()
+
+	return nil
+}
+
+func (m *membership) Leave(ctx context.Context) error {
+	m.mu.Lock()
+	leaseID := m.leaseID
+	cancelKeepAlive := m.cancelKeepAlive
+	m.leaseID = 0
+	m.cancelKeepAlive = nil
+	m.mu.Unlock()
+
+	if leaseID == 0 {
+		return nil
+	}
+
+	defer cancelKeepAlive()
+
+	// revoking the lease deletes the member key with it
+	if _, err := m.clientWrapper.RevokeLease(ctx, leaseID); err != nil {
+		return err
+	}
+
+	m.logger.Info("Left group",
+		zap.String("group", m.prefix),
+		zap.String("identity", m.identity))
+
+	return nil
+}
+
+// Observe lists the members and follows them with a watch, they are listed again when the watch breaks
+func (m *membership) Observe(ctx context.Context) (<-chan []string, error) {
+	members, revision, err := m.listMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	membersChan := make(chan []string, 1)
+	membersChan <- sortedMembers(members)
+
+	go func() {
+		defer close(membersChan)
+
+		send := func() bool {
+			select {
+			case membersChan <- sortedMembers(members):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for ctx.Err() == nil {
+			watchChan, err := m.clientWrapper.Watch(ctx, m.prefix, revision+1)
+			if err != nil {
+				m.logger.Warn("Failed to watch members", zap.String("group", m.prefix), zap.Error(err))
+				return
+			}
+
+			for watchResp := range watchChan {
+				if watchResp.Err() != nil {
+					m.logger.Warn("Member watch ended", zap.String("group", m.prefix), zap.Error(watchResp.Err()))
+					break
+				}
+
+				changed := false
+				for _, ev := range watchResp.Events {
+					identity := strings.TrimPrefix(string(ev.Kv.Key), m.prefix)
+					_, known := members[identity]
+					if ev.Type == clientv3.EventTypeDelete && known {
+						delete(members, identity)
+						changed = true
+					} else if ev.Type == clientv3.EventTypePut && !known {
+						members[identity] = struct{}{}
+						changed = true
+					}
+				}
+				revision = watchResp.Header.Revision
+
+				if changed && !send() {
+					return
+				}
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			// the watch broke, possibly after a compaction, so the members are listed from scratch
+			members, revision, err = m.listMembers(ctx)
+			if err != nil {
+				m.logger.Warn("Failed to list members", zap.String("group", m.prefix), zap.Error(err))
+				return
+			}
+			if !send() {
+				return
+			}
+		}
+	}()
+
+	return membersChan, nil
+}
+
+func (m *membership) listMembers(ctx context.Context) (map[string]struct{}, int64, error) {
+	batch, err := m.clientWrapper.List(ctx, types.Paging{Prefix: m.prefix})
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to list members")
+	}
+
+	members := make(map[string]struct{}, len(batch.KVs))
+	for _, kv := range batch.KVs {
+		members[strings.TrimPrefix(kv.Key, m.prefix)] = struct{}{}
+	}
+	return members, batch.Revision, nil
+}
+
+func (m *membership) revokeLease(leaseID clientv3.LeaseID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := m.clientWrapper.RevokeLease(ctx, leaseID); err != nil {
+		m.logger.Warn("Failed to revoke membership lease",
+			zap.String("group", m.prefix),
+			zap.Error(err))
+	}
+}
+
+func sortedMembers(members map[string]struct{}) []string {
+	identities := make([]string, 0, len(members))
+	for identity := range members {
+		identities = append(identities, identity)
+	}
+	sort.Strings(identities)
+	return identities
+}
+
+func membershipDbKeyPrefix(group string) string {
+	return fmt.Sprintf("/membership/%s/", group)
+}
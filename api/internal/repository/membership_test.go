@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+)
+
+func TestMembership_JoinAndLoseMembership(t *testing.T) {
+	mockClient := mocks.NewMockClientWrapper(t)
+	mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+	mockTxn := mocks.NewMockTxn(t)
+
+	ctx := context.Background()
+	leaseID := clientv3.LeaseID(42)
+	keepAlive := make(chan *clientv3.LeaseKeepAliveResponse)
+
+	membership := NewMembership(zap.NewNop(), mockClient, "gc-worker", 10*time.Second)
+
+	// Given: the member key can be written with a lease
+	mockClient.EXPECT().GrantLease(ctx, int64(10)).Return(&clientv3.LeaseGrantResponse{ID: leaseID}, nil)
+	mockClient.EXPECT().KeepAliveLease(mock.Anything, leaseID).Return((<-chan *clientv3.LeaseKeepAliveResponse)(keepAlive), nil)
+	mockClient.EXPECT().Client().Return(mockEtcdClient)
+	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
+
+	// When: joining
+	err := membership.Join(ctx)
+
+	// Then: membership is held
+	require.NoError(t, err)
+	select {
+	case <-membership.Done():
+		t.Fatal("Done() closed while a member")
+	default:
+	}
+
+	// When: the lease can no longer be kept alive
+	close(keepAlive)
+
+	// Then: membership is reported as lost
+	select {
+	case <-membership.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done() not closed after keep alive stopped")
+	}
+}
+
+func TestMembership_Observe(t *testing.T) {
+	mockClient := mocks.NewMockClientWrapper(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	membership := NewMembership(zap.NewNop(), mockClient, "gc-worker", 10*time.Second)
+	watchChan := make(chan clientv3.WatchResponse, 1)
+
+	// Given: two members and a third one joining while the other leaves
+	mockClient.EXPECT().List(ctx, types.Paging{Prefix: "/membership/gc-worker/"}).Return(&types.Batch{
+		Revision: 5,
+		KVs: []types.KeyValue{
+			{Key: "/membership/gc-worker/host-b"},
+			{Key: "/membership/gc-worker/host-a"},
+		},
+	}, nil)
+	mockClient.EXPECT().Watch(ctx, "/membership/gc-worker/", int64(6)).Return((<-chan clientv3.WatchResponse)(watchChan), nil)
+
+	// When: observing the members
+	members, err := membership.Observe(ctx)
+	require.NoError(t, err)
+
+	// Then: the current members come first, sorted
+	assert.Equal(t, []string{"host-a", "host-b"}, <-members)
+
+	watchChan <- clientv3.WatchResponse{
+		Header: etcdserverpb.ResponseHeader{Revision: 7},
+		Events: []*clientv3.Event{
+			{Type: clientv3.EventTypePut, Kv: &mvccpb.KeyValue{Key: []byte("/membership/gc-worker/host-c")}},
+			{Type: clientv3.EventTypeDelete, Kv: &mvccpb.KeyValue{Key: []byte("/membership/gc-worker/host-a")}},
+		},
+	}
+
+	// Then: the change is streamed once
+	select {
+	case next := <-members:
+		assert.Equal(t, []string{"host-b", "host-c"}, next)
+	case <-time.After(time.Second):
+		t.Fatal("members change not streamed")
+	}
+}
@@ -74,18 +74,47 @@ func (b *OwnerReferenceOpBuilder) GetChildrenKeys(ctx context.Context, parentKey
 		return nil, errors.Wrap(err, "failed to get owner references from etcd")
 	}
 
-	childKeys := make([]sdkmeta.ObjectKey, len(batch.KVs))
+	childKeys := make([]sdkmeta.ObjectKey, 0, len(batch.KVs))
 	for _, kv := range batch.KVs {
-		_, childKey, err := parseOwnerReferenceIndexDbKey(kv.Key)
+		indexParentKey, childKey, err := parseOwnerReferenceIndexDbKey(kv.Key)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to parse index db key")
 		}
+		// the prefix also matches parents whose name starts with the name of this one
+		if indexParentKey != parentKey {
+			continue
+		}
 		childKeys = append(childKeys, childKey)
 	}
 
 	return childKeys, nil
 }
 
+// ListOwnerReferences returns a page of the owner reference index after lastKey
+func (b *OwnerReferenceOpBuilder) ListOwnerReferences(ctx context.Context, lastKey string, limit int) (*types.OwnerReferenceBatch, error) {
+	batch, err := b.clientWrapper.List(ctx, types.Paging{Prefix: ownerReferenceIndexDbKeyPrefix, LastKey: lastKey, Limit: limit})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list owner references from etcd")
+	}
+
+	result := &types.OwnerReferenceBatch{
+		Entries: make([]types.OwnerReferenceEntry, 0, len(batch.KVs)),
+		More:    batch.More,
+	}
+	for _, kv := range batch.KVs {
+		result.LastKey = kv.Key
+
+		parentKey, childKey, err := parseOwnerReferenceIndexDbKey(kv.Key)
+		if err != nil {
+			b.logger.Warn("Skipping invalid owner reference index key", zap.String("key", kv.Key), zap.Error(err))
+			continue
+		}
+		result.Entries = append(result.Entries, types.OwnerReferenceEntry{Parent: parentKey, Child: childKey})
+	}
+
+	return result, nil
+}
+
 // QueryChildren queries all child resources for a given parent key
 func (b *OwnerReferenceOpBuilder) QueryChildren(ctx context.Context, parentKey sdkmeta.ObjectKey) ([]*sdkmeta.Object, error) {
 	childKeys, err := b.GetChildrenKeys(ctx, parentKey)
@@ -113,22 +142,29 @@ func (b *OwnerReferenceOpBuilder) QueryChildren(ctx context.Context, parentKey s
 	return children, nil
 }
 
+const ownerReferenceIndexDbKeyPrefix = "/index/owner-reference/"
+
 func buildOwnerReferenceIndexDbKeyPrefix(parentKey sdkmeta.ObjectKey) string {
-	return fmt.Sprintf("/index/owner-reference/%s", objectKeyToDbKey(parentKey))
+	return ownerReferenceIndexDbKeyPrefix + objectKeyToDbKey(parentKey)
 }
 
 func buildOwnerReferenceIndexDbKey(parentKey sdkmeta.ObjectKey, childKey sdkmeta.ObjectKey) string {
-	return fmt.Sprintf("/index/owner-reference/%s/%s", objectKeyToDbKey(parentKey), objectKeyToDbKey(childKey))
+	return fmt.Sprintf("%s%s/%s", ownerReferenceIndexDbKeyPrefix, objectKeyToDbKey(parentKey), objectKeyToDbKey(childKey))
 }
 
+// parseOwnerReferenceIndexDbKey splits the index key into the parent and child keys, both db keys start
+// with a slash so they are separated by a double one
 func parseOwnerReferenceIndexDbKey(dbKey string) (sdkmeta.ObjectKey, sdkmeta.ObjectKey, error) {
-	dbKey = strings.TrimPrefix(dbKey, "/index/owner-reference/")
-	parts := strings.Split(dbKey, "/")
-	parentKey, err := parseObjectKey(parts[1])
+	dbKey = strings.TrimPrefix(dbKey, ownerReferenceIndexDbKeyPrefix)
+	parts := strings.SplitN(dbKey, "//", 2)
+	if len(parts) != 2 {
+		return sdkmeta.ObjectKey{}, sdkmeta.ObjectKey{}, fmt.Errorf("invalid owner reference index key %q", dbKey)
+	}
+	parentKey, err := parseObjectKey(parts[0])
 	if err != nil {
 		return sdkmeta.ObjectKey{}, sdkmeta.ObjectKey{}, err
 	}
-	childKey, err := parseObjectKey(parts[0])
+	childKey, err := parseObjectKey(parts[1])
 	if err != nil {
 		return sdkmeta.ObjectKey{}, sdkmeta.ObjectKey{}, err
 	}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+const (
+	readCacheRelistDelay = time.Second
+)
+
+// ReadCache keeps the objects of kinds in memory, each kind is fed by a watch of it across all namespaces
+// started with the first read of the kind. It serves reads that accept any state at or after a revision,
+// the objects it returns are shared and must not be modified
+type ReadCache struct {
+	watchManager *WatchManager
+	logger       *zap.Logger
+	config       types.WatchConfig
+	kinds        map[string]*kindCache
+	mu           sync.Mutex
+}
+
+// kindCache holds the objects of one kind by their db keys
+type kindCache struct {
+	objects  map[string]*sdkmeta.Object
+	revision int64
+	synced   bool
+	// changed is closed and replaced whenever the revision moves, readers waiting for a revision select on it
+	changed chan struct{}
+	mu      sync.RWMutex
+}
+
+func NewReadCache(watchManager *WatchManager, logger *zap.Logger, config types.WatchConfig) *ReadCache {
+	return &ReadCache{
+		watchManager: watchManager,
+		logger:       logger,
+		config:       config,
+		kinds:        make(map[string]*kindCache),
+	}
+}
+
+// Get returns the object of the key as of a revision at or after minRevision, nil when it doesn't exist
+// False is returned when the cache hasn't seen the revision within the wait timeout
+func (c *ReadCache) Get(ctx context.Context, key sdkmeta.ObjectKey, minRevision int64) (*sdkmeta.Object, bool) {
+	kind := c.kind(&key.ObjectType)
+	if !c.waitFor(ctx, kind, minRevision) {
+		return nil, false
+	}
+
+	kind.mu.RLock()
+	defer kind.mu.RUnlock()
+	return kind.objects[objectKeyToDbKey(key)], true
+}
+
+// List returns the objects of the type in key order together with the revision they are current at
+// False is returned when the cache hasn't seen minRevision within the wait timeout
+func (c *ReadCache) List(ctx context.Context, objType *sdkmeta.ObjectType, minRevision int64) ([]*sdkmeta.Object, int64, bool) {
+	kind := c.kind(objType)
+	if !c.waitFor(ctx, kind, minRevision) {
+		return nil, 0, false
+	}
+
+	kind.mu.RLock()
+	defer kind.mu.RUnlock()
+
+	keys := make([]string, 0, len(kind.objects))
+	for dbKey, object := range kind.objects {
+		if typeContainsKey(objType, *object.ObjectKey) {
+			keys = append(keys, dbKey)
+		}
+	}
+	sort.Strings(keys)
+
+	objects := make([]*sdkmeta.Object, 0, len(keys))
+	for _, dbKey := range keys {
+		objects = append(objects, kind.objects[dbKey])
+	}
+	return objects, kind.revision, true
+}
+
+// waitFor waits for the kind to see the revision. The revision is global to etcd and often comes from a write
+// to another kind, which the watch of this one has no event of, so etcd is asked for the progress of the watch
+func (c *ReadCache) waitFor(ctx context.Context, kind *kindCache, revision int64) bool {
+	synced, seen := kind.status(revision)
+	if seen {
+		return true
+	}
+	if synced {
+		c.watchManager.requestProgress(ctx)
+	}
+	return kind.waitFor(ctx, revision, c.config.ReadCacheWaitTimeout)
+}
+
+// kind returns the cache of the kind of the type, it is started on first use and kept for the life of the process
+func (c *ReadCache) kind(objType *sdkmeta.ObjectType) *kindCache {
+	kindType := &sdkmeta.ObjectType{Group: objType.Group, Version: objType.Version, Kind: objType.Kind}
+	key := objectTypeToDbKey(kindType)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	kind, ok := c.kinds[key]
+	if !ok {
+		kind = &kindCache{
+			objects: make(map[string]*sdkmeta.Object),
+			changed: make(chan struct{}),
+		}
+		c.kinds[key] = kind
+		go c.run(context.Background(), kind, kindType)
+	}
+	return kind
+}
+
+func (c *ReadCache) run(ctx context.Context, kind *kindCache, kindType *sdkmeta.ObjectType) {
+	for {
+		err := c.listAndWatch(ctx, kind, kindType)
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.logger.Warn("Read cache watch ended, relisting",
+			zap.String("key", objectTypeToDbKey(kindType)),
+			zap.Error(err))
+		kind.unsync()
+
+		select {
+		case <-time.After(readCacheRelistDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// listAndWatch replaces the objects with a fresh list and applies the changes after it until the watch fails
+func (c *ReadCache) listAndWatch(ctx context.Context, kind *kindCache, kindType *sdkmeta.ObjectType) error {
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, err := c.watchManager.ListAndWatch(watchCtx, kindType)
+	if err != nil {
+		return err
+	}
+
+	initial := make(map[string]*sdkmeta.Object)
+	for event := range events {
+		switch event.Type {
+		case types.WatchEventTypeError:
+			return event.Error
+		case types.WatchEventTypeBookmark:
+			if event.InitialEventsEnd {
+				kind.replace(initial, event.Revision)
+				initial = nil
+			} else {
+				kind.apply(event)
+			}
+		default:
+			if initial != nil {
+				initial[objectKeyToDbKey(event.ObjectKey)] = event.Object
+			} else {
+				kind.apply(event)
+			}
+		}
+	}
+
+	return errors.New("watch channel closed")
+}
+
+func (k *kindCache) replace(objects map[string]*sdkmeta.Object, revision int64) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.objects = objects
+	k.synced = true
+	k.setRevision(revision)
+}
+
+func (k *kindCache) apply(event types.WatchEvent) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	switch event.Type {
+	case types.WatchEventTypeAdded, types.WatchEventTypeModified:
+		if event.Object != nil {
+			k.objects[objectKeyToDbKey(event.ObjectKey)] = event.Object
+		}
+	case types.WatchEventTypeDeleted:
+		delete(k.objects, objectKeyToDbKey(event.ObjectKey))
+	}
+
+	if event.Revision > k.revision {
+		k.setRevision(event.Revision)
+	}
+}
+
+// unsync sends reads to etcd until the next list
+func (k *kindCache) unsync() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.synced = false
+}
+
+// setRevision is called with the lock held
+func (k *kindCache) setRevision(revision int64) {
+	k.revision = revision
+	close(k.changed)
+	k.changed = make(chan struct{})
+}
+
+// status reports whether the kind is listed and whether it has seen the revision
+func (k *kindCache) status(revision int64) (bool, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.synced, k.synced && k.revision >= revision
+}
+
+// waitFor reports whether the cache has seen the revision, waiting for it up to the timeout
+func (k *kindCache) waitFor(ctx context.Context, revision int64, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		k.mu.RLock()
+		ready := k.synced && k.revision >= revision
+		changed := k.changed
+		k.mu.RUnlock()
+
+		if ready {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
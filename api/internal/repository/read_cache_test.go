@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/lib"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+func TestReadCache_GetWaitsForRevision(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	config := types.WatchConfig{HistorySize: 10, ReconcileBatchSize: 10, ReadCacheWaitTimeout: time.Second}
+	cache := NewReadCache(NewWatchManager(mockStore, zap.NewNop(), config, &lib.BackoffManager{}), zap.NewNop(), config)
+
+	// Given: The kind holds one object at revision 10 and is watched after it
+	event := testWatchEvent(types.WatchEventTypeAdded, 0)
+	etcdEvents := expectKindWatch(mockStore, []*sdkmeta.Object{event.Object}, 10)
+	mockStore.EXPECT().RequestProgress(mock.Anything).Return(nil).Maybe()
+
+	// When: Reading at a revision the cache has seen
+	object, ok := cache.Get(context.Background(), event.ObjectKey, 10)
+
+	// Then: The object comes from memory
+	assert.True(t, ok)
+	assert.Equal(t, event.Object, object)
+
+	// When: Reading at a revision that is yet to come
+	done := make(chan *sdkmeta.Object)
+	go func() {
+		object, _ := cache.Get(context.Background(), event.ObjectKey, 11)
+		done <- object
+	}()
+
+	// Then: The read waits for the change of that revision
+	modified := testWatchEvent(types.WatchEventTypeModified, 11)
+	(<-etcdEvents) <- modified
+	select {
+	case object = <-done:
+		assert.Same(t, modified.Object, object)
+	case <-time.After(time.Second):
+		t.Fatal("read didn't complete")
+	}
+}
+
+func TestReadCache_GetFollowsProgress(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	config := types.WatchConfig{HistorySize: 10, ReconcileBatchSize: 10, ReadCacheWaitTimeout: time.Second}
+	cache := NewReadCache(NewWatchManager(mockStore, zap.NewNop(), config, &lib.BackoffManager{}), zap.NewNop(), config)
+
+	// Given: The kind holds one object at revision 10
+	event := testWatchEvent(types.WatchEventTypeAdded, 0)
+	etcdEvents := expectKindWatch(mockStore, []*sdkmeta.Object{event.Object}, 10)
+	_, ok := cache.Get(context.Background(), event.ObjectKey, 10)
+	assert.True(t, ok)
+
+	// And: A write to another kind moved etcd to revision 15, etcd reports it as the progress of the watch when asked
+	eventChan := <-etcdEvents
+	mockStore.EXPECT().RequestProgress(mock.Anything).
+		Run(func(_ context.Context) {
+			eventChan <- newBookmarkEvent(15)
+		}).Return(nil).Once()
+
+	// When: Reading at that revision
+	start := time.Now()
+	object, ok := cache.Get(context.Background(), event.ObjectKey, 15)
+
+	// Then: The object comes from memory without waiting for the timeout
+	assert.True(t, ok)
+	assert.Equal(t, event.Object, object)
+	assert.Less(t, time.Since(start), config.ReadCacheWaitTimeout)
+}
+
+func TestReadCache_ListFiltersType(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	config := types.WatchConfig{HistorySize: 10, ReconcileBatchSize: 10, ReadCacheWaitTimeout: time.Second}
+	cache := NewReadCache(NewWatchManager(mockStore, zap.NewNop(), config, &lib.BackoffManager{}), zap.NewNop(), config)
+
+	// Given: Objects of the kind in two namespaces
+	kindType := sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource"}
+	first := testCachedObject(kindType, "default", "b")
+	second := testCachedObject(kindType, "default", "a")
+	other := testCachedObject(kindType, "other", "c")
+	expectKindWatch(mockStore, []*sdkmeta.Object{first, second, other}, 10)
+
+	// When: Listing one namespace
+	objType := kindType
+	objType.Namespace = "default"
+	objects, revision, ok := cache.List(context.Background(), &objType, 5)
+
+	// Then: Only its objects are returned in key order at the revision of the cache
+	assert.True(t, ok)
+	assert.Equal(t, int64(10), revision)
+	assert.Equal(t, []*sdkmeta.Object{second, first}, objects)
+}
+
+func TestResourceRepository_GetCached_FallsBackToStore(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	config := types.WatchConfig{HistorySize: 10, ReconcileBatchSize: 10, ReadCache: true, ReadCacheWaitTimeout: 10 * time.Millisecond}
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, config, &lib.BackoffManager{})
+
+	// Given: The cache is at revision 10
+	event := testWatchEvent(types.WatchEventTypeAdded, 0)
+	expectKindWatch(mockStore, []*sdkmeta.Object{event.Object}, 10)
+	mockStore.EXPECT().RequestProgress(mock.Anything).Return(nil).Maybe()
+	stored := &sdkmeta.Object{ObjectKey: &event.ObjectKey, SystemMeta: &sdkmeta.SystemMeta{ModRevision: 20}}
+	mockStore.EXPECT().Get(mock.Anything, event.ObjectKey).Return(stored, nil)
+
+	// When: Reading at a revision the cache doesn't reach within the wait timeout
+	object, err := repo.GetCached(context.Background(), event.ObjectKey, 20)
+
+	// Then: The object is read from etcd
+	assert.NoError(t, err)
+	assert.Same(t, stored, object)
+}
+
+// expectKindWatch sets the store up for the list and watch of the kind of the test objects
+// and returns the channel the changes after the list are sent on
+func expectKindWatch(mockStore *mocks.MockResourceStore, objects []*sdkmeta.Object, revision int64) chan chan<- types.WatchEvent {
+	kindType := &sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource"}
+
+	mockStore.EXPECT().List(mock.Anything, kindType, &types.Paging{Limit: 10}).
+		Return(&types.ObjectBatch{Revision: revision, Objects: objects}, nil).Once()
+	mockStore.EXPECT().List(mock.Anything, kindType, &types.Paging{Limit: 1, Revision: revision}).
+		Return(&types.ObjectBatch{Revision: revision}, nil).Once()
+
+	etcdEvents := make(chan chan<- types.WatchEvent, 1)
+	mockStore.EXPECT().Watch(mock.Anything, kindType, mock.Anything, revision+1).
+		Run(func(_ context.Context, _ *sdkmeta.ObjectType, eventChan chan<- types.WatchEvent, _ ...int64) {
+			etcdEvents <- eventChan
+		}).Return(nil).Maybe()
+	return etcdEvents
+}
+
+func testCachedObject(kindType sdkmeta.ObjectType, namespace string, name string) *sdkmeta.Object {
+	kindType.Namespace = namespace
+	return &sdkmeta.Object{
+		ObjectKey:  &sdkmeta.ObjectKey{ObjectType: kindType, Name: name},
+		SystemMeta: &sdkmeta.SystemMeta{},
+	}
+}
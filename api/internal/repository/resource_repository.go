@@ -1,26 +1,36 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 
+	internalerrors "github.com/tsamsiyu/themelio/api/internal/errors"
 	"github.com/tsamsiyu/themelio/api/internal/lib"
 	"github.com/tsamsiyu/themelio/api/internal/repository/types"
 	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
 )
 
+// removeFinalizerAttempts bounds the retries of a finalizer removal racing with other writes of the object
+const removeFinalizerAttempts = 5
+
 type resourceRepository struct {
 	store             types.ResourceStore
 	clientWrapper     types.ClientWrapper
 	ownerRefOpBuilder *OwnerReferenceOpBuilder
 	deletionOpBuilder *DeletionOpBuilder
 	labelsOpBuilder   *LabelsOperations
+	fieldIndexOps     *FieldIndexOperations
 	watchManager      *WatchManager
+	readCache         *ReadCache
 	logger            *zap.Logger
 }
 
@@ -33,25 +43,70 @@ func NewResourceRepository(
 ) types.ResourceRepository {
 	ownerRefOpBuilder := NewOwnerReferenceOpBuilder(store, clientWrapper, logger)
 	deletionOpBuilder := NewDeletionOpBuilder(store, clientWrapper, logger)
-	labelsOpBuilder := NewLabelsOperations(store, logger)
+	labelsOpBuilder := NewLabelsOperations(store, clientWrapper, logger)
+	fieldIndexOps := NewFieldIndexOperations(clientWrapper, logger)
 	watchManager := NewWatchManager(store, logger, watchConfig, backoffManager)
+
+	var readCache *ReadCache
+	if watchConfig.ReadCache {
+		readCache = NewReadCache(watchManager, logger, watchConfig)
+	}
+
 	return &resourceRepository{
 		store:             store,
 		clientWrapper:     clientWrapper,
 		ownerRefOpBuilder: ownerRefOpBuilder,
 		deletionOpBuilder: deletionOpBuilder,
 		labelsOpBuilder:   labelsOpBuilder,
+		fieldIndexOps:     fieldIndexOps,
 		watchManager:      watchManager,
+		readCache:         readCache,
 		logger:            logger,
 	}
 }
 
+// Replace writes the spec and metadata of the object, the stored status is kept as it is owned by ReplaceStatus
 func (r *resourceRepository) Replace(ctx context.Context, obj *sdkmeta.Object, optimisticLock bool) error {
 	oldObj, err := r.store.Get(ctx, *obj.ObjectKey)
 	if err != nil && !IsNotFoundError(err) {
 		return err
 	}
 
+	if oldObj != nil {
+		obj.Status = oldObj.Status
+	} else {
+		obj.Status = nil
+	}
+
+	return r.save(ctx, oldObj, obj, optimisticLock)
+}
+
+// ReplaceStatus writes only the status of the object, the stored spec and metadata are kept
+func (r *resourceRepository) ReplaceStatus(ctx context.Context, obj *sdkmeta.Object, optimisticLock bool) error {
+	oldObj, err := r.store.Get(ctx, *obj.ObjectKey)
+	if err != nil {
+		return err
+	}
+
+	systemMeta := *oldObj.SystemMeta
+	if obj.SystemMeta != nil && obj.SystemMeta.ModRevision != 0 {
+		systemMeta.ModRevision = obj.SystemMeta.ModRevision
+	}
+
+	obj.ObjectMeta = oldObj.ObjectMeta
+	obj.SystemMeta = &systemMeta
+	obj.Spec = oldObj.Spec
+
+	return r.save(ctx, oldObj, obj, optimisticLock)
+}
+
+func (r *resourceRepository) save(ctx context.Context, oldObj *sdkmeta.Object, obj *sdkmeta.Object, optimisticLock bool) error {
+	if err := checkDeletingUpdate(oldObj, obj); err != nil {
+		return err
+	}
+
+	expectedRevision := expectedRevision(oldObj, obj)
+
 	beforeSave(oldObj, obj)
 
 	putOp, err := r.store.BuildPutTxOp(obj)
@@ -81,38 +136,285 @@ func (r *resourceRepository) Replace(ctx context.Context, obj *sdkmeta.Object, o
 		obj.ObjectMeta.Labels,
 	)
 
+	indexedFields, err := r.fieldIndexOps.IndexedFields(ctx, &obj.ObjectKey.ObjectType)
+	if err != nil {
+		return errors.Wrap(err, "failed to read indexed fields")
+	}
+
+	fieldIndexOps := r.fieldIndexOps.BuildFieldIndexUpdateOps(*obj.ObjectKey, indexedFields, oldObj, obj)
+
 	txn := r.clientWrapper.Client().Txn(ctx)
 
 	ops := []clientv3.Op{}
 	ops = append(ops, putOp)
 	ops = append(ops, ownerRefOps...)
 	ops = append(ops, labelsOps...)
+	ops = append(ops, fieldIndexOps...)
 
-	if optimisticLock && oldObj != nil {
-		onlyIfOp := clientv3.Compare(clientv3.Value(objectKeyToDbKey(*obj.ObjectKey)), "=", fmt.Sprintf("%d", obj.SystemMeta.Version))
-		_, err = txn.If(onlyIfOp).Then(ops...).Commit()
+	// the deletion record is dropped while finalizers hold the object, it is put back once the last one is removed
+	if finalizersReleased(oldObj, obj) {
+		deletionOp, err := r.deletionOpBuilder.BuildMarkDeletionOperation(*obj.ObjectKey)
+		if err != nil {
+			return errors.Wrap(err, "failed to build mark deletion operation")
+		}
+		ops = append(ops, *deletionOp)
+	}
+
+	if !optimisticLock {
+		_, err = txn.Then(ops...).Commit()
 		return err
 	}
 
-	_, err = txn.Then(ops...).Commit()
-	return err
+	dbKey := objectKeyToDbKey(*obj.ObjectKey)
+
+	// with no expected revision the object must not exist yet, otherwise it must not have been modified since
+	var onlyIfOp clientv3.Cmp
+	if expectedRevision == 0 {
+		onlyIfOp = clientv3.Compare(clientv3.CreateRevision(dbKey), "=", 0)
+	} else {
+		onlyIfOp = clientv3.Compare(clientv3.ModRevision(dbKey), "=", expectedRevision)
+	}
+
+	txnResp, err := txn.If(onlyIfOp).Then(ops...).Commit()
+	if err != nil {
+		return err
+	}
+
+	if !txnResp.Succeeded {
+		return NewConflictError(dbKey)
+	}
+
+	return nil
+}
+
+// RemoveFinalizer removes the finalizer from the stored object, retrying when the object is modified concurrently
+// Removing a finalizer the object doesn't have is not an error, the stored object is returned as it is
+func (r *resourceRepository) RemoveFinalizer(ctx context.Context, key sdkmeta.ObjectKey, finalizer string) (*sdkmeta.Object, error) {
+	for attempt := 1; ; attempt++ {
+		obj, err := r.store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		finalizers := removeFinalizer(obj.ObjectMeta.Finalizers, finalizer)
+		if len(finalizers) == len(obj.ObjectMeta.Finalizers) {
+			return obj, nil
+		}
+
+		oldObj := *obj
+		oldMeta := *obj.ObjectMeta
+		oldObj.ObjectMeta = &oldMeta
+		oldSystemMeta := *obj.SystemMeta
+		oldObj.SystemMeta = &oldSystemMeta
+
+		obj.ObjectMeta.Finalizers = finalizers
+
+		err = r.save(ctx, &oldObj, obj, true)
+		if err == nil {
+			return obj, nil
+		}
+		if !IsConflictError(err) || attempt == removeFinalizerAttempts {
+			return nil, err
+		}
+	}
 }
 
 func (r *resourceRepository) Get(ctx context.Context, key sdkmeta.ObjectKey) (*sdkmeta.Object, error) {
 	return r.store.Get(ctx, key)
 }
 
-func (r *resourceRepository) List(ctx context.Context, objType *sdkmeta.ObjectType) ([]*sdkmeta.Object, error) {
-	res, err := r.store.List(ctx, objType, nil)
+func (r *resourceRepository) GetCached(ctx context.Context, key sdkmeta.ObjectKey, minRevision int64) (*sdkmeta.Object, error) {
+	if r.readCache != nil && minRevision > 0 {
+		if object, ok := r.readCache.Get(ctx, key, minRevision); ok {
+			if object == nil {
+				return nil, NewNotFoundError(objectKeyToDbKey(key))
+			}
+			return object, nil
+		}
+	}
+	return r.store.Get(ctx, key)
+}
+
+// List returns a page of objects, all pages of one list are read at the revision of the first one
+// A first page with a minimum revision may come from the read cache, the next pages are then read from etcd
+// at the revision of the cache
+func (r *resourceRepository) List(ctx context.Context, objType *sdkmeta.ObjectType, options types.ListOptions) (*types.ObjectBatch, error) {
+	if r.readCache != nil && options.MinRevision > 0 && options.Continue == "" {
+		if batch, ok, err := r.listCached(ctx, objType, options); err != nil || ok {
+			return batch, err
+		}
+	}
+
+	var token *continueToken
+	if options.Continue != "" {
+		var err error
+		token, err = decodeContinueToken(options.Continue, typePrefixDbKey(objType))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if query, ok, err := r.indexQuery(ctx, objType, options); err != nil || ok {
+		if err != nil {
+			return nil, err
+		}
+		return r.listByIndex(ctx, options, token, query)
+	}
+
+	paging := &types.Paging{Limit: options.Limit}
+	if token != nil {
+		paging.LastKey = token.LastKey
+		paging.Revision = token.Revision
+	}
+
+	batch, err := r.store.List(ctx, objType, paging)
+	if err != nil {
+		return nil, err
+	}
+
+	if paging.Revision > 0 {
+		batch.Revision = paging.Revision
+	}
+
+	// the page may hold no object of the type at all, the next one still starts after the last key read
+	if err := setContinueToken(batch, batch.LastKey); err != nil {
+		return nil, err
+	}
+
+	// without an indexed requirement the page is filtered after reading, so it may come out short
+	if !options.LabelSelector.Empty() || !options.FieldSelector.Empty() {
+		batch.Objects = filterBySelectors(batch.Objects, options)
+	}
+
+	return batch, nil
+}
+
+func (r *resourceRepository) listCached(
+	ctx context.Context,
+	objType *sdkmeta.ObjectType,
+	options types.ListOptions,
+) (*types.ObjectBatch, bool, error) {
+	objects, revision, ok := r.readCache.List(ctx, objType, options.MinRevision)
+	if !ok {
+		return nil, false, nil
+	}
+
+	batch := &types.ObjectBatch{
+		Revision: revision,
+		Objects:  filterBySelectors(objects, options),
+	}
+
+	if options.Limit > 0 && len(batch.Objects) > options.Limit {
+		batch.Objects = batch.Objects[:options.Limit]
+		batch.More = true
+
+		lastObject := batch.Objects[len(batch.Objects)-1]
+		if err := setContinueToken(batch, objectKeyToDbKey(*lastObject.ObjectKey)); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return batch, true, nil
+}
+
+// indexQuery returns a query of the keys of candidate objects when an index can answer a requirement,
+// an indexed field is preferred over a label
+func (r *resourceRepository) indexQuery(
+	ctx context.Context,
+	objType *sdkmeta.ObjectType,
+	options types.ListOptions,
+) (indexQuery, bool, error) {
+	// the schema is only read when a requirement could be answered by a field index
+	if mayUseFieldIndex(options.FieldSelector) {
+		indexedFields, err := r.fieldIndexOps.IndexedFields(ctx, objType)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "failed to read indexed fields")
+		}
+
+		if requirement, ok := IndexableFieldRequirement(options.FieldSelector, indexedFields); ok {
+			return func(revision int64) ([]sdkmeta.ObjectKey, int64, error) {
+				return r.fieldIndexOps.QueryObjectKeys(ctx, objType, requirement, revision)
+			}, true, nil
+		}
+	}
+
+	for _, requirement := range options.LabelSelector {
+		if IsIndexableRequirement(requirement) {
+			return func(revision int64) ([]sdkmeta.ObjectKey, int64, error) {
+				return r.labelsOpBuilder.QueryObjectKeys(ctx, objType, requirement, revision)
+			}, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// indexQuery returns the sorted keys of candidate objects at the revision, a zero revision reads the latest one
+type indexQuery func(revision int64) ([]sdkmeta.ObjectKey, int64, error)
+
+// listByIndex reads only the objects the index points to and filters them by the selectors
+func (r *resourceRepository) listByIndex(
+	ctx context.Context,
+	options types.ListOptions,
+	token *continueToken,
+	query indexQuery,
+) (*types.ObjectBatch, error) {
+	var revision int64
+	var lastKey string
+	if token != nil {
+		revision = token.Revision
+		lastKey = token.LastKey
+	}
+
+	keys, revision, err := query(revision)
 	if err != nil {
 		return nil, err
 	}
-	return res.Objects, nil
+
+	start := sort.Search(len(keys), func(i int) bool {
+		return objectKeyToDbKey(keys[i]) > lastKey
+	})
+	keys = keys[start:]
+
+	batch := &types.ObjectBatch{Revision: revision}
+
+	for len(keys) > 0 {
+		chunk := keys
+		if len(chunk) > maxTxnOps {
+			chunk = chunk[:maxTxnOps]
+		}
+		keys = keys[len(chunk):]
+
+		found, err := r.store.GetMany(ctx, chunk, revision)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, object := range found.Objects {
+			if !matchesSelectors(object, options) {
+				continue
+			}
+			batch.Objects = append(batch.Objects, object)
+
+			if options.Limit > 0 && len(batch.Objects) == options.Limit {
+				batch.More = i < len(found.Objects)-1 || len(keys) > 0
+				if err := setContinueToken(batch, objectKeyToDbKey(*object.ObjectKey)); err != nil {
+					return nil, err
+				}
+				return batch, nil
+			}
+		}
+	}
+
+	return batch, nil
 }
 
 func (r *resourceRepository) Delete(ctx context.Context, key sdkmeta.ObjectKey, lockValue string) error {
 	obj, err := r.store.Get(ctx, key)
 	if err != nil {
+		if IsNotFoundError(err) {
+			return r.dropDeletion(ctx, key, lockValue)
+		}
 		return err
 	}
 
@@ -120,6 +422,13 @@ func (r *resourceRepository) Delete(ctx context.Context, key sdkmeta.ObjectKey,
 
 	labelsCleanupOps := r.labelsOpBuilder.BuildLabelsCleanupOps(key, obj.ObjectMeta.Labels)
 
+	indexedFields, err := r.fieldIndexOps.IndexedFields(ctx, &key.ObjectType)
+	if err != nil {
+		return errors.Wrap(err, "failed to read indexed fields")
+	}
+
+	fieldIndexCleanupOps := r.fieldIndexOps.BuildFieldIndexCleanupOps(key, indexedFields, obj)
+
 	childResources, err := r.ownerRefOpBuilder.QueryChildren(ctx, key)
 	if err != nil {
 		return errors.Wrap(err, "failed to query children resources")
@@ -134,22 +443,73 @@ func (r *resourceRepository) Delete(ctx context.Context, key sdkmeta.ObjectKey,
 
 	var ops []clientv3.Op
 	ops = append(ops, clientv3.OpDelete(objectKeyToDbKey(key)))
-	ops = append(ops, clientv3.OpDelete(deletionLockDbKey(key)))
+	ops = append(ops, clientv3.OpDelete(deletionDbKey(key)))
+	ops = append(ops, r.deletionOpBuilder.BuildLockDropOps(key)...)
 	ops = append(ops, childrenReferencesClenaupOps...)
 	ops = append(ops, labelsCleanupOps...)
-	ops = append(ops, childrenCleanupOps...)
+	ops = append(ops, fieldIndexCleanupOps...)
+
+	// children that don't fit into the transaction of the object are settled in chunks before it is deleted,
+	// a retry after a failed chunk finds the children of the committed ones marked or orphaned already
+	if len(ops)+countOps(childrenCleanupOps) > maxTxnOps {
+		if err := r.commitChunks(ctx, childrenCleanupOps, ifLockedByItselfOp); err != nil {
+			return errors.Wrap(err, "failed to commit children cleanup operations")
+		}
+	} else {
+		for _, childOps := range childrenCleanupOps {
+			ops = append(ops, childOps...)
+		}
+	}
 
 	txn := r.clientWrapper.Client().Txn(ctx)
-	_, err = txn.If(ifLockedByItselfOp).Then(ops...).Commit()
-	return err
+	txnResp, err := txn.If(ifLockedByItselfOp).Then(ops...).Commit()
+	if err != nil {
+		return err
+	}
+	r.deletionOpBuilder.RevokeLockLease(ctx, key, txnResp)
+	return nil
+}
+
+// dropDeletion removes the deletion record and lock of an object that is gone already
+func (r *resourceRepository) dropDeletion(ctx context.Context, key sdkmeta.ObjectKey, lockValue string) error {
+	ifLockedByItselfOp := clientv3.Compare(clientv3.Value(deletionLockDbKey(key)), "=", lockValue)
+
+	ops := append([]clientv3.Op{clientv3.OpDelete(deletionDbKey(key))}, r.deletionOpBuilder.BuildLockDropOps(key)...)
+
+	txn := r.clientWrapper.Client().Txn(ctx)
+	txnResp, err := txn.If(ifLockedByItselfOp).Then(ops...).Commit()
+	if err != nil {
+		return err
+	}
+	r.deletionOpBuilder.RevokeLockLease(ctx, key, txnResp)
+	return nil
+}
+
+// commitChunks commits the groups of operations in transactions within the etcd limit, each one conditional on cmps
+func (r *resourceRepository) commitChunks(ctx context.Context, groups [][]clientv3.Op, cmps ...clientv3.Cmp) error {
+	for _, chunk := range chunkOps(groups, maxTxnOps) {
+		txn := r.clientWrapper.Client().Txn(ctx)
+		resp, err := txn.If(cmps...).Then(chunk...).Commit()
+		if err != nil {
+			return err
+		}
+		if !resp.Succeeded {
+			return errors.New("transaction conditions are not met")
+		}
+	}
+	return nil
 }
 
-func (r *resourceRepository) Watch(ctx context.Context, objType *sdkmeta.ObjectType, revision int64) (<-chan types.WatchEvent, error) {
-	return r.watchManager.Watch(ctx, objType, revision)
+func (r *resourceRepository) Watch(ctx context.Context, objType *sdkmeta.ObjectType, options types.WatchOptions) (<-chan types.WatchEvent, error) {
+	if options.SendInitialEvents {
+		return r.watchManager.ListAndWatch(ctx, objType)
+	}
+	return r.watchManager.Watch(ctx, objType, options.Revision)
 }
 
 // MarkDeleted marks a resource for deletion by setting deletionTimestamp and adding to deletion collection
-func (r *resourceRepository) MarkDeleted(ctx context.Context, key sdkmeta.ObjectKey) error {
+// The propagation policy is kept with the resource, the first deletion request decides it
+func (r *resourceRepository) MarkDeleted(ctx context.Context, key sdkmeta.ObjectKey, options types.DeleteOptions) error {
 	resource, err := r.store.Get(ctx, key)
 	if err != nil {
 		return errors.Wrap(err, "failed to get resource for deletion marking")
@@ -159,8 +519,14 @@ func (r *resourceRepository) MarkDeleted(ctx context.Context, key sdkmeta.Object
 		return nil // Already marked for deletion
 	}
 
+	policy := options.PropagationPolicy
+	if policy == "" {
+		policy = sdkmeta.PropagationPolicyBackground
+	}
+
 	now := time.Now()
 	resource.SystemMeta.DeletionTime = &now
+	resource.SystemMeta.PropagationPolicy = policy
 
 	deletionOp, err := r.deletionOpBuilder.BuildMarkDeletionOperation(key)
 	if err != nil {
@@ -177,9 +543,187 @@ func (r *resourceRepository) MarkDeleted(ctx context.Context, key sdkmeta.Object
 	return err
 }
 
-// ListDeletions returns a batch of resources marked for deletion using distributed locking
-func (r *resourceRepository) ListDeletions(ctx context.Context, lockKey string, lockExp time.Duration, batchLimit int) (*types.DeletionBatch, error) {
-	return r.deletionOpBuilder.AcquireDeletions(ctx, lockKey, lockExp, batchLimit)
+func (r *resourceRepository) MarkChildrenDeleted(ctx context.Context, obj *sdkmeta.Object, lockValue string) (int, error) {
+	children, err := r.ownerRefOpBuilder.QueryChildren(ctx, *obj.ObjectKey)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to query children resources")
+	}
+
+	ops, pending, err := r.deletionOpBuilder.BuildChildrenForegroundOps(obj, children)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create children deletion operations")
+	}
+
+	ifLockedByItselfOp := clientv3.Compare(clientv3.Value(deletionLockDbKey(*obj.ObjectKey)), "=", lockValue)
+	if err := r.commitChunks(ctx, ops, ifLockedByItselfOp); err != nil {
+		return 0, errors.Wrap(err, "failed to commit children deletion operations")
+	}
+	return pending, nil
+}
+
+// DeferDeletion drops the deletion record of an object held by finalizers, so it is not processed until
+// the last finalizer is removed, nothing is dropped if the object was modified since it was read
+func (r *resourceRepository) DeferDeletion(ctx context.Context, obj *sdkmeta.Object, lockValue string) error {
+	key := *obj.ObjectKey
+
+	ifUnchangedOp := clientv3.Compare(clientv3.ModRevision(objectKeyToDbKey(key)), "=", obj.SystemMeta.ModRevision)
+	ifLockedByItselfOp := clientv3.Compare(clientv3.Value(deletionLockDbKey(key)), "=", lockValue)
+
+	ops := append([]clientv3.Op{clientv3.OpDelete(deletionDbKey(key))}, r.deletionOpBuilder.BuildLockDropOps(key)...)
+
+	txn := r.clientWrapper.Client().Txn(ctx)
+	txnResp, err := txn.If(ifUnchangedOp, ifLockedByItselfOp).Then(ops...).Commit()
+	if err != nil {
+		return err
+	}
+	r.deletionOpBuilder.RevokeLockLease(ctx, key, txnResp)
+	return nil
+}
+
+// ListDeletions returns the deletion records oldest first and the revision they were read at
+func (r *resourceRepository) ListDeletions(ctx context.Context, batchLimit int) ([]types.DeletionRecord, int64, error) {
+	return r.deletionOpBuilder.ListDeletions(ctx, batchLimit)
+}
+
+// WatchDeletions streams the changes of the deletion records made after the revision
+func (r *resourceRepository) WatchDeletions(ctx context.Context, revision int64) (<-chan types.DeletionEvent, error) {
+	return r.deletionOpBuilder.WatchDeletions(ctx, revision)
+}
+
+// AcquireDeletion locks the deletion of the object for lockKey, it returns false when another worker holds the lock
+func (r *resourceRepository) AcquireDeletion(ctx context.Context, key sdkmeta.ObjectKey, lockKey string, lockExp time.Duration) (bool, error) {
+	return r.deletionOpBuilder.AcquireDeletion(ctx, key, lockKey, lockExp)
+}
+
+// ListOwnerReferences returns a page of the owner reference index after lastKey, an empty one starts the walk
+func (r *resourceRepository) ListOwnerReferences(ctx context.Context, lastKey string, limit int) (*types.OwnerReferenceBatch, error) {
+	return r.ownerRefOpBuilder.ListOwnerReferences(ctx, lastKey, limit)
+}
+
+// CheckOwnerReference settles an entry of the owner reference index whose child or owner is gone
+// The child of a gone owner is marked for deletion, unless another owner holds it, then it only loses the reference
+// The child is written only if it wasn't modified since it was read, a conflict is returned otherwise
+func (r *resourceRepository) CheckOwnerReference(ctx context.Context, entry types.OwnerReferenceEntry) (types.OwnerReferenceCheck, error) {
+	indexDbKey := buildOwnerReferenceIndexDbKey(entry.Parent, entry.Child)
+	childDbKey := objectKeyToDbKey(entry.Child)
+
+	child, err := r.store.Get(ctx, entry.Child)
+	if err != nil && !IsNotFoundError(err) {
+		return "", errors.Wrap(err, "failed to get child resource")
+	}
+
+	var childRevision int64
+	var ownerRefs []sdkmeta.OwnerReference
+	if child != nil {
+		childRevision = child.SystemMeta.ModRevision
+		for _, ownerRef := range child.ObjectMeta.OwnerReferences {
+			if ownerRef.ToObjectKey() == entry.Parent {
+				ownerRefs = append(ownerRefs, ownerRef)
+			}
+		}
+	}
+
+	// the index outlived the child or its reference, a missing child has a zero revision
+	if len(ownerRefs) == 0 {
+		if err := r.commitIfUnchanged(ctx, childDbKey, childRevision, clientv3.OpDelete(indexDbKey)); err != nil {
+			return "", err
+		}
+		return types.OwnerReferenceStale, nil
+	}
+
+	parent, err := r.store.Get(ctx, entry.Parent)
+	if err != nil && !IsNotFoundError(err) {
+		return "", errors.Wrap(err, "failed to get owner resource")
+	}
+	// an owner stored before uids were assigned can only be matched by its key
+	if parent != nil {
+		for _, ownerRef := range ownerRefs {
+			if parent.SystemMeta.UID == "" || ownerRef.UID == parent.SystemMeta.UID {
+				return types.OwnerReferenceValid, nil
+			}
+		}
+	}
+
+	if child.SystemMeta.DeletionTime != nil {
+		return types.OwnerReferenceValid, nil
+	}
+
+	// a child held by another owner only loses the references to the gone one
+	check := types.OwnerReferenceOrphaned
+	var ops []clientv3.Op
+	if len(ownerRefs) < len(child.ObjectMeta.OwnerReferences) {
+		check = types.OwnerReferenceDropped
+		child.ObjectMeta.OwnerReferences = removeOwnerReference(child.ObjectMeta.OwnerReferences, entry.Parent, "")
+		putOp, err := r.store.BuildPutTxOp(child)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to build set operation for child resource")
+		}
+		ops = []clientv3.Op{putOp, clientv3.OpDelete(indexDbKey)}
+	} else {
+		ops, err = r.deletionOpBuilder.buildChildMarkDeletionOps(child, sdkmeta.PropagationPolicyBackground)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to create child deletion operations")
+		}
+	}
+
+	if err := r.commitIfUnchanged(ctx, childDbKey, childRevision, ops...); err != nil {
+		return "", err
+	}
+	return check, nil
+}
+
+// commitIfUnchanged commits the operations if the key is still at the mod revision, zero for a missing key
+func (r *resourceRepository) commitIfUnchanged(ctx context.Context, dbKey string, revision int64, ops ...clientv3.Op) error {
+	ifUnchangedOp := clientv3.Compare(clientv3.ModRevision(dbKey), "=", revision)
+
+	txnResp, err := r.clientWrapper.Client().Txn(ctx).If(ifUnchangedOp).Then(ops...).Commit()
+	if err != nil {
+		return err
+	}
+	if !txnResp.Succeeded {
+		return NewConflictError(dbKey)
+	}
+	return nil
+}
+
+// ReleaseDeletion drops the deletion lock of the object if lockKey holds it
+func (r *resourceRepository) ReleaseDeletion(ctx context.Context, key sdkmeta.ObjectKey, lockKey string) error {
+	return r.deletionOpBuilder.ReleaseDeletion(ctx, key, lockKey)
+}
+
+// setContinueToken sets the token of the page following lastKey when the batch has more pages
+func setContinueToken(batch *types.ObjectBatch, lastKey string) error {
+	if !batch.More {
+		return nil
+	}
+
+	token, err := encodeContinueToken(batch.Revision, lastKey)
+	if err != nil {
+		return err
+	}
+	batch.Continue = token
+	return nil
+}
+
+func filterBySelectors(objects []*sdkmeta.Object, options types.ListOptions) []*sdkmeta.Object {
+	filtered := make([]*sdkmeta.Object, 0, len(objects))
+	for _, object := range objects {
+		if matchesSelectors(object, options) {
+			filtered = append(filtered, object)
+		}
+	}
+	return filtered
+}
+
+func matchesSelectors(object *sdkmeta.Object, options types.ListOptions) bool {
+	return options.LabelSelector.Matches(objectLabels(object)) && options.FieldSelector.Matches(object)
+}
+
+func objectLabels(object *sdkmeta.Object) map[string]string {
+	if object.ObjectMeta == nil {
+		return nil
+	}
+	return object.ObjectMeta.Labels
 }
 
 func beforeSave(oldResource *sdkmeta.Object, newResource *sdkmeta.Object) {
@@ -187,9 +731,88 @@ func beforeSave(oldResource *sdkmeta.Object, newResource *sdkmeta.Object) {
 
 	if oldResource == nil {
 		newResource.SystemMeta = &sdkmeta.SystemMeta{
+			UID:          lib.NewUID(),
 			CreationTime: &now,
+			Generation:   1,
 		}
-	} else {
-		newResource.SystemMeta.LastUpdateTime = &now
+		return
+	}
+
+	if newResource.SystemMeta == nil {
+		newResource.SystemMeta = &sdkmeta.SystemMeta{}
+	}
+
+	// system fields are owned by the server, the ones sent by the client are ignored
+	newResource.SystemMeta.UID = oldResource.SystemMeta.UID
+	newResource.SystemMeta.CreationTime = oldResource.SystemMeta.CreationTime
+	newResource.SystemMeta.DeletionTime = oldResource.SystemMeta.DeletionTime
+	newResource.SystemMeta.PropagationPolicy = oldResource.SystemMeta.PropagationPolicy
+	newResource.SystemMeta.LastUpdateTime = &now
+
+	newResource.SystemMeta.Generation = oldResource.SystemMeta.Generation
+	if specChanged(oldResource, newResource) {
+		newResource.SystemMeta.Generation++
+	}
+}
+
+// checkDeletingUpdate rejects changes of the spec and new finalizers of an object that is being deleted
+func checkDeletingUpdate(oldResource *sdkmeta.Object, newResource *sdkmeta.Object) error {
+	if oldResource == nil || oldResource.SystemMeta.DeletionTime == nil {
+		return nil
+	}
+
+	if specChanged(oldResource, newResource) {
+		return internalerrors.NewInvalidInputError("spec can't be changed while the resource is being deleted")
+	}
+
+	for _, finalizer := range newResource.ObjectMeta.Finalizers {
+		if !slices.Contains(oldResource.ObjectMeta.Finalizers, finalizer) {
+			return internalerrors.NewInvalidInputError(
+				fmt.Sprintf("finalizer %q can't be added while the resource is being deleted", finalizer))
+		}
+	}
+
+	return nil
+}
+
+// finalizersReleased reports whether the update removes the last finalizer of an object that is being deleted
+func finalizersReleased(oldResource *sdkmeta.Object, newResource *sdkmeta.Object) bool {
+	return oldResource != nil &&
+		oldResource.SystemMeta.DeletionTime != nil &&
+		len(oldResource.ObjectMeta.Finalizers) > 0 &&
+		len(newResource.ObjectMeta.Finalizers) == 0
+}
+
+func removeFinalizer(finalizers []string, finalizer string) []string {
+	var remaining []string
+	for _, f := range finalizers {
+		if f != finalizer {
+			remaining = append(remaining, f)
+		}
+	}
+	return remaining
+}
+
+// specChanged compares specs by their json form, as the stored one is decoded into generic maps
+func specChanged(oldResource *sdkmeta.Object, newResource *sdkmeta.Object) bool {
+	oldSpec, err := json.Marshal(oldResource.Spec)
+	if err != nil {
+		return true
+	}
+	newSpec, err := json.Marshal(newResource.Spec)
+	if err != nil {
+		return true
+	}
+	return !bytes.Equal(oldSpec, newSpec)
+}
+
+// expectedRevision is the mod revision the client has seen, the stored one when the client did not send it
+func expectedRevision(oldResource *sdkmeta.Object, newResource *sdkmeta.Object) int64 {
+	if newResource.SystemMeta != nil && newResource.SystemMeta.ModRevision != 0 {
+		return newResource.SystemMeta.ModRevision
+	}
+	if oldResource != nil {
+		return oldResource.SystemMeta.ModRevision
 	}
+	return 0
 }
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 
@@ -26,6 +30,7 @@ func TestResourceRepository_Delete_ResourceWithoutOwnerReferences(t *testing.T)
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -60,7 +65,7 @@ func TestResourceRepository_Delete_ResourceWithoutOwnerReferences(t *testing.T)
 	mockTxn := mocks.NewMockTxn(t)
 	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
 	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
-	mockTxn.EXPECT().Then(mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockTxn)
 	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
 	mockClient.EXPECT().Client().Return(mockEtcdClient)
 
@@ -81,6 +86,7 @@ func TestResourceRepository_Delete_ResourceWithOwnerReferences(t *testing.T) {
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -138,7 +144,7 @@ func TestResourceRepository_Delete_ResourceWithOwnerReferences(t *testing.T) {
 	mockTxn := mocks.NewMockTxn(t)
 	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
 	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
-	mockTxn.EXPECT().Then(mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockTxn)
 	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
 	mockClient.EXPECT().Client().Return(mockEtcdClient)
 
@@ -159,6 +165,7 @@ func TestResourceRepository_Delete_ResourceWithChildResources(t *testing.T) {
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -193,7 +200,7 @@ func TestResourceRepository_Delete_ResourceWithChildResources(t *testing.T) {
 	mockTxn := mocks.NewMockTxn(t)
 	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
 	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
-	mockTxn.EXPECT().Then(mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockTxn)
 	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
 	mockClient.EXPECT().Client().Return(mockEtcdClient)
 
@@ -214,6 +221,7 @@ func TestResourceRepository_Delete_ResourceNotFound(t *testing.T) {
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -224,15 +232,27 @@ func TestResourceRepository_Delete_ResourceNotFound(t *testing.T) {
 		Name: "nonexistent-resource",
 	}
 
-	// Given: A resource that does not exist
+	// Given: A resource that does not exist anymore but is still marked for deletion
 	mockStore.EXPECT().Get(ctx, key).Return(nil, NewNotFoundError("resource not found"))
 
+	var committed []clientv3.Op
+	mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+	mockTxn := mocks.NewMockTxn(t)
+	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything, mock.Anything, mock.Anything).Run(func(ops ...clientv3.Op) { committed = ops }).Return(mockTxn)
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
+	mockClient.EXPECT().Client().Return(mockEtcdClient)
+
 	// When: Attempting to delete the resource
 	err := repo.Delete(ctx, key, "test-lock")
 
-	// Then: An error should be returned
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "resource not found")
+	// Then: Only the deletion record and lock are removed
+	require.NoError(t, err)
+	require.Len(t, committed, 3)
+	assert.Equal(t, deletionDbKey(key), string(committed[0].KeyBytes()))
+	assert.True(t, committed[2].IsDelete())
+	assert.Equal(t, deletionLockDbKey(key), string(committed[2].KeyBytes()))
 }
 
 func TestResourceRepository_Delete_ErrorDuringOwnerReferenceCleanup(t *testing.T) {
@@ -245,6 +265,7 @@ func TestResourceRepository_Delete_ErrorDuringOwnerReferenceCleanup(t *testing.T
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -304,6 +325,7 @@ func TestResourceRepository_Delete_ResourceWithLabels(t *testing.T) {
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -345,7 +367,7 @@ func TestResourceRepository_Delete_ResourceWithLabels(t *testing.T) {
 	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
 	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
 	// 2 base ops + 0 owner ref ops + 3 labels ops + 0 children ops = 5 total
-	mockTxn.EXPECT().Then(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockTxn)
 	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
 	mockClient.EXPECT().Client().Return(mockEtcdClient)
 
@@ -355,3 +377,449 @@ func TestResourceRepository_Delete_ResourceWithLabels(t *testing.T) {
 	// Then: The deletion should succeed
 	assert.NoError(t, err)
 }
+
+func newPropagationTestObjects(policy sdkmeta.PropagationPolicy) (*sdkmeta.Object, *sdkmeta.Object) {
+	parentKey := sdkmeta.ObjectKey{
+		ObjectType: sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Network", Namespace: "default"},
+		Name:       "main",
+	}
+	childKey := sdkmeta.ObjectKey{
+		ObjectType: sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Subnet", Namespace: "default"},
+		Name:       "main-a",
+	}
+
+	parent := &sdkmeta.Object{
+		ObjectKey:  &parentKey,
+		ObjectMeta: &sdkmeta.ObjectMeta{},
+		SystemMeta: &sdkmeta.SystemMeta{UID: "parent-uid", PropagationPolicy: policy},
+	}
+	child := &sdkmeta.Object{
+		ObjectKey: &childKey,
+		ObjectMeta: &sdkmeta.ObjectMeta{
+			OwnerReferences: []sdkmeta.OwnerReference{
+				{TypeMeta: &parentKey.ObjectType, Name: "main", UID: "parent-uid", BlockOwnerDeletion: true},
+			},
+		},
+		SystemMeta: &sdkmeta.SystemMeta{UID: "child-uid"},
+	}
+	return parent, child
+}
+
+func TestResourceRepository_Delete_PropagationPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        sdkmeta.PropagationPolicy
+		wantOrphaned  bool
+		wantChildOps  int
+		wantChildMark bool
+	}{
+		{name: "background", policy: sdkmeta.PropagationPolicyBackground, wantChildMark: true},
+		{name: "orphan", policy: sdkmeta.PropagationPolicyOrphan, wantOrphaned: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mocks.NewMockResourceStore(t)
+			mockClient := mocks.NewMockClientWrapper(t)
+			repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+			ctx := context.Background()
+
+			// Given: A parent deleted with the policy and a child blocking its deletion
+			parent, child := newPropagationTestObjects(tt.policy)
+			mockClient.EXPECT().Get(ctx, "/schema/example.com/Network").Return(nil, NewNotFoundError("/schema/example.com/Network"))
+			mockStore.EXPECT().Get(ctx, *parent.ObjectKey).Return(parent, nil)
+			mockStore.EXPECT().Get(ctx, *child.ObjectKey).Return(child, nil)
+			indexKey := buildOwnerReferenceIndexDbKey(*parent.ObjectKey, *child.ObjectKey)
+			mockClient.EXPECT().List(ctx, types.Paging{Prefix: buildOwnerReferenceIndexDbKeyPrefix(*parent.ObjectKey)}).
+				Return(&types.Batch{KVs: []types.KeyValue{{Key: indexKey}}}, nil)
+
+			var written *sdkmeta.Object
+			mockStore.EXPECT().BuildPutTxOp(mock.Anything).RunAndReturn(func(obj *sdkmeta.Object) (clientv3.Op, error) {
+				written = obj
+				return clientv3.OpPut(objectKeyToDbKey(*obj.ObjectKey), "{}"), nil
+			})
+
+			var committed []clientv3.Op
+			mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+			mockTxn := mocks.NewMockTxn(t)
+			mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+			mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+			mockTxn.EXPECT().Then(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Run(func(ops ...clientv3.Op) { committed = ops }).Return(mockTxn)
+			mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
+			mockClient.EXPECT().Client().Return(mockEtcdClient)
+
+			// When: The parent is deleted
+			err := repo.Delete(ctx, *parent.ObjectKey, "test-lock")
+
+			// Then: The child is orphaned or marked for deletion by the policy
+			require.NoError(t, err)
+			require.NotNil(t, written)
+			assert.Equal(t, *child.ObjectKey, *written.ObjectKey)
+
+			var markedKeys []string
+			for _, op := range committed {
+				markedKeys = append(markedKeys, string(op.KeyBytes()))
+			}
+			if tt.wantOrphaned {
+				assert.Empty(t, written.ObjectMeta.OwnerReferences)
+				assert.Nil(t, written.SystemMeta.DeletionTime)
+				assert.Contains(t, markedKeys, indexKey)
+				assert.NotContains(t, markedKeys, deletionDbKey(*child.ObjectKey))
+			}
+			if tt.wantChildMark {
+				assert.Len(t, written.ObjectMeta.OwnerReferences, 1)
+				assert.NotNil(t, written.SystemMeta.DeletionTime)
+				assert.Equal(t, sdkmeta.PropagationPolicyBackground, written.SystemMeta.PropagationPolicy)
+				assert.Contains(t, markedKeys, deletionDbKey(*child.ObjectKey))
+			}
+		})
+	}
+}
+
+func TestRemoveOwnerReference(t *testing.T) {
+	parent, _ := newPropagationTestObjects(sdkmeta.PropagationPolicyOrphan)
+	routerType := sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Router", Namespace: "default"}
+
+	ownerRefs := []sdkmeta.OwnerReference{
+		{TypeMeta: &parent.ObjectKey.ObjectType, Name: "main", UID: "parent-uid", BlockOwnerDeletion: true},
+		{TypeMeta: &parent.ObjectKey.ObjectType, Name: "main", UID: "replaced-uid"},
+		{TypeMeta: &routerType, Name: "edge", UID: "router-uid", BlockOwnerDeletion: true},
+	}
+
+	tests := []struct {
+		name     string
+		ownerUID string
+		wantUIDs []string
+	}{
+		{name: "owner with uid", ownerUID: "parent-uid", wantUIDs: []string{"replaced-uid", "router-uid"}},
+		{name: "owner stored without uid", ownerUID: "", wantUIDs: []string{"router-uid"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When: The references to the owner are removed
+			remaining := removeOwnerReference(ownerRefs, *parent.ObjectKey, tt.ownerUID)
+
+			// Then: Only the references to that owner are gone
+			var uids []string
+			for _, ownerRef := range remaining {
+				uids = append(uids, ownerRef.UID)
+			}
+			assert.Equal(t, tt.wantUIDs, uids)
+		})
+	}
+}
+
+func TestHasOtherBlockingReference(t *testing.T) {
+	parent, child := newPropagationTestObjects(sdkmeta.PropagationPolicyForeground)
+	routerType := sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Router", Namespace: "default"}
+
+	// Given: A child blocking the parent and held by another owner with the same uid
+	ownerRefs := append(child.ObjectMeta.OwnerReferences,
+		sdkmeta.OwnerReference{TypeMeta: &routerType, Name: "edge", UID: "parent-uid"})
+
+	// Then: Only the blocking reference of the parent itself is found
+	assert.True(t, blocksOwnerDeletion(ownerRefs, parent))
+	assert.False(t, hasOtherBlockingReference(ownerRefs, parent))
+
+	ownerRefs[1].BlockOwnerDeletion = true
+	assert.True(t, hasOtherBlockingReference(ownerRefs, parent))
+}
+
+func TestResourceRepository_MarkChildrenDeleted(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+	ctx := context.Background()
+
+	// Given: A parent deleted in the foreground with a child blocking its deletion
+	parent, child := newPropagationTestObjects(sdkmeta.PropagationPolicyForeground)
+	mockStore.EXPECT().Get(ctx, *child.ObjectKey).Return(child, nil)
+	mockClient.EXPECT().List(ctx, types.Paging{Prefix: buildOwnerReferenceIndexDbKeyPrefix(*parent.ObjectKey)}).
+		Return(&types.Batch{KVs: []types.KeyValue{{Key: buildOwnerReferenceIndexDbKey(*parent.ObjectKey, *child.ObjectKey)}}}, nil)
+	mockStore.EXPECT().BuildPutTxOp(child).Return(clientv3.OpPut(objectKeyToDbKey(*child.ObjectKey), "{}"), nil)
+
+	mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+	mockTxn := mocks.NewMockTxn(t)
+	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything, mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
+	mockClient.EXPECT().Client().Return(mockEtcdClient)
+
+	// When: The children of the parent are marked
+	pending, err := repo.MarkChildrenDeleted(ctx, parent, "test-lock")
+
+	// Then: The child is deleted in the foreground too and the parent waits for it
+	require.NoError(t, err)
+	assert.Equal(t, 1, pending)
+	assert.NotNil(t, child.SystemMeta.DeletionTime)
+	assert.Equal(t, sdkmeta.PropagationPolicyForeground, child.SystemMeta.PropagationPolicy)
+}
+
+func TestParseOwnerReferenceIndexDbKey(t *testing.T) {
+	parent, child := newPropagationTestObjects(sdkmeta.PropagationPolicyBackground)
+
+	parentKey, childKey, err := parseOwnerReferenceIndexDbKey(buildOwnerReferenceIndexDbKey(*parent.ObjectKey, *child.ObjectKey))
+
+	require.NoError(t, err)
+	assert.Equal(t, *parent.ObjectKey, parentKey)
+	assert.Equal(t, *child.ObjectKey, childKey)
+}
+
+func TestResourceRepository_Delete_ChildrenInChunks(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+	ctx := context.Background()
+
+	// Given: A parent with more children than fit into one transaction
+	parent, template := newPropagationTestObjects(sdkmeta.PropagationPolicyBackground)
+	mockClient.EXPECT().Get(ctx, "/schema/example.com/Network").Return(nil, NewNotFoundError("/schema/example.com/Network"))
+	mockStore.EXPECT().Get(ctx, *parent.ObjectKey).Return(parent, nil)
+
+	const childCount = 120
+	var indexKVs []types.KeyValue
+	for i := 0; i < childCount; i++ {
+		childKey := *template.ObjectKey
+		childKey.Name = fmt.Sprintf("main-%03d", i)
+		child := &sdkmeta.Object{
+			ObjectKey:  &childKey,
+			ObjectMeta: &sdkmeta.ObjectMeta{OwnerReferences: template.ObjectMeta.OwnerReferences},
+			SystemMeta: &sdkmeta.SystemMeta{UID: fmt.Sprintf("child-%d", i)},
+		}
+		mockStore.EXPECT().Get(ctx, childKey).Return(child, nil)
+		indexKVs = append(indexKVs, types.KeyValue{Key: buildOwnerReferenceIndexDbKey(*parent.ObjectKey, childKey)})
+	}
+	mockClient.EXPECT().List(ctx, types.Paging{Prefix: buildOwnerReferenceIndexDbKeyPrefix(*parent.ObjectKey)}).
+		Return(&types.Batch{KVs: indexKVs}, nil)
+	mockStore.EXPECT().BuildPutTxOp(mock.Anything).RunAndReturn(func(obj *sdkmeta.Object) (clientv3.Op, error) {
+		return clientv3.OpPut(objectKeyToDbKey(*obj.ObjectKey), "{}"), nil
+	})
+
+	var txnSizes []int
+	mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+	mockTxn := mocks.NewMockTxn(t)
+	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+	recordSize := func(ops ...clientv3.Op) { txnSizes = append(txnSizes, len(ops)) }
+	mockTxn.EXPECT().Then(anyOps(4)...).Run(recordSize).Return(mockTxn).Once()
+	mockTxn.EXPECT().Then(anyOps(2*childCount - 2*maxTxnOps)...).Run(recordSize).Return(mockTxn).Once()
+	mockTxn.EXPECT().Then(anyOps(maxTxnOps)...).Run(recordSize).Return(mockTxn).Times(2)
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
+	mockClient.EXPECT().Client().Return(mockEtcdClient)
+
+	// When: The parent is deleted
+	err := repo.Delete(ctx, *parent.ObjectKey, "test-lock")
+
+	// Then: The children are marked in bounded chunks before the parent is deleted on its own
+	require.NoError(t, err)
+	assert.Equal(t, []int{maxTxnOps, maxTxnOps, 2*childCount - 2*maxTxnOps, 4}, txnSizes)
+}
+
+func TestResourceRepository_Delete_LockLostDuringChunks(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+	ctx := context.Background()
+
+	// Given: A parent with more children than fit into one transaction and a lock taken over by another worker
+	parent, template := newPropagationTestObjects(sdkmeta.PropagationPolicyBackground)
+	mockClient.EXPECT().Get(ctx, "/schema/example.com/Network").Return(nil, NewNotFoundError("/schema/example.com/Network"))
+	mockStore.EXPECT().Get(ctx, *parent.ObjectKey).Return(parent, nil)
+
+	var indexKVs []types.KeyValue
+	for i := 0; i < maxTxnOps; i++ {
+		childKey := *template.ObjectKey
+		childKey.Name = fmt.Sprintf("main-%03d", i)
+		child := &sdkmeta.Object{
+			ObjectKey:  &childKey,
+			ObjectMeta: &sdkmeta.ObjectMeta{OwnerReferences: template.ObjectMeta.OwnerReferences},
+			SystemMeta: &sdkmeta.SystemMeta{UID: fmt.Sprintf("child-%d", i)},
+		}
+		mockStore.EXPECT().Get(ctx, childKey).Return(child, nil)
+		indexKVs = append(indexKVs, types.KeyValue{Key: buildOwnerReferenceIndexDbKey(*parent.ObjectKey, childKey)})
+	}
+	mockClient.EXPECT().List(ctx, types.Paging{Prefix: buildOwnerReferenceIndexDbKeyPrefix(*parent.ObjectKey)}).
+		Return(&types.Batch{KVs: indexKVs}, nil)
+	mockStore.EXPECT().BuildPutTxOp(mock.Anything).RunAndReturn(func(obj *sdkmeta.Object) (clientv3.Op, error) {
+		return clientv3.OpPut(objectKeyToDbKey(*obj.ObjectKey), "{}"), nil
+	})
+
+	mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+	mockTxn := mocks.NewMockTxn(t)
+	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(anyOps(maxTxnOps)...).Return(mockTxn).Once()
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: false}, nil).Once()
+	mockClient.EXPECT().Client().Return(mockEtcdClient)
+
+	// When: The parent is deleted
+	err := repo.Delete(ctx, *parent.ObjectKey, "test-lock")
+
+	// Then: The deletion stops after the failed chunk and the parent is kept
+	require.Error(t, err)
+}
+
+// anyOps matches a transaction of up to n operations
+func anyOps(n int) []interface{} {
+	args := make([]interface{}, n)
+	for i := range args {
+		args[i] = mock.Anything
+	}
+	return args
+}
+
+func TestChunkOps(t *testing.T) {
+	group := func(n int) []clientv3.Op {
+		ops := make([]clientv3.Op, n)
+		for i := range ops {
+			ops[i] = clientv3.OpDelete(fmt.Sprintf("/key/%d", i))
+		}
+		return ops
+	}
+
+	chunks := chunkOps([][]clientv3.Op{group(2), group(2), group(1), group(2)}, 4)
+
+	require.Len(t, chunks, 2)
+	assert.Len(t, chunks[0], 4)
+	assert.Len(t, chunks[1], 3)
+	assert.Empty(t, chunkOps(nil, 4))
+}
+
+func TestResourceRepository_ListDeletions(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+	ctx := context.Background()
+
+	// Given: Deletion records over two pages, marked in another order than their keys
+	newer := sdkmeta.ObjectKey{ObjectType: finalizerTestKey.ObjectType, Name: "a-newer"}
+	older := sdkmeta.ObjectKey{ObjectType: finalizerTestKey.ObjectType, Name: "b-older"}
+	mockClient.EXPECT().List(ctx, types.Paging{Prefix: "/deletion/", Limit: 1}).Return(&types.Batch{
+		Revision: 7,
+		KVs:      []types.KeyValue{{Key: deletionDbKey(newer), Value: []byte("2025-01-02T00:00:00Z")}},
+		More:     true,
+	}, nil)
+	mockClient.EXPECT().List(ctx, types.Paging{Prefix: "/deletion/", Limit: 1, Revision: 7, LastKey: deletionDbKey(newer)}).Return(&types.Batch{
+		Revision: 7,
+		KVs:      []types.KeyValue{{Key: deletionDbKey(older), Value: []byte("2025-01-01T00:00:00Z")}},
+	}, nil)
+
+	// When: The deletions are listed
+	records, revision, err := repo.ListDeletions(ctx, 1)
+
+	// Then: They are read at one revision and sorted oldest first
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), revision)
+	require.Len(t, records, 2)
+	assert.Equal(t, older, records[0].ObjectKey)
+	assert.Equal(t, newer, records[1].ObjectKey)
+}
+
+func TestResourceRepository_AcquireDeletion(t *testing.T) {
+	key := sdkmeta.ObjectKey{
+		ObjectType: sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Network", Namespace: "default"},
+		Name:       "main",
+	}
+
+	tests := []struct {
+		name     string
+		held     bool
+		vacant   bool
+		acquired bool
+	}{
+		{name: "held by the worker", held: true, acquired: true},
+		{name: "vacant", vacant: true, acquired: true},
+		{name: "held by another worker"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mocks.NewMockResourceStore(t)
+			mockClient := mocks.NewMockClientWrapper(t)
+			repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+			ctx := context.Background()
+
+			mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+			mockTxn := mocks.NewMockTxn(t)
+			mockClient.EXPECT().Client().Return(mockEtcdClient)
+			mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+			mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+
+			// Given: The lock in the state of the case
+			mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: tt.held}, nil).Once()
+			if !tt.held {
+				mockClient.EXPECT().GrantLease(ctx, int64(300)).Return(&clientv3.LeaseGrantResponse{ID: 7}, nil).Once()
+				mockTxn.EXPECT().Then(mock.Anything).Return(mockTxn).Once()
+				mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: tt.vacant}, nil).Once()
+			}
+			if !tt.held && !tt.vacant {
+				mockClient.EXPECT().RevokeLease(ctx, clientv3.LeaseID(7)).Return(&clientv3.LeaseRevokeResponse{}, nil).Once()
+			}
+
+			// When: Acquiring the lock
+			acquired, err := repo.AcquireDeletion(ctx, key, "test-lock", 5*time.Minute)
+
+			// Then: A lock held already keeps its lease and a lease that doesn't get the lock is revoked
+			require.NoError(t, err)
+			assert.Equal(t, tt.acquired, acquired)
+		})
+	}
+}
+
+func TestResourceRepository_DeletionLockLeaseRevoked(t *testing.T) {
+	key := sdkmeta.ObjectKey{
+		ObjectType: sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Network", Namespace: "default"},
+		Name:       "main",
+	}
+	lockResponse := &clientv3.TxnResponse{
+		Succeeded: true,
+		Responses: []*etcdserverpb.ResponseOp{
+			{Response: &etcdserverpb.ResponseOp_ResponseRange{ResponseRange: &etcdserverpb.RangeResponse{
+				Kvs: []*mvccpb.KeyValue{{Key: []byte(deletionLockDbKey(key)), Value: []byte("test-lock"), Lease: 7}},
+			}}},
+			{Response: &etcdserverpb.ResponseOp_ResponseDeleteRange{ResponseDeleteRange: &etcdserverpb.DeleteRangeResponse{Deleted: 1}}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		drop func(repo types.ResourceRepository, ctx context.Context) error
+	}{
+		{name: "released", drop: func(repo types.ResourceRepository, ctx context.Context) error {
+			return repo.ReleaseDeletion(ctx, key, "test-lock")
+		}},
+		{name: "deleted", drop: func(repo types.ResourceRepository, ctx context.Context) error {
+			return repo.Delete(ctx, key, "test-lock")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mocks.NewMockResourceStore(t)
+			mockClient := mocks.NewMockClientWrapper(t)
+			repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+			ctx := context.Background()
+
+			// Given: The lock is held with lease 7 by the worker
+			mockStore.EXPECT().Get(ctx, key).Return(nil, NewNotFoundError("resource not found")).Maybe()
+			mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+			mockTxn := mocks.NewMockTxn(t)
+			mockClient.EXPECT().Client().Return(mockEtcdClient)
+			mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+			mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+			mockTxn.EXPECT().Then(mock.Anything).Return(mockTxn).Maybe()
+			mockTxn.EXPECT().Then(mock.Anything, mock.Anything).Return(mockTxn).Maybe()
+			mockTxn.EXPECT().Then(mock.Anything, mock.Anything, mock.Anything).Return(mockTxn).Maybe()
+			mockTxn.EXPECT().Commit().Return(lockResponse, nil)
+
+			// Then: The lease of the lock is revoked with it
+			mockClient.EXPECT().RevokeLease(ctx, clientv3.LeaseID(7)).Return(&clientv3.LeaseRevokeResponse{}, nil).Once()
+
+			// When: The lock is dropped
+			require.NoError(t, tt.drop(repo, ctx))
+		})
+	}
+}
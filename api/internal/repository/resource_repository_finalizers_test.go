@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	internalerrors "github.com/tsamsiyu/themelio/api/internal/errors"
+	"github.com/tsamsiyu/themelio/api/internal/lib"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+var finalizerTestKey = sdkmeta.ObjectKey{
+	ObjectType: sdkmeta.ObjectType{
+		Group:     "example.com",
+		Version:   "v1",
+		Kind:      "TestResource",
+		Namespace: "default",
+	},
+	Name: "test-resource",
+}
+
+func newDeletingResource(finalizers ...string) *sdkmeta.Object {
+	deletionTime := time.Now()
+	key := finalizerTestKey
+	return &sdkmeta.Object{
+		ObjectKey: &key,
+		ObjectMeta: &sdkmeta.ObjectMeta{
+			Finalizers: finalizers,
+		},
+		SystemMeta: &sdkmeta.SystemMeta{
+			UID:          "test-uid",
+			ModRevision:  42,
+			DeletionTime: &deletionTime,
+		},
+		Spec: map[string]interface{}{
+			"replicas": 3,
+		},
+	}
+}
+
+func TestResourceRepository_Replace_DeletingResource(t *testing.T) {
+	tests := []struct {
+		name       string
+		update     func(obj *sdkmeta.Object)
+		wantErrMsg string
+	}{
+		{
+			name:       "spec change",
+			update:     func(obj *sdkmeta.Object) { obj.Spec = map[string]interface{}{"replicas": 5} },
+			wantErrMsg: "spec can't be changed while the resource is being deleted",
+		},
+		{
+			name: "new finalizer",
+			update: func(obj *sdkmeta.Object) {
+				obj.ObjectMeta.Finalizers = []string{"example.com/cleanup", "example.com/other"}
+			},
+			wantErrMsg: `finalizer "example.com/other" can't be added while the resource is being deleted`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mocks.NewMockResourceStore(t)
+			mockClient := mocks.NewMockClientWrapper(t)
+			repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+			ctx := context.Background()
+
+			// Given: A resource that is being deleted
+			mockStore.EXPECT().Get(ctx, finalizerTestKey).Return(newDeletingResource("example.com/cleanup"), nil)
+
+			// When: It is replaced with a change that isn't allowed anymore
+			newResource := newDeletingResource("example.com/cleanup")
+			tt.update(newResource)
+			err := repo.Replace(ctx, newResource, true)
+
+			// Then: The change is rejected without writing anything
+			var invalidInput *internalerrors.InvalidInputError
+			require.ErrorAs(t, err, &invalidInput)
+			assert.Equal(t, tt.wantErrMsg, invalidInput.Message)
+		})
+	}
+}
+
+func TestResourceRepository_RemoveFinalizer(t *testing.T) {
+	tests := []struct {
+		name          string
+		finalizers    []string
+		wantRemaining []string
+		wantRequeued  bool
+	}{
+		{
+			name:          "other finalizers remain",
+			finalizers:    []string{"example.com/cleanup", "example.com/other"},
+			wantRemaining: []string{"example.com/other"},
+		},
+		{
+			name:         "last finalizer",
+			finalizers:   []string{"example.com/cleanup"},
+			wantRequeued: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mocks.NewMockResourceStore(t)
+			mockClient := mocks.NewMockClientWrapper(t)
+			repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+			ctx := context.Background()
+			expectNoIndexedFields(mockClient, ctx)
+
+			// Given: A resource that is being deleted and waits for finalizers
+			mockStore.EXPECT().Get(ctx, finalizerTestKey).Return(newDeletingResource(tt.finalizers...), nil)
+			mockStore.EXPECT().BuildPutTxOp(mock.Anything).Return(clientv3.OpPut("/example.com/v1/TestResource/default/test-resource", "{}"), nil)
+
+			var committed []clientv3.Op
+			mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+			mockTxn := mocks.NewMockTxn(t)
+			mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+			mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+			thenArgs := []interface{}{mock.Anything}
+			if tt.wantRequeued {
+				thenArgs = append(thenArgs, mock.Anything)
+			}
+			mockTxn.EXPECT().Then(thenArgs...).Run(func(ops ...clientv3.Op) { committed = ops }).Return(mockTxn)
+			mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
+			mockClient.EXPECT().Client().Return(mockEtcdClient)
+
+			// When: One of its finalizers is removed
+			obj, err := repo.RemoveFinalizer(ctx, finalizerTestKey, "example.com/cleanup")
+
+			// Then: The finalizer is gone and the deletion is queued again only once none is left
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRemaining, obj.ObjectMeta.Finalizers)
+
+			requeued := false
+			for _, op := range committed {
+				if string(op.KeyBytes()) == deletionDbKey(finalizerTestKey) {
+					requeued = true
+				}
+			}
+			assert.Equal(t, tt.wantRequeued, requeued)
+		})
+	}
+}
+
+func TestResourceRepository_RemoveFinalizer_Missing(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+	ctx := context.Background()
+
+	// Given: A resource without the finalizer
+	mockStore.EXPECT().Get(ctx, finalizerTestKey).Return(newDeletingResource("example.com/other"), nil)
+
+	// When: The finalizer is removed
+	obj, err := repo.RemoveFinalizer(ctx, finalizerTestKey, "example.com/cleanup")
+
+	// Then: The stored resource is returned without a write
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/other"}, obj.ObjectMeta.Finalizers)
+}
+
+func TestResourceRepository_RemoveFinalizer_RetriesConflict(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
+
+	// Given: A resource that is modified concurrently with the first removal
+	mockStore.EXPECT().Get(ctx, finalizerTestKey).RunAndReturn(func(context.Context, sdkmeta.ObjectKey) (*sdkmeta.Object, error) {
+		return newDeletingResource("example.com/cleanup", "example.com/other"), nil
+	}).Times(2)
+	mockStore.EXPECT().BuildPutTxOp(mock.Anything).Return(clientv3.OpPut("/example.com/v1/TestResource/default/test-resource", "{}"), nil)
+
+	mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+	mockTxn := mocks.NewMockTxn(t)
+	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: false}, nil).Once()
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil).Once()
+	mockClient.EXPECT().Client().Return(mockEtcdClient)
+
+	// When: The finalizer is removed
+	obj, err := repo.RemoveFinalizer(ctx, finalizerTestKey, "example.com/cleanup")
+
+	// Then: The removal is retried on the fresh object
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/other"}, obj.ObjectMeta.Finalizers)
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/lib"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+func TestResourceRepository_CheckOwnerReference(t *testing.T) {
+	otherOwnerType := sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Router", Namespace: "default"}
+
+	tests := []struct {
+		name         string
+		childMissing bool
+		dropRef      bool
+		otherOwner   bool
+		replaced     bool
+		legacyOwner  bool
+		parentGone   bool
+		wantCheck    types.OwnerReferenceCheck
+		wantKeys     []string
+	}{
+		{name: "owner exists", wantCheck: types.OwnerReferenceValid},
+		{name: "owner stored without uid", legacyOwner: true, wantCheck: types.OwnerReferenceValid},
+		{name: "child gone", childMissing: true, wantCheck: types.OwnerReferenceStale},
+		{name: "reference removed", dropRef: true, wantCheck: types.OwnerReferenceStale},
+		{name: "owner gone", parentGone: true, wantCheck: types.OwnerReferenceOrphaned},
+		{name: "owner replaced", replaced: true, wantCheck: types.OwnerReferenceOrphaned},
+		{name: "owner gone with another owner", parentGone: true, otherOwner: true, wantCheck: types.OwnerReferenceDropped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mocks.NewMockResourceStore(t)
+			mockClient := mocks.NewMockClientWrapper(t)
+			repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+			ctx := context.Background()
+
+			// Given: An index entry and the child and the owner created through Replace it links
+			parent, child := newPropagationTestObjects(sdkmeta.PropagationPolicyBackground)
+			parent = createThroughReplace(t, parent)
+			child.SystemMeta.ModRevision = 11
+			child.ObjectMeta.OwnerReferences[0].UID = parent.SystemMeta.UID
+			if tt.replaced {
+				parent = createThroughReplace(t, parent)
+			}
+			if tt.legacyOwner {
+				parent.SystemMeta.UID = ""
+			}
+			if tt.dropRef {
+				child.ObjectMeta.OwnerReferences = nil
+			}
+			if tt.otherOwner {
+				child.ObjectMeta.OwnerReferences = append(child.ObjectMeta.OwnerReferences,
+					sdkmeta.OwnerReference{TypeMeta: &otherOwnerType, Name: "edge", UID: "router-uid"})
+			}
+			entry := types.OwnerReferenceEntry{Parent: *parent.ObjectKey, Child: *child.ObjectKey}
+
+			if tt.childMissing {
+				mockStore.EXPECT().Get(ctx, entry.Child).Return(nil, NewNotFoundError(objectKeyToDbKey(entry.Child)))
+			} else {
+				mockStore.EXPECT().Get(ctx, entry.Child).Return(child, nil)
+			}
+			if !tt.childMissing && !tt.dropRef {
+				if tt.parentGone {
+					mockStore.EXPECT().Get(ctx, entry.Parent).Return(nil, NewNotFoundError(objectKeyToDbKey(entry.Parent)))
+				} else {
+					mockStore.EXPECT().Get(ctx, entry.Parent).Return(parent, nil)
+				}
+			}
+
+			var written *sdkmeta.Object
+			var committed []clientv3.Op
+			if tt.wantCheck != types.OwnerReferenceValid {
+				if tt.wantCheck != types.OwnerReferenceStale {
+					mockStore.EXPECT().BuildPutTxOp(child).RunAndReturn(func(obj *sdkmeta.Object) (clientv3.Op, error) {
+						written = obj
+						return clientv3.OpPut(objectKeyToDbKey(*obj.ObjectKey), "{}"), nil
+					})
+				}
+
+				mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+				mockTxn := mocks.NewMockTxn(t)
+				mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+				mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+				mockTxn.EXPECT().Then(mock.Anything, mock.Anything).
+					Run(func(ops ...clientv3.Op) { committed = ops }).Return(mockTxn)
+				mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
+				mockClient.EXPECT().Client().Return(mockEtcdClient)
+			}
+
+			// When: The entry is checked
+			check, err := repo.CheckOwnerReference(ctx, entry)
+
+			// Then: The entry, the child or nothing is changed by what is gone
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCheck, check)
+
+			var keys []string
+			for _, op := range committed {
+				keys = append(keys, string(op.KeyBytes()))
+			}
+			indexKey := buildOwnerReferenceIndexDbKey(entry.Parent, entry.Child)
+			switch tt.wantCheck {
+			case types.OwnerReferenceStale:
+				assert.Equal(t, []string{indexKey}, keys)
+			case types.OwnerReferenceOrphaned:
+				assert.Contains(t, keys, deletionDbKey(entry.Child))
+				assert.NotNil(t, written.SystemMeta.DeletionTime)
+			case types.OwnerReferenceDropped:
+				assert.Contains(t, keys, indexKey)
+				assert.NotContains(t, keys, deletionDbKey(entry.Child))
+				require.Len(t, written.ObjectMeta.OwnerReferences, 1)
+				assert.Equal(t, "router-uid", written.ObjectMeta.OwnerReferences[0].UID)
+			}
+		})
+	}
+}
+
+func TestResourceRepository_CheckOwnerReference_Conflict(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+	ctx := context.Background()
+
+	// Given: A child of a gone owner that is modified while it is checked
+	parent, child := newPropagationTestObjects(sdkmeta.PropagationPolicyBackground)
+	entry := types.OwnerReferenceEntry{Parent: *parent.ObjectKey, Child: *child.ObjectKey}
+	mockStore.EXPECT().Get(ctx, entry.Child).Return(child, nil)
+	mockStore.EXPECT().Get(ctx, entry.Parent).Return(nil, NewNotFoundError(objectKeyToDbKey(entry.Parent)))
+	mockStore.EXPECT().BuildPutTxOp(child).Return(clientv3.OpPut(objectKeyToDbKey(entry.Child), "{}"), nil)
+
+	mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+	mockTxn := mocks.NewMockTxn(t)
+	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything, mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: false}, nil)
+	mockClient.EXPECT().Client().Return(mockEtcdClient)
+
+	// When: The entry is checked
+	_, err := repo.CheckOwnerReference(ctx, entry)
+
+	// Then: A conflict is returned so the next scan checks it again
+	assert.True(t, IsConflictError(err))
+}
+
+func TestResourceRepository_ListOwnerReferences(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+	ctx := context.Background()
+
+	// Given: A page of the owner reference index with an invalid key
+	parent, child := newPropagationTestObjects(sdkmeta.PropagationPolicyBackground)
+	indexKey := buildOwnerReferenceIndexDbKey(*parent.ObjectKey, *child.ObjectKey)
+	mockClient.EXPECT().List(ctx, types.Paging{Prefix: "/index/owner-reference/", LastKey: "/index/owner-reference/a", Limit: 2}).
+		Return(&types.Batch{KVs: []types.KeyValue{{Key: indexKey}, {Key: "/index/owner-reference/broken"}}, More: true}, nil)
+
+	// When: The page is listed
+	batch, err := repo.ListOwnerReferences(ctx, "/index/owner-reference/a", 2)
+
+	// Then: The valid entries are returned and the walk continues after the last key
+	require.NoError(t, err)
+	assert.Equal(t, []types.OwnerReferenceEntry{{Parent: *parent.ObjectKey, Child: *child.ObjectKey}}, batch.Entries)
+	assert.Equal(t, "/index/owner-reference/broken", batch.LastKey)
+	assert.True(t, batch.More)
+}
+
+// createThroughReplace creates the object with Replace and returns the object it is stored as
+func createThroughReplace(t *testing.T, obj *sdkmeta.Object) *sdkmeta.Object {
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+	ctx := context.Background()
+
+	created := &sdkmeta.Object{
+		ObjectKey:  obj.ObjectKey,
+		ObjectMeta: &sdkmeta.ObjectMeta{},
+		SystemMeta: &sdkmeta.SystemMeta{},
+	}
+
+	var stored *sdkmeta.Object
+	mockStore.EXPECT().Get(ctx, *obj.ObjectKey).Return(nil, NewNotFoundError(objectKeyToDbKey(*obj.ObjectKey)))
+	mockStore.EXPECT().BuildPutTxOp(created).RunAndReturn(func(obj *sdkmeta.Object) (clientv3.Op, error) {
+		stored = obj
+		return clientv3.OpPut(objectKeyToDbKey(*obj.ObjectKey), "{}"), nil
+	})
+	mockClient.EXPECT().Get(ctx, mock.Anything).Return(nil, NewNotFoundError("schema")).Maybe()
+
+	mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+	mockTxn := mocks.NewMockTxn(t)
+	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
+	mockClient.EXPECT().Client().Return(mockEtcdClient)
+
+	require.NoError(t, repo.Replace(ctx, created, false))
+	require.NotEmpty(t, stored.SystemMeta.UID)
+	return stored
+}
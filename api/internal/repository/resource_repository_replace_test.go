@@ -25,6 +25,7 @@ func TestResourceRepository_Replace_NewResource(t *testing.T) {
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -63,8 +64,10 @@ func TestResourceRepository_Replace_NewResource(t *testing.T) {
 	// When: Creating the new resource
 	err := repo.Replace(ctx, resource, false)
 
-	// Then: The creation should succeed
+	// Then: The creation should succeed with a uid assigned by the server
 	assert.NoError(t, err)
+	assert.NotEmpty(t, resource.SystemMeta.UID)
+	assert.NotEqual(t, "new-uid", resource.SystemMeta.UID)
 }
 
 func TestResourceRepository_Replace_NewResource_WithOwnerReferences(t *testing.T) {
@@ -77,6 +80,7 @@ func TestResourceRepository_Replace_NewResource_WithOwnerReferences(t *testing.T
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -142,6 +146,7 @@ func TestResourceRepository_Replace_UpdateExistingResource(t *testing.T) {
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -223,8 +228,9 @@ func TestResourceRepository_Replace_UpdateExistingResource(t *testing.T) {
 	// When: Updating the resource with new owner references
 	err := repo.Replace(ctx, newResource, false)
 
-	// Then: The update should succeed
+	// Then: The update should succeed and keep the uid
 	assert.NoError(t, err)
+	assert.Equal(t, "test-uid", newResource.SystemMeta.UID)
 }
 
 func TestResourceRepository_Replace_NoOwnerReferenceChanges(t *testing.T) {
@@ -237,6 +243,7 @@ func TestResourceRepository_Replace_NoOwnerReferenceChanges(t *testing.T) {
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -332,6 +339,7 @@ func TestResourceRepository_Replace_WithLabelsChanges(t *testing.T) {
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -410,6 +418,7 @@ func TestResourceRepository_Replace_NewResourceWithLabels(t *testing.T) {
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -466,6 +475,7 @@ func TestResourceRepository_Replace_OptimisticLocking(t *testing.T) {
 	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
 
 	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
 	key := sdkmeta.ObjectKey{
 		ObjectType: sdkmeta.ObjectType{
 			Group:     "example.com",
@@ -529,3 +539,218 @@ func TestResourceRepository_Replace_OptimisticLocking(t *testing.T) {
 	// Then: The update should succeed
 	assert.NoError(t, err)
 }
+
+func TestResourceRepository_Replace_OptimisticLockingConflict(t *testing.T) {
+	logger := zap.NewNop()
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	backoffManager := &lib.BackoffManager{}
+	watchConfig := types.WatchConfig{}
+
+	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
+
+	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
+	key := sdkmeta.ObjectKey{
+		ObjectType: sdkmeta.ObjectType{
+			Group:     "example.com",
+			Version:   "v1",
+			Kind:      "TestResource",
+			Namespace: "default",
+		},
+		Name: "test-resource",
+	}
+
+	existingResource := &sdkmeta.Object{
+		ObjectKey:  &key,
+		ObjectMeta: &sdkmeta.ObjectMeta{},
+		SystemMeta: &sdkmeta.SystemMeta{UID: "test-uid", ModRevision: 124},
+		Spec:       map[string]interface{}{"replicas": 1},
+		Status:     map[string]interface{}{"ready": true},
+	}
+
+	// New resource based on an outdated version
+	newResource := &sdkmeta.Object{
+		ObjectKey:  &key,
+		ObjectMeta: &sdkmeta.ObjectMeta{},
+		SystemMeta: &sdkmeta.SystemMeta{ModRevision: 123},
+		Spec:       map[string]interface{}{"replicas": 3},
+	}
+
+	// Given: The stored resource was modified after the client read it
+	mockStore.EXPECT().Get(ctx, key).Return(existingResource, nil)
+	mockStore.EXPECT().BuildPutTxOp(newResource).Return(clientv3.OpPut("/example.com/v1/TestResource/default/test-resource", "{}"), nil)
+
+	mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+	mockTxn := mocks.NewMockTxn(t)
+	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: false}, nil)
+	mockClient.EXPECT().Client().Return(mockEtcdClient)
+
+	// When: Updating the resource with optimistic locking
+	err := repo.Replace(ctx, newResource, true)
+
+	// Then: A conflict is reported and the stored status is kept
+	assert.True(t, IsConflictError(err))
+	assert.Equal(t, existingResource.Status, newResource.Status)
+	assert.Equal(t, "test-uid", newResource.SystemMeta.UID)
+}
+
+func TestResourceRepository_ReplaceStatus(t *testing.T) {
+	logger := zap.NewNop()
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	backoffManager := &lib.BackoffManager{}
+	watchConfig := types.WatchConfig{}
+
+	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
+
+	ctx := context.Background()
+	expectNoIndexedFields(mockClient, ctx)
+	key := sdkmeta.ObjectKey{
+		ObjectType: sdkmeta.ObjectType{
+			Group:     "example.com",
+			Version:   "v1",
+			Kind:      "TestResource",
+			Namespace: "default",
+		},
+		Name: "test-resource",
+	}
+
+	existingResource := &sdkmeta.Object{
+		ObjectKey: &key,
+		ObjectMeta: &sdkmeta.ObjectMeta{
+			Labels: map[string]string{"app": "test-app"},
+		},
+		SystemMeta: &sdkmeta.SystemMeta{UID: "test-uid", ModRevision: 5},
+		Spec:       map[string]interface{}{"replicas": 1},
+	}
+
+	// Status update that also tries to change the spec and labels
+	statusUpdate := &sdkmeta.Object{
+		ObjectKey: &key,
+		ObjectMeta: &sdkmeta.ObjectMeta{
+			Labels: map[string]string{"app": "other-app"},
+		},
+		Spec:   map[string]interface{}{"replicas": 3},
+		Status: map[string]interface{}{"readyReplicas": 1},
+	}
+
+	// Given: An existing resource
+	mockStore.EXPECT().Get(ctx, key).Return(existingResource, nil)
+	mockStore.EXPECT().BuildPutTxOp(statusUpdate).Return(clientv3.OpPut("/example.com/v1/TestResource/default/test-resource", "{}"), nil)
+
+	mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+	mockTxn := mocks.NewMockTxn(t)
+	mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+	mockTxn.EXPECT().If(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Then(mock.Anything).Return(mockTxn)
+	mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
+	mockClient.EXPECT().Client().Return(mockEtcdClient)
+
+	// When: Replacing the status
+	err := repo.ReplaceStatus(ctx, statusUpdate, true)
+
+	// Then: Only the status is taken from the update
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"readyReplicas": 1}, statusUpdate.Status)
+	assert.Equal(t, existingResource.Spec, statusUpdate.Spec)
+	assert.Equal(t, existingResource.ObjectMeta, statusUpdate.ObjectMeta)
+	assert.Equal(t, "test-uid", statusUpdate.SystemMeta.UID)
+	assert.Equal(t, existingResource.SystemMeta.Generation, statusUpdate.SystemMeta.Generation)
+}
+
+func TestResourceRepository_Replace_Generation(t *testing.T) {
+	key := sdkmeta.ObjectKey{
+		ObjectType: sdkmeta.ObjectType{
+			Group:     "example.com",
+			Version:   "v1",
+			Kind:      "TestResource",
+			Namespace: "default",
+		},
+		Name: "test-resource",
+	}
+
+	tests := []struct {
+		name           string
+		existing       *sdkmeta.Object
+		spec           interface{}
+		wantGeneration int64
+	}{
+		{
+			name:           "new resource",
+			spec:           map[string]interface{}{"replicas": 1},
+			wantGeneration: 1,
+		},
+		{
+			name: "spec changed",
+			existing: &sdkmeta.Object{
+				ObjectKey:  &key,
+				ObjectMeta: &sdkmeta.ObjectMeta{},
+				SystemMeta: &sdkmeta.SystemMeta{Generation: 2},
+				Spec:       map[string]interface{}{"replicas": float64(1)},
+			},
+			spec:           map[string]interface{}{"replicas": 3},
+			wantGeneration: 3,
+		},
+		{
+			name: "spec unchanged",
+			existing: &sdkmeta.Object{
+				ObjectKey:  &key,
+				ObjectMeta: &sdkmeta.ObjectMeta{},
+				SystemMeta: &sdkmeta.SystemMeta{Generation: 2},
+				Spec:       map[string]interface{}{"replicas": float64(1)},
+			},
+			spec:           map[string]interface{}{"replicas": 1},
+			wantGeneration: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mocks.NewMockResourceStore(t)
+			mockClient := mocks.NewMockClientWrapper(t)
+			repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+
+			ctx := context.Background()
+			expectNoIndexedFields(mockClient, ctx)
+			resource := &sdkmeta.Object{
+				ObjectKey: &key,
+				ObjectMeta: &sdkmeta.ObjectMeta{
+					Labels: map[string]string{"app": "test-app"},
+				},
+				Spec: tt.spec,
+			}
+
+			// Given: The stored resource, if any
+			if tt.existing != nil {
+				mockStore.EXPECT().Get(ctx, key).Return(tt.existing, nil)
+			} else {
+				mockStore.EXPECT().Get(ctx, key).Return(nil, NewNotFoundError("resource not found"))
+			}
+			mockStore.EXPECT().BuildPutTxOp(resource).Return(clientv3.OpPut("/example.com/v1/TestResource/default/test-resource", "{}"), nil)
+
+			mockEtcdClient := mocks.NewMockEtcdClientInterface(t)
+			mockTxn := mocks.NewMockTxn(t)
+			mockEtcdClient.EXPECT().Txn(ctx).Return(mockTxn)
+			mockTxn.EXPECT().Then(mock.Anything, mock.Anything).Return(mockTxn)
+			mockTxn.EXPECT().Commit().Return(&clientv3.TxnResponse{Succeeded: true}, nil)
+			mockClient.EXPECT().Client().Return(mockEtcdClient)
+
+			// When: Replacing the resource
+			err := repo.Replace(ctx, resource, false)
+
+			// Then: The generation is only incremented for spec changes
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantGeneration, resource.SystemMeta.Generation)
+		})
+	}
+}
+
+// expectNoIndexedFields answers the lookup of indexed fields as for a type without a schema
+func expectNoIndexedFields(mockClient *mocks.MockClientWrapper, ctx context.Context) {
+	mockClient.EXPECT().Get(ctx, "/schema/example.com/TestResource").
+		Return(nil, NewNotFoundError("/schema/example.com/TestResource")).Maybe()
+}
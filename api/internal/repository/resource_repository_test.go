@@ -12,6 +12,7 @@ import (
 	"github.com/tsamsiyu/themelio/api/internal/lib"
 	"github.com/tsamsiyu/themelio/api/internal/repository/types"
 	"github.com/tsamsiyu/themelio/api/mocks"
+	sdklabels "github.com/tsamsiyu/themelio/sdk/pkg/labels"
 	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
 )
 
@@ -92,12 +93,199 @@ func TestResourceRepository_List(t *testing.T) {
 	}
 
 	// Mock expectations
-	mockStore.EXPECT().List(ctx, objType, (*types.Paging)(nil)).Return(expectedResources, nil)
+	mockStore.EXPECT().List(ctx, objType, &types.Paging{}).Return(expectedResources, nil)
 
 	// Test
-	result, err := repo.List(ctx, objType)
+	result, err := repo.List(ctx, objType, types.ListOptions{})
 	assert.NoError(t, err)
-	assert.Equal(t, expectedResources.Objects, result)
+	assert.Equal(t, expectedResources, result)
+}
+
+func TestResourceRepository_List_Pages(t *testing.T) {
+	logger := zap.NewNop()
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	backoffManager := &lib.BackoffManager{}
+	watchConfig := types.WatchConfig{}
+
+	repo := NewResourceRepository(logger, mockStore, mockClient, watchConfig, backoffManager)
+
+	ctx := context.Background()
+	objType := &sdkmeta.ObjectType{
+		Group:     "example.com",
+		Version:   "v1",
+		Kind:      "TestResource",
+		Namespace: "default",
+	}
+	newObject := func(name string) *sdkmeta.Object {
+		return &sdkmeta.Object{
+			ObjectKey: &sdkmeta.ObjectKey{ObjectType: *objType, Name: name},
+		}
+	}
+
+	// Given: Two objects listed one per page while the store moves on to a newer revision
+	mockStore.EXPECT().List(ctx, objType, &types.Paging{Limit: 1}).Return(&types.ObjectBatch{
+		Revision: 10,
+		Objects:  []*sdkmeta.Object{newObject("a")},
+		More:     true,
+		LastKey:  "/example.com/v1/TestResource/default/a",
+	}, nil)
+	mockStore.EXPECT().List(ctx, objType, &types.Paging{
+		Limit:    1,
+		LastKey:  "/example.com/v1/TestResource/default/a",
+		Revision: 10,
+	}).Return(&types.ObjectBatch{
+		Revision: 12,
+		Objects:  []*sdkmeta.Object{newObject("b")},
+	}, nil)
+
+	// When: Listing the first page
+	first, err := repo.List(ctx, objType, types.ListOptions{Limit: 1})
+
+	// Then: It points to the next page
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first.Continue)
+
+	// When: Listing the next page
+	second, err := repo.List(ctx, objType, types.ListOptions{Limit: 1, Continue: first.Continue})
+
+	// Then: It is the last page, reported at the revision of the first one
+	assert.NoError(t, err)
+	assert.Empty(t, second.Continue)
+	assert.Equal(t, int64(10), second.Revision)
+	assert.Equal(t, "b", second.Objects[0].ObjectKey.Name)
+}
+
+func TestResourceRepository_List_PageOfOtherKind(t *testing.T) {
+	mockClient := mocks.NewMockClientWrapper(t)
+	store := NewResourceStore(zap.NewNop(), mockClient)
+	repo := NewResourceRepository(zap.NewNop(), store, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+	ctx := context.Background()
+
+	// Given: A kind whose prefix is shared by a longer kind filling the whole first page
+	objType := &sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "Net"}
+	mockClient.EXPECT().List(ctx, types.Paging{Prefix: "/example.com/v1/Net", Limit: 2}).Return(&types.Batch{
+		Revision: 10,
+		KVs: []types.KeyValue{
+			{Key: "/example.com/v1/Network/a", Value: []byte(`{}`)},
+			{Key: "/example.com/v1/Network/b", Value: []byte(`{}`)},
+		},
+		More: true,
+	}, nil)
+	mockClient.EXPECT().List(ctx, types.Paging{Prefix: "/example.com/v1/Net", Limit: 2, LastKey: "/example.com/v1/Network/b", Revision: 10}).
+		Return(&types.Batch{
+			Revision: 12,
+			KVs: []types.KeyValue{
+				{Key: "/example.com/v1/Net/main", Value: []byte(`{"key":{"group":"example.com","version":"v1","kind":"Net","name":"main"},"meta":{},"system":{}}`)},
+			},
+		}, nil)
+
+	// When: Listing the first page
+	first, err := repo.List(ctx, objType, types.ListOptions{Limit: 2})
+
+	// Then: It holds no object but still points to the next page
+	assert.NoError(t, err)
+	assert.Empty(t, first.Objects)
+	assert.True(t, first.More)
+	assert.NotEmpty(t, first.Continue)
+
+	// When: Listing the next page
+	second, err := repo.List(ctx, objType, types.ListOptions{Limit: 2, Continue: first.Continue})
+
+	// Then: The object of the kind is found after the skipped keys
+	assert.NoError(t, err)
+	assert.False(t, second.More)
+	assert.Len(t, second.Objects, 1)
+}
+
+func TestResourceRepository_List_ForeignContinueToken(t *testing.T) {
+	repo := NewResourceRepository(zap.NewNop(), mocks.NewMockResourceStore(t), mocks.NewMockClientWrapper(t), types.WatchConfig{}, &lib.BackoffManager{})
+
+	token, err := encodeContinueToken(10, "/example.com/v1/OtherResource/default/a")
+	assert.NoError(t, err)
+
+	objType := &sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: "default"}
+	_, err = repo.List(context.Background(), objType, types.ListOptions{Limit: 1, Continue: token})
+
+	assert.Error(t, err)
+}
+
+func TestResourceRepository_List_LabelIndex(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	mockClient := mocks.NewMockClientWrapper(t)
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mockClient, types.WatchConfig{}, &lib.BackoffManager{})
+
+	ctx := context.Background()
+	objType := &sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: "default"}
+	newObject := func(name string, tier string) *sdkmeta.Object {
+		return &sdkmeta.Object{
+			ObjectKey:  &sdkmeta.ObjectKey{ObjectType: *objType, Name: name},
+			ObjectMeta: &sdkmeta.ObjectMeta{Labels: map[string]string{"env": "prod", "tier": tier}},
+		}
+	}
+	newKey := func(name string) sdkmeta.ObjectKey {
+		return sdkmeta.ObjectKey{ObjectType: *objType, Name: name}
+	}
+	selector, err := sdklabels.Parse("env=prod,tier!=db")
+	assert.NoError(t, err)
+
+	// Given: The env index points to objects of two namespaces, one of the matching objects is a db
+	indexPrefix := "/index/label/example.com/v1/TestResource/env/prod/"
+	indexBatch := &types.Batch{Revision: 10, KVs: []types.KeyValue{
+		{Key: indexPrefix + "default/a"},
+		{Key: indexPrefix + "default/b"},
+		{Key: indexPrefix + "default/c"},
+		{Key: indexPrefix + "other/a"},
+	}}
+	mockClient.EXPECT().List(ctx, types.Paging{Prefix: indexPrefix}).Return(indexBatch, nil)
+	mockClient.EXPECT().List(ctx, types.Paging{Prefix: indexPrefix, Revision: 10}).Return(indexBatch, nil)
+	mockStore.EXPECT().GetMany(ctx, []sdkmeta.ObjectKey{newKey("a"), newKey("b"), newKey("c")}, int64(10)).
+		Return(&types.ObjectBatch{Revision: 10, Objects: []*sdkmeta.Object{newObject("a", "web"), newObject("b", "db"), newObject("c", "web")}}, nil)
+	mockStore.EXPECT().GetMany(ctx, []sdkmeta.ObjectKey{newKey("b"), newKey("c")}, int64(10)).
+		Return(&types.ObjectBatch{Revision: 10, Objects: []*sdkmeta.Object{newObject("b", "db"), newObject("c", "web")}}, nil)
+
+	// When: Listing the first page
+	first, err := repo.List(ctx, objType, types.ListOptions{Limit: 1, LabelSelector: selector})
+
+	// Then: Only the objects of the namespace are read and the page points to the rest
+	assert.NoError(t, err)
+	assert.Len(t, first.Objects, 1)
+	assert.Equal(t, "a", first.Objects[0].ObjectKey.Name)
+	assert.NotEmpty(t, first.Continue)
+
+	// When: Listing the next page
+	second, err := repo.List(ctx, objType, types.ListOptions{Limit: 1, Continue: first.Continue, LabelSelector: selector})
+
+	// Then: The db object is skipped and there are no more pages
+	assert.NoError(t, err)
+	assert.Len(t, second.Objects, 1)
+	assert.Equal(t, "c", second.Objects[0].ObjectKey.Name)
+	assert.Empty(t, second.Continue)
+	assert.Equal(t, int64(10), second.Revision)
+}
+
+func TestResourceRepository_List_UnindexedLabelSelector(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	repo := NewResourceRepository(zap.NewNop(), mockStore, mocks.NewMockClientWrapper(t), types.WatchConfig{}, &lib.BackoffManager{})
+
+	ctx := context.Background()
+	objType := &sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: "default"}
+	selector, err := sdklabels.Parse("!canary")
+	assert.NoError(t, err)
+
+	// Given: A selector the label index can't answer
+	mockStore.EXPECT().List(ctx, objType, &types.Paging{}).Return(&types.ObjectBatch{Revision: 5, Objects: []*sdkmeta.Object{
+		{ObjectKey: &sdkmeta.ObjectKey{ObjectType: *objType, Name: "a"}, ObjectMeta: &sdkmeta.ObjectMeta{Labels: map[string]string{"canary": "true"}}},
+		{ObjectKey: &sdkmeta.ObjectKey{ObjectType: *objType, Name: "b"}, ObjectMeta: &sdkmeta.ObjectMeta{}},
+	}}, nil)
+
+	// When: Listing
+	result, err := repo.List(ctx, objType, types.ListOptions{LabelSelector: selector})
+
+	// Then: The scanned objects are filtered
+	assert.NoError(t, err)
+	assert.Len(t, result.Objects, 1)
+	assert.Equal(t, "b", result.Objects[0].ObjectKey.Name)
 }
 
 func TestResourceRepository_MarkDeleted(t *testing.T) {
@@ -144,6 +332,6 @@ func TestResourceRepository_MarkDeleted(t *testing.T) {
 	mockClient.EXPECT().Client().Return(mockEtcdClient)
 
 	// Test
-	err := repo.MarkDeleted(ctx, key)
+	err := repo.MarkDeleted(ctx, key, types.DeleteOptions{})
 	assert.NoError(t, err)
 }
@@ -46,16 +46,44 @@ func (s *resourceStore) Get(ctx context.Context, key sdkmeta.ObjectKey) (*sdkmet
 	return s.unmarshalResource(kv)
 }
 
+func (s *resourceStore) GetMany(ctx context.Context, keys []sdkmeta.ObjectKey, revision int64) (*types.ObjectBatch, error) {
+	dbKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		dbKeys = append(dbKeys, objectKeyToDbKey(key))
+	}
+
+	batch, err := s.clientWrapper.GetMany(ctx, dbKeys, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]*sdkmeta.Object, 0, len(batch.KVs))
+	for _, kv := range batch.KVs {
+		object, err := s.unmarshalResource(&kv)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+
+	return &types.ObjectBatch{
+		Revision: batch.Revision,
+		Objects:  objects,
+	}, nil
+}
+
 func (s *resourceStore) Delete(ctx context.Context, key sdkmeta.ObjectKey) error {
 	keyStr := objectKeyToDbKey(key)
 	return s.clientWrapper.Delete(ctx, keyStr)
 }
 
+// List reads a page of the objects of the type, keys of other types under the same prefix are skipped
+// so a page may come out short
 func (s *resourceStore) List(ctx context.Context, objType *sdkmeta.ObjectType, paging *types.Paging) (*types.ObjectBatch, error) {
 	if paging == nil {
-		paging = &types.Paging{Prefix: objectTypeToDbKey(objType)}
+		paging = &types.Paging{Prefix: typePrefixDbKey(objType)}
 	} else {
-		paging.Prefix = objectTypeToDbKey(objType)
+		paging.Prefix = typePrefixDbKey(objType)
 	}
 
 	batch, err := s.clientWrapper.List(ctx, *paging)
@@ -65,6 +93,10 @@ func (s *resourceStore) List(ctx context.Context, objType *sdkmeta.ObjectType, p
 
 	var objects []*sdkmeta.Object
 	for _, kv := range batch.KVs {
+		if key, ok := parseResourceDbKey(kv.Key); !ok || !typeContainsKey(objType, key) {
+			continue
+		}
+
 		object, err := s.unmarshalResource(&kv)
 		if err != nil {
 			return nil, err
@@ -72,9 +104,16 @@ func (s *resourceStore) List(ctx context.Context, objType *sdkmeta.ObjectType, p
 		objects = append(objects, object)
 	}
 
+	var lastKey string
+	if len(batch.KVs) > 0 {
+		lastKey = batch.KVs[len(batch.KVs)-1].Key
+	}
+
 	return &types.ObjectBatch{
 		Revision: batch.Revision,
 		Objects:  objects,
+		More:     batch.More,
+		LastKey:  lastKey,
 	}, nil
 }
 
@@ -96,7 +135,7 @@ func (s *resourceStore) BuildPutTxOp(resource *sdkmeta.Object) (clientv3.Op, err
 }
 
 func (s *resourceStore) Watch(ctx context.Context, objType *sdkmeta.ObjectType, eventChan chan<- types.WatchEvent, revision ...int64) error {
-	keyStr := objectTypeToDbKey(objType)
+	keyStr := typePrefixDbKey(objType)
 
 	// Use revision if provided, otherwise start from beginning
 	var startRevision int64 = 0
@@ -104,13 +143,24 @@ func (s *resourceStore) Watch(ctx context.Context, objType *sdkmeta.ObjectType,
 		startRevision = revision[0]
 	}
 
-	go s.watchResources(ctx, keyStr, eventChan, startRevision)
+	go s.watchResources(ctx, objType, keyStr, eventChan, startRevision)
 	return nil
 }
 
+// RequestProgress makes the watches send a bookmark of the revision they have seen every event up to
+func (s *resourceStore) RequestProgress(ctx context.Context) error {
+	return s.clientWrapper.RequestProgress(ctx)
+}
+
 // watchResources
 // if eventChan is full this method will block until the channel is ready to receive the event
-func (s *resourceStore) watchResources(ctx context.Context, prefix string, eventChan chan<- types.WatchEvent, revision int64) {
+func (s *resourceStore) watchResources(
+	ctx context.Context,
+	objType *sdkmeta.ObjectType,
+	prefix string,
+	eventChan chan<- types.WatchEvent,
+	revision int64,
+) {
 	defer close(eventChan)
 
 	watchCtx, cancel := context.WithCancel(ctx)
@@ -159,8 +209,22 @@ func (s *resourceStore) watchResources(ctx context.Context, prefix string, event
 				return
 			}
 
+			if watchResp.IsProgressNotify() {
+				select {
+				case eventChan <- newBookmarkEvent(watchResp.Header.Revision):
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
 			for _, ev := range watchResp.Events {
-				event, err := s.convertEtcdEventToWatchEvent(ev, watchResp.Header.Revision)
+				objectKey, ok := parseResourceDbKey(string(ev.Kv.Key))
+				if !ok || !typeContainsKey(objType, objectKey) {
+					continue
+				}
+
+				event, err := s.convertEtcdEventToWatchEvent(ev, objectKey, watchResp.Header.Revision)
 				if err != nil {
 					s.logger.Error("Failed to convert etcd event to watch event",
 						zap.String("prefix", prefix),
@@ -182,11 +246,20 @@ func (s *resourceStore) watchResources(ctx context.Context, prefix string, event
 	}
 }
 
-func (s *resourceStore) convertEtcdEventToWatchEvent(ev *clientv3.Event, revision int64) (types.WatchEvent, error) {
+func (s *resourceStore) convertEtcdEventToWatchEvent(
+	ev *clientv3.Event,
+	objectKey sdkmeta.ObjectKey,
+	revision int64,
+) (types.WatchEvent, error) {
+	// the revision of the change itself, a watcher resuming from it gets exactly the changes that came after
 	event := types.WatchEvent{
+		ObjectKey: objectKey,
 		Timestamp: time.Now(),
 		Revision:  revision,
 	}
+	if ev.Kv != nil && ev.Kv.ModRevision > 0 {
+		event.Revision = ev.Kv.ModRevision
+	}
 
 	switch ev.Type {
 	case clientv3.EventTypePut:
@@ -203,6 +276,15 @@ func (s *resourceStore) convertEtcdEventToWatchEvent(ev *clientv3.Event, revisio
 		}
 		event.Object = resource
 
+		if ev.PrevKv != nil {
+			prevKv := convertClientKV(ev.PrevKv)
+			prevResource, err := s.unmarshalResource(&prevKv)
+			if err != nil {
+				return event, errors.Wrap(err, "failed to unmarshal previous resource from etcd event")
+			}
+			event.PrevObject = prevResource
+		}
+
 	case clientv3.EventTypeDelete:
 		event.Type = types.WatchEventTypeDeleted
 
@@ -214,21 +296,8 @@ func (s *resourceStore) convertEtcdEventToWatchEvent(ev *clientv3.Event, revisio
 			}
 			event.Object = resource
 		} else {
-			objectKey, err := parseObjectKey(string(ev.Kv.Key))
-			if err != nil {
-				return event, errors.Wrap(err, "failed to parse etcd key")
-			}
-
 			event.Object = &sdkmeta.Object{
-				ObjectKey: &sdkmeta.ObjectKey{
-					ObjectType: sdkmeta.ObjectType{
-						Group:     objectKey.Group,
-						Version:   objectKey.Version,
-						Kind:      objectKey.Kind,
-						Namespace: objectKey.Namespace,
-					},
-					Name: objectKey.Name,
-				},
+				ObjectKey: &objectKey,
 			}
 		}
 
@@ -4,13 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 
 	internalerrors "github.com/tsamsiyu/themelio/api/internal/errors"
 	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	sdkfields "github.com/tsamsiyu/themelio/sdk/pkg/fields"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
 	sdkschema "github.com/tsamsiyu/themelio/sdk/pkg/types/schema"
 )
 
@@ -28,6 +32,11 @@ func NewSchemaRepository(logger *zap.Logger, etcdClient *clientv3.Client) types.
 
 // Schema management methods
 func (r *schemaRepository) StoreSchema(ctx context.Context, schema *sdkschema.ObjectSchema) error {
+	previous, err := r.GetSchema(ctx, schema.Group, schema.Kind)
+	if err != nil && !IsNotFoundError(err) {
+		return err
+	}
+
 	schemaData, err := json.Marshal(schema)
 	if err != nil {
 		return internalerrors.NewMarshalingError("Failed to marshal ObjectSchema")
@@ -39,6 +48,10 @@ func (r *schemaRepository) StoreSchema(ctx context.Context, schema *sdkschema.Ob
 		return errors.Wrap(err, "failed to store ObjectSchema in etcd")
 	}
 
+	if err := r.syncFieldIndexes(ctx, previous, schema); err != nil {
+		return errors.Wrap(err, "failed to update field indexes")
+	}
+
 	r.logger.Info("ObjectSchema stored successfully",
 		zap.String("group", schema.Group),
 		zap.String("kind", schema.Kind))
@@ -102,3 +115,131 @@ func (r *schemaRepository) ListSchemas(ctx context.Context) ([]*sdkschema.Object
 
 	return schemas, nil
 }
+
+// syncFieldIndexes drops the indexes of fields that are no longer marked indexed and builds the ones of newly marked
+// fields from the stored objects, writes of objects keep the indexes up to date from then on
+func (r *schemaRepository) syncFieldIndexes(ctx context.Context, previous *sdkschema.ObjectSchema, schema *sdkschema.ObjectSchema) error {
+	versions := map[string]bool{}
+	for _, version := range schema.Versions {
+		versions[version.Name] = true
+	}
+	if previous != nil {
+		for _, version := range previous.Versions {
+			versions[version.Name] = true
+		}
+	}
+
+	for name := range versions {
+		objType := &sdkmeta.ObjectType{Group: schema.Group, Version: name, Kind: schema.Kind}
+
+		var oldFields, newFields []string
+		if previous != nil {
+			if version, ok := previous.Version(name); ok {
+				oldFields = version.IndexedFields()
+			}
+		}
+		if version, ok := schema.Version(name); ok {
+			newFields = version.IndexedFields()
+		}
+
+		for _, field := range subtractFields(oldFields, newFields) {
+			if _, err := r.etcdClient.Delete(ctx, fieldIndexDbPrefix(objType, field), clientv3.WithPrefix()); err != nil {
+				return errors.Wrap(err, "failed to delete field index")
+			}
+		}
+
+		if added := subtractFields(newFields, oldFields); len(added) > 0 {
+			if err := r.buildFieldIndexes(ctx, objType, added); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// buildFieldIndexes indexes the fields of the stored objects of the type page by page
+func (r *schemaRepository) buildFieldIndexes(ctx context.Context, objType *sdkmeta.ObjectType, fields []string) error {
+	prefix := objectTypeToDbKey(objType) + "/"
+	from := prefix
+
+	for {
+		resp, err := r.etcdClient.Get(ctx, from,
+			clientv3.WithRange(clientv3.GetPrefixRangeEnd(prefix)),
+			clientv3.WithLimit(maxTxnOps))
+		if err != nil {
+			return errors.Wrap(err, "failed to list objects to index")
+		}
+
+		for _, kv := range resp.Kvs {
+			if err := r.indexObjectFields(ctx, kv, fields); err != nil {
+				return err
+			}
+		}
+
+		if !resp.More || len(resp.Kvs) == 0 {
+			return nil
+		}
+		from = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+	}
+}
+
+// indexObjectFields writes the index keys only if the object is unchanged since it was read and retries otherwise,
+// so an object modified meanwhile is not indexed by its stale value
+func (r *schemaRepository) indexObjectFields(ctx context.Context, kv *mvccpb.KeyValue, fields []string) error {
+	for kv != nil {
+		var obj sdkmeta.Object
+		if err := json.Unmarshal(kv.Value, &obj); err != nil || obj.ObjectKey == nil {
+			r.logger.Warn("Skipping object that can't be indexed", zap.String("key", string(kv.Key)))
+			return nil
+		}
+
+		now := time.Now().Format(time.RFC3339)
+		var ops []clientv3.Op
+		for _, field := range fields {
+			if value, ok := sdkfields.ObjectValue(&obj, field); ok {
+				ops = append(ops, clientv3.OpPut(buildFieldIndexDbKey(*obj.ObjectKey, field, value), now))
+			}
+		}
+		if len(ops) == 0 {
+			return nil
+		}
+
+		resp, err := r.etcdClient.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(string(kv.Key)), "=", kv.ModRevision)).
+			Then(ops...).
+			Else(clientv3.OpGet(string(kv.Key))).
+			Commit()
+		if err != nil {
+			return errors.Wrap(err, "failed to write field index")
+		}
+		if resp.Succeeded {
+			return nil
+		}
+
+		kv = nil
+		if kvs := resp.Responses[0].GetResponseRange().Kvs; len(kvs) > 0 {
+			kv = kvs[0]
+		}
+	}
+
+	return nil
+}
+
+// subtractFields returns the fields of a that are not in b
+func subtractFields(a []string, b []string) []string {
+	var result []string
+	for _, field := range a {
+		found := false
+		for _, other := range b {
+			if field == other {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, field)
+		}
+	}
+	return result
+}
@@ -6,6 +6,8 @@ import (
 
 	clientv3 "go.etcd.io/etcd/client/v3"
 
+	sdkfields "github.com/tsamsiyu/themelio/sdk/pkg/fields"
+	sdklabels "github.com/tsamsiyu/themelio/sdk/pkg/labels"
 	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
 	sdkschema "github.com/tsamsiyu/themelio/sdk/pkg/types/schema"
 )
@@ -23,6 +25,7 @@ type Paging struct {
 type Batch struct {
 	Revision int64
 	KVs      []KeyValue
+	More     bool // there are more keys in the range beyond the limit
 }
 
 type KeyValue struct {
@@ -40,6 +43,8 @@ const (
 	WatchEventTypeModified WatchEventType = "modified"
 	WatchEventTypeDeleted  WatchEventType = "deleted"
 	WatchEventTypeError    WatchEventType = "error"
+	// WatchEventTypeBookmark carries only the revision the watcher has seen everything up to
+	WatchEventTypeBookmark WatchEventType = "bookmark"
 )
 
 type WatchEvent struct {
@@ -49,11 +54,43 @@ type WatchEvent struct {
 	Timestamp time.Time         `json:"timestamp"`
 	Revision  int64             `json:"revision"`
 	Error     error             `json:"error,omitempty"`
+	// PrevObject is the object before a modification, nil when it is not known
+	PrevObject *sdkmeta.Object `json:"-"`
+	// InitialEventsEnd marks the bookmark that follows the initial events of a watch
+	InitialEventsEnd bool `json:"-"`
 }
 
 type ObjectBatch struct {
 	Revision int64
 	Objects  []*sdkmeta.Object
+	More     bool
+	// Continue is the token of the next page, empty on the last one
+	Continue string
+	// LastKey is the db key the page ended at, the next one starts after it even when it didn't hold an object of the type
+	LastKey string
+}
+
+// ListOptions selects a page of a list, Continue is the token returned with the previous page
+type ListOptions struct {
+	Limit         int
+	Continue      string
+	LabelSelector sdklabels.Selector
+	FieldSelector sdkfields.Selector
+	// MinRevision lets the first page be served from the read cache once it has seen the revision,
+	// zero is a quorum read from etcd
+	MinRevision int64
+}
+
+// WatchOptions selects where a watch starts, a zero Revision watches only new changes
+type WatchOptions struct {
+	Revision int64
+	// SendInitialEvents streams the current objects as added events before the changes
+	SendInitialEvents bool
+}
+
+// DeleteOptions tell what happens to the children of a deleted object, a zero PropagationPolicy is background
+type DeleteOptions struct {
+	PropagationPolicy sdkmeta.PropagationPolicy
 }
 
 type WatchCacheEntry struct {
@@ -65,13 +102,58 @@ type WatchCacheEntry struct {
 type WatchConfig struct {
 	MaxRetries         int
 	ReconcileBatchSize int
+	// HistorySize is the number of recent events kept per type for watchers resuming from a revision
+	HistorySize int
+	// BookmarkInterval is how often watchers get a bookmark with the latest revision, zero disables bookmarks
+	BookmarkInterval time.Duration
+	// ReadCache keeps the objects of every kind read with a minimum revision in memory, fed by a watch of the kind
+	ReadCache bool
+	// ReadCacheWaitTimeout is how long a read waits for the cache to catch up with its revision before it goes to etcd
+	ReadCacheWaitTimeout time.Duration
+}
+
+// DeletionRecord is an object marked for deletion and the time it was marked
+type DeletionRecord struct {
+	ObjectKey sdkmeta.ObjectKey
+	Time      time.Time
+}
+
+// DeletionEvent is a change of a deletion record, Removed is set once the record is gone
+// An event with an Error is the last one of the watch
+type DeletionEvent struct {
+	Record   DeletionRecord
+	Removed  bool
+	Revision int64
+	Error    error
+}
+
+// OwnerReferenceEntry is an entry of the owner reference index, it links a child to an owner it references
+type OwnerReferenceEntry struct {
+	Parent sdkmeta.ObjectKey
+	Child  sdkmeta.ObjectKey
 }
 
-type DeletionBatch struct {
-	ObjectKeys []sdkmeta.ObjectKey
-	LeaseID    clientv3.LeaseID
+// OwnerReferenceBatch is a page of the owner reference index, LastKey continues the walk while More is set
+type OwnerReferenceBatch struct {
+	Entries []OwnerReferenceEntry
+	LastKey string
+	More    bool
 }
 
+// OwnerReferenceCheck is what checking an entry of the owner reference index did
+type OwnerReferenceCheck string
+
+const (
+	// OwnerReferenceValid means the owner and the child exist and nothing was changed
+	OwnerReferenceValid OwnerReferenceCheck = "valid"
+	// OwnerReferenceStale means the child is gone or doesn't reference the owner anymore, the entry was removed
+	OwnerReferenceStale OwnerReferenceCheck = "stale"
+	// OwnerReferenceOrphaned means the owner is gone and the child was marked for deletion
+	OwnerReferenceOrphaned OwnerReferenceCheck = "orphaned"
+	// OwnerReferenceDropped means the owner is gone and the child, held by other owners, lost the reference
+	OwnerReferenceDropped OwnerReferenceCheck = "dropped"
+)
+
 // EtcdClientInterface is a minimal interface for etcd client that we can mock
 // It includes the methods we need from clientv3.Client
 type EtcdClientInterface interface {
@@ -86,6 +168,8 @@ type ClientWrapper interface {
 	// Basic CRUD operations with raw data
 	Put(ctx context.Context, key string, value string) error
 	Get(ctx context.Context, key string) (*KeyValue, error)
+	// GetMany reads the keys at one revision, missing keys are skipped and a zero revision reads the latest one
+	GetMany(ctx context.Context, keys []string, revision int64) (*Batch, error)
 	Delete(ctx context.Context, key string) error
 	List(ctx context.Context, paging Paging) (*Batch, error)
 
@@ -96,6 +180,8 @@ type ClientWrapper interface {
 
 	// Watch operations
 	Watch(ctx context.Context, prefix string, revision ...int64) (<-chan clientv3.WatchResponse, error)
+	// RequestProgress asks etcd to tell the watches the revision they have seen every event up to
+	RequestProgress(ctx context.Context) error
 }
 
 // ResourceStore provides resource-specific operations and watch functionality
@@ -103,6 +189,7 @@ type ResourceStore interface {
 	// Basic CRUD operations with automatic marshaling
 	Put(ctx context.Context, obj *sdkmeta.Object) error
 	Get(ctx context.Context, key sdkmeta.ObjectKey) (*sdkmeta.Object, error)
+	GetMany(ctx context.Context, keys []sdkmeta.ObjectKey, revision int64) (*ObjectBatch, error)
 	Delete(ctx context.Context, key sdkmeta.ObjectKey) error
 	List(ctx context.Context, objType *sdkmeta.ObjectType, paging *Paging) (*ObjectBatch, error)
 
@@ -110,18 +197,75 @@ type ResourceStore interface {
 	BuildPutTxOp(obj *sdkmeta.Object) (clientv3.Op, error)
 
 	// Watch operations
+	// Watch sends a bookmark whenever etcd reports the revision the watch has seen every event up to
 	Watch(ctx context.Context, objType *sdkmeta.ObjectType, eventChan chan<- WatchEvent, revision ...int64) error
+	// RequestProgress makes the watches send such a bookmark
+	RequestProgress(ctx context.Context) error
 }
 
 // ResourceRepository interface for resource operations
 type ResourceRepository interface {
 	Replace(ctx context.Context, obj *sdkmeta.Object, optimisticLock bool) error
+	ReplaceStatus(ctx context.Context, obj *sdkmeta.Object, optimisticLock bool) error
 	Get(ctx context.Context, key sdkmeta.ObjectKey) (*sdkmeta.Object, error)
-	List(ctx context.Context, objType *sdkmeta.ObjectType) ([]*sdkmeta.Object, error)
+	// GetCached reads the object from the read cache once it has seen minRevision, from etcd when it is disabled or behind
+	GetCached(ctx context.Context, key sdkmeta.ObjectKey, minRevision int64) (*sdkmeta.Object, error)
+	List(ctx context.Context, objType *sdkmeta.ObjectType, options ListOptions) (*ObjectBatch, error)
 	Delete(ctx context.Context, key sdkmeta.ObjectKey, lockValue string) error
-	Watch(ctx context.Context, objType *sdkmeta.ObjectType, revision int64) (<-chan WatchEvent, error)
-	MarkDeleted(ctx context.Context, key sdkmeta.ObjectKey) error
-	ListDeletions(ctx context.Context, lockKey string, lockExp time.Duration, batchLimit int) (*DeletionBatch, error)
+	Watch(ctx context.Context, objType *sdkmeta.ObjectType, options WatchOptions) (<-chan WatchEvent, error)
+	MarkDeleted(ctx context.Context, key sdkmeta.ObjectKey, options DeleteOptions) error
+	// MarkChildrenDeleted marks the children of an object deleted in the foreground for deletion in the foreground,
+	// it returns how many children blocking the deletion of the object are left
+	MarkChildrenDeleted(ctx context.Context, obj *sdkmeta.Object, lockValue string) (int, error)
+	// RemoveFinalizer removes the finalizer from the object, the deletion of an object waiting for it resumes once none is left
+	RemoveFinalizer(ctx context.Context, key sdkmeta.ObjectKey, finalizer string) (*sdkmeta.Object, error)
+	// DeferDeletion drops the deletion record of an object held by finalizers until the last one is removed
+	DeferDeletion(ctx context.Context, obj *sdkmeta.Object, lockValue string) error
+	// ListDeletions returns the deletion records oldest first and the revision they were read at
+	ListDeletions(ctx context.Context, batchLimit int) ([]DeletionRecord, int64, error)
+	// WatchDeletions streams the changes of the deletion records made after the revision
+	WatchDeletions(ctx context.Context, revision int64) (<-chan DeletionEvent, error)
+	// AcquireDeletion locks the deletion of the object for lockKey, it returns false when another worker holds the lock
+	AcquireDeletion(ctx context.Context, key sdkmeta.ObjectKey, lockKey string, lockExp time.Duration) (bool, error)
+	// ReleaseDeletion drops the deletion lock of the object if lockKey holds it
+	ReleaseDeletion(ctx context.Context, key sdkmeta.ObjectKey, lockKey string) error
+	// ListOwnerReferences returns a page of the owner reference index after lastKey, an empty one starts the walk
+	ListOwnerReferences(ctx context.Context, lastKey string, limit int) (*OwnerReferenceBatch, error)
+	// CheckOwnerReference settles an entry of the owner reference index whose child or owner is gone,
+	// an owner replaced by an object with another UID counts as gone
+	CheckOwnerReference(ctx context.Context, entry OwnerReferenceEntry) (OwnerReferenceCheck, error)
+}
+
+// LeaderElection elects a single leader among the processes campaigning under the same name
+// Leadership is bound to an etcd lease, so it moves to another candidate once the leader stops renewing it
+type LeaderElection interface {
+	// Identity returns the unique identity this process campaigns with
+	Identity() string
+	// Campaign blocks until this process becomes the leader or ctx is done
+	Campaign(ctx context.Context) error
+	// Resign gives up leadership if it is held, so another candidate can take over immediately
+	Resign(ctx context.Context) error
+	// Leader returns the identity of the current leader, empty if there is none
+	Leader(ctx context.Context) (string, error)
+	// Observe streams the identity of the leader every time it changes, empty when there is none
+	Observe(ctx context.Context) (<-chan string, error)
+	// Done is closed when leadership acquired by the last Campaign is lost
+	Done() <-chan struct{}
+}
+
+// Membership registers the process as a live member of a group, so work can be shared among the members
+// Membership is bound to an etcd lease, so a member is dropped once it stops renewing it
+type Membership interface {
+	// Identity returns the unique identity this process joins with
+	Identity() string
+	// Join registers this process as a member of the group
+	Join(ctx context.Context) error
+	// Leave removes this process from the group, so the others take over its share immediately
+	Leave(ctx context.Context) error
+	// Observe streams the sorted identities of the live members every time they change
+	Observe(ctx context.Context) (<-chan []string, error)
+	// Done is closed when the membership acquired by the last Join is lost
+	Done() <-chan struct{}
 }
 
 // SchemaRepository interface for schema operations
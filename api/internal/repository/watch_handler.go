@@ -177,22 +177,25 @@ func (h *WatchHandler) ProcessWatchEvents(ctx context.Context, watchChan <-chan
 	}
 }
 
+// Reconcile lists the objects page by page, all pages at the revision of the first one,
+// and sends the differences to the cache as events
 func (h *WatchHandler) Reconcile(ctx context.Context) error {
 	lastKey := ""
+	var revision int64
 	allCurrentKeys := make(map[sdkmeta.ObjectKey]bool)
 
 	for {
 		batch, err := h.Store.List(ctx, h.ObjType, &types.Paging{
-			Prefix:  objectTypeToDbKey(h.ObjType),
-			Limit:   h.config.ReconcileBatchSize,
-			LastKey: lastKey,
+			Limit:    h.config.ReconcileBatchSize,
+			LastKey:  lastKey,
+			Revision: revision,
 		})
 		if err != nil {
 			return err
 		}
 
-		if len(batch.Objects) == 0 {
-			break
+		if revision == 0 {
+			revision = batch.Revision
 		}
 
 		for _, obj := range batch.Objects {
@@ -206,7 +209,7 @@ func (h *WatchHandler) Reconcile(ctx context.Context) error {
 					Object:    obj,
 					ObjectKey: key,
 					Timestamp: time.Now(),
-					Revision:  batch.Revision,
+					Revision:  revision,
 				}
 				h.SendEvent(ctx, event)
 			} else {
@@ -216,7 +219,7 @@ func (h *WatchHandler) Reconcile(ctx context.Context) error {
 						Object:    nil,
 						ObjectKey: key,
 						Timestamp: time.Now(),
-						Revision:  batch.Revision,
+						Revision:  revision,
 					}
 					h.SendEvent(ctx, deletedEvent)
 
@@ -225,7 +228,7 @@ func (h *WatchHandler) Reconcile(ctx context.Context) error {
 						Object:    obj,
 						ObjectKey: key,
 						Timestamp: time.Now(),
-						Revision:  batch.Revision,
+						Revision:  revision,
 					}
 					h.SendEvent(ctx, addedEvent)
 				} else if cachedEntry.ModRevision != obj.SystemMeta.ModRevision {
@@ -234,7 +237,7 @@ func (h *WatchHandler) Reconcile(ctx context.Context) error {
 						Object:    obj,
 						ObjectKey: key,
 						Timestamp: time.Now(),
-						Revision:  batch.Revision,
+						Revision:  revision,
 					}
 					h.SendEvent(ctx, event)
 				}
@@ -247,14 +250,13 @@ func (h *WatchHandler) Reconcile(ctx context.Context) error {
 			}
 		}
 
-		h.LastRevision = batch.Revision
+		h.LastRevision = revision
 
-		if len(batch.Objects) < h.config.ReconcileBatchSize {
+		// a page holds fewer objects than keys when keys of other types are skipped, so only More tells the end
+		if !batch.More || batch.LastKey == "" {
 			break
 		}
-
-		lastObject := batch.Objects[len(batch.Objects)-1]
-		lastKey = objectKeyToDbKey(*lastObject.ObjectKey)
+		lastKey = batch.LastKey
 	}
 
 	// check for objects in cache that are not in any batch (deleted objects)
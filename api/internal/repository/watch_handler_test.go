@@ -372,6 +372,8 @@ func TestWatchHandler_reconcile_BatchProcessing(t *testing.T) {
 	batch1 := &types.ObjectBatch{
 		Revision: 200,
 		Objects:  []*sdkmeta.Object{obj1},
+		More:     true,
+		LastKey:  objectKeyToDbKey(*obj1.ObjectKey),
 	}
 
 	batch2 := &types.ObjectBatch{
@@ -5,6 +5,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.uber.org/zap"
 
 	"github.com/tsamsiyu/themelio/api/internal/lib"
@@ -14,17 +16,27 @@ import (
 
 const (
 	blockingClientEvictionTimeout = 100 * time.Millisecond
+	clientBufferSize              = 100
 )
 
+// WatchManager shares one etcd watch per type key between its watchers
+// Watchers resuming from a revision are served from the recent history of the shared watch,
+// or get a watch of their own when the history doesn't reach back far enough
 type WatchManager struct {
-	store      types.ResourceStore
-	logger     *zap.Logger
-	config     types.WatchConfig
-	backoff    *lib.BackoffManager
-	handlers   map[string]*WatchHandler
-	handlersMu sync.RWMutex
-	clients    map[string][]chan<- types.WatchEvent
-	clientsMu  sync.RWMutex
+	store   types.ResourceStore
+	logger  *zap.Logger
+	config  types.WatchConfig
+	backoff *lib.BackoffManager
+	groups  map[string]*watchGroup
+	mu      sync.Mutex
+}
+
+// watchGroup is the shared watch of one type key
+type watchGroup struct {
+	handler *WatchHandler
+	cancel  context.CancelFunc
+	history *eventHistory
+	clients []*watchClient
 }
 
 func NewWatchManager(
@@ -34,156 +46,464 @@ func NewWatchManager(
 	backoff *lib.BackoffManager,
 ) *WatchManager {
 	return &WatchManager{
-		store:    store,
-		logger:   logger,
-		config:   config,
-		backoff:  backoff,
-		handlers: make(map[string]*WatchHandler),
-		clients:  make(map[string][]chan<- types.WatchEvent),
+		store:   store,
+		logger:  logger,
+		config:  config,
+		backoff: backoff,
+		groups:  make(map[string]*watchGroup),
 	}
 }
 
+// Watch streams events of the type after the revision, a zero revision streams only new events
+// An ExpiredError is returned when the revision has been compacted and the watcher has to list again
 func (m *WatchManager) Watch(ctx context.Context, objType *sdkmeta.ObjectType, revision int64) (<-chan types.WatchEvent, error) {
-	clientChan := make(chan types.WatchEvent, 100)
-	keyStr := objectTypeToDbKey(objType)
+	key := objectTypeToDbKey(objType)
+
+	m.mu.Lock()
+	group := m.groups[key]
 
-	m.handlersMu.Lock()
-	defer m.handlersMu.Unlock()
+	var replay []types.WatchEvent
+	if revision > 0 {
+		var ok bool
+		if group != nil {
+			replay, ok = group.history.Since(revision)
+		}
+		if !ok {
+			m.mu.Unlock()
+			return m.watchFrom(ctx, objType, revision)
+		}
+	}
+	defer m.mu.Unlock()
 
-	_, exists := m.handlers[keyStr]
-	if !exists {
-		handler := NewWatchHandler(objType, m.store, m.logger, m.config, m.backoff, revision)
-		m.handlers[keyStr] = handler
-		go m.startHandler(ctx, keyStr, handler)
+	if group == nil {
+		var err error
+		group, err = m.startGroup(ctx, key, objType)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	m.addClient(keyStr, clientChan)
+	// the replayed events fit into the buffer, so they are queued before any live event
+	client := newWatchClient(clientBufferSize + len(replay))
+	for _, event := range replay {
+		client.ch <- event
+	}
+	group.clients = append(group.clients, client)
 
-	go m.cleanupHandler(ctx, keyStr, clientChan)
+	go func() {
+		<-ctx.Done()
+		m.removeClient(key, group, client)
+	}()
+
+	return client.ch, nil
+}
+
+// ListAndWatch streams the current objects as added events, then a bookmark marking their end and then the changes
+// All pages are read at the revision of the first one, so the changes continue exactly where the objects left off
+func (m *WatchManager) ListAndWatch(ctx context.Context, objType *sdkmeta.ObjectType) (<-chan types.WatchEvent, error) {
+	pageSize := m.config.ReconcileBatchSize
+
+	batch, err := m.store.List(ctx, objType, &types.Paging{Limit: pageSize})
+	if err != nil {
+		return nil, err
+	}
+	revision := batch.Revision
+
+	clientChan := make(chan types.WatchEvent, clientBufferSize)
+	go func() {
+		defer close(clientChan)
+
+		send := func(event types.WatchEvent) bool {
+			select {
+			case clientChan <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			for _, obj := range batch.Objects {
+				event := types.WatchEvent{
+					Type:      types.WatchEventTypeAdded,
+					Object:    obj,
+					ObjectKey: *obj.ObjectKey,
+					Timestamp: time.Now(),
+					Revision:  revision,
+				}
+				if !send(event) {
+					return
+				}
+			}
+
+			if !batch.More || batch.LastKey == "" {
+				break
+			}
+
+			batch, err = m.store.List(ctx, objType, &types.Paging{
+				Limit:    pageSize,
+				LastKey:  batch.LastKey,
+				Revision: revision,
+			})
+			if err != nil {
+				send(types.WatchEvent{Type: types.WatchEventTypeError, Error: err, Timestamp: time.Now()})
+				return
+			}
+		}
+
+		initialEventsEnd := newBookmarkEvent(revision)
+		initialEventsEnd.InitialEventsEnd = true
+		if !send(initialEventsEnd) {
+			return
+		}
+
+		events, err := m.Watch(ctx, objType, revision)
+		if err != nil {
+			send(types.WatchEvent{Type: types.WatchEventTypeError, Error: err, Timestamp: time.Now()})
+			return
+		}
+
+		for event := range events {
+			if !send(event) {
+				return
+			}
+		}
+	}()
 
 	return clientChan, nil
 }
 
-func (m *WatchManager) startHandler(ctx context.Context, key string, handler *WatchHandler) {
-	handler.Start(ctx)
+// startGroup starts the shared watch from the current revision, from which on its history is complete
+func (m *WatchManager) startGroup(ctx context.Context, key string, objType *sdkmeta.ObjectType) (*watchGroup, error) {
+	batch, err := m.store.List(ctx, objType, &types.Paging{Limit: 1})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read current revision")
+	}
+
+	groupCtx, cancel := context.WithCancel(context.Background())
+	group := &watchGroup{
+		handler: NewWatchHandler(objType, m.store, m.logger, m.config, m.backoff, batch.Revision),
+		cancel:  cancel,
+		history: newEventHistory(m.config.HistorySize, batch.Revision),
+	}
+	m.groups[key] = group
+
+	group.handler.Start(groupCtx)
+	go m.broadcastLoop(groupCtx, key, group)
+
+	return group, nil
+}
+
+// watchFrom watches the type from the revision for a single watcher
+func (m *WatchManager) watchFrom(ctx context.Context, objType *sdkmeta.ObjectType, revision int64) (<-chan types.WatchEvent, error) {
+	// a read reports a compacted revision right away, a watch would only report it with its first event
+	if _, err := m.store.List(ctx, objType, &types.Paging{Limit: 1, Revision: revision}); err != nil {
+		return nil, err
+	}
+
+	eventChan := make(chan types.WatchEvent, clientBufferSize)
+	if err := m.store.Watch(ctx, objType, eventChan, revision+1); err != nil {
+		return nil, err
+	}
 
+	clientChan := make(chan types.WatchEvent, clientBufferSize)
 	go func() {
-		eventChan := handler.EventChannel()
+		defer close(clientChan)
+
+		bookmarks, stop := m.bookmarkTicker()
+		defer stop()
+
+		lastRevision := revision
+		bookmarkDue := false
 		for {
+			var event types.WatchEvent
 			select {
 			case <-ctx.Done():
 				return
-			case event, ok := <-eventChan:
+			case <-bookmarks:
+				due := bookmarkDue
+				bookmarkDue = true
+				m.requestProgress(ctx)
+				if !due {
+					continue
+				}
+				// etcd didn't report the progress since the previous tick
+				event = newBookmarkEvent(lastRevision)
+			case ev, ok := <-eventChan:
 				if !ok {
-					m.logger.Warn("Handler event channel closed, cleaning up clients",
-						zap.String("key", key))
-					m.cleanupHandlerClients(key)
 					return
 				}
-
-				if event.Type == types.WatchEventTypeError {
-					m.logger.Error("Handler error",
-						zap.String("key", key),
-						zap.Error(event.Error))
+				event = ev
+				if ev.Type == types.WatchEventTypeBookmark {
+					if ev.Revision <= lastRevision && !bookmarkDue {
+						continue
+					}
+					event = newBookmarkEvent(max(ev.Revision, lastRevision))
 				}
+			}
+
+			if event.Type == types.WatchEventTypeBookmark {
+				bookmarkDue = false
+			}
+
+			if event.Type == types.WatchEventTypeError && errors.Is(event.Error, rpctypes.ErrCompacted) {
+				event.Error = NewExpiredError(revision)
+			}
+			if event.Type != types.WatchEventTypeError && event.Revision > lastRevision {
+				lastRevision = event.Revision
+			}
 
-				m.broadcastEvent(ctx, key, event)
+			select {
+			case clientChan <- event:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
+
+	return clientChan, nil
+}
+
+func (m *WatchManager) broadcastLoop(ctx context.Context, key string, group *watchGroup) {
+	bookmarks, stop := m.bookmarkTicker()
+	defer stop()
+
+	// a bookmark goes out whenever etcd reports progress past the last event, and at least once per tick
+	bookmarkDue := false
+	eventChan := group.handler.EventChannel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-bookmarks:
+			due := bookmarkDue
+			bookmarkDue = true
+			m.requestProgress(ctx)
+			if !due {
+				continue
+			}
+
+			// etcd didn't report the progress since the previous tick
+			m.mu.Lock()
+			revision := group.history.LastRevision()
+			m.mu.Unlock()
+
+			bookmarkDue = false
+			m.broadcastEvent(key, group, newBookmarkEvent(revision))
+		case event, ok := <-eventChan:
+			if !ok {
+				m.logger.Warn("Handler event channel closed, cleaning up clients",
+					zap.String("key", key))
+				m.closeGroup(key, group)
+				return
+			}
+
+			if event.Type == types.WatchEventTypeBookmark {
+				m.mu.Lock()
+				moved := group.history.Progress(event.Revision)
+				revision := group.history.LastRevision()
+				m.mu.Unlock()
+
+				if moved || bookmarkDue {
+					bookmarkDue = false
+					m.broadcastEvent(key, group, newBookmarkEvent(revision))
+				}
+				continue
+			}
+
+			if event.Type == types.WatchEventTypeError {
+				m.logger.Error("Handler error",
+					zap.String("key", key),
+					zap.Error(event.Error))
+			}
+
+			m.broadcastEvent(key, group, event)
+		}
+	}
 }
 
-func (m *WatchManager) cleanupHandler(
-	ctx context.Context,
-	key string,
-	clientChan chan types.WatchEvent,
-) {
-	<-ctx.Done()
-	m.removeClient(key, clientChan)
-	close(clientChan)
+// requestProgress asks etcd for the revision the watches have seen every event up to, it comes back as bookmarks
+func (m *WatchManager) requestProgress(ctx context.Context) {
+	if err := m.store.RequestProgress(ctx); err != nil && ctx.Err() == nil {
+		m.logger.Warn("Failed to request watch progress", zap.Error(err))
+	}
+}
 
-	if m.getClientCount(key) == 0 {
-		m.handlersMu.Lock()
-		defer m.handlersMu.Unlock()
-		delete(m.handlers, key)
+// bookmarkTicker ticks every BookmarkInterval, it never ticks when bookmarks are disabled
+func (m *WatchManager) bookmarkTicker() (<-chan time.Time, func()) {
+	if m.config.BookmarkInterval <= 0 {
+		return nil, func() {}
 	}
+	ticker := time.NewTicker(m.config.BookmarkInterval)
+	return ticker.C, ticker.Stop
 }
 
-func (m *WatchManager) cleanupHandlerClients(key string) {
-	m.clientsMu.Lock()
-	defer m.clientsMu.Unlock()
+func newBookmarkEvent(revision int64) types.WatchEvent {
+	return types.WatchEvent{
+		Type:      types.WatchEventTypeBookmark,
+		Timestamp: time.Now(),
+		Revision:  revision,
+	}
+}
+
+func (m *WatchManager) broadcastEvent(key string, group *watchGroup, event types.WatchEvent) {
+	// the history and the clients are updated together, so a joining client neither misses nor repeats an event
+	m.mu.Lock()
+	if event.Type != types.WatchEventTypeError && event.Type != types.WatchEventTypeBookmark {
+		group.history.Add(event)
+	}
+	clients := make([]*watchClient, len(group.clients))
+	copy(clients, group.clients)
+	m.mu.Unlock()
 
-	clients := m.clients[key]
 	for _, client := range clients {
-		close(client)
+		if !client.send(event, blockingClientEvictionTimeout) {
+			m.logger.Warn("Client not reading events, evicting",
+				zap.String("key", key))
+			m.removeClient(key, group, client)
+		}
+	}
+}
+
+// removeClient closes the client and stops the shared watch when it was the last one
+func (m *WatchManager) removeClient(key string, group *watchGroup, client *watchClient) {
+	m.mu.Lock()
+	for i, c := range group.clients {
+		if c == client {
+			group.clients = append(group.clients[:i], group.clients[i+1:]...)
+			break
+		}
 	}
 
-	delete(m.clients, key)
+	if len(group.clients) == 0 && m.groups[key] == group {
+		delete(m.groups, key)
+		group.cancel()
+	}
+	m.mu.Unlock()
 
-	m.handlersMu.Lock()
-	defer m.handlersMu.Unlock()
-	delete(m.handlers, key)
+	client.close()
 }
 
-func (m *WatchManager) addClient(key string, clientChan chan<- types.WatchEvent) {
-	m.clientsMu.Lock()
-	defer m.clientsMu.Unlock()
-	m.clients[key] = append(m.clients[key], clientChan)
+func (m *WatchManager) closeGroup(key string, group *watchGroup) {
+	m.mu.Lock()
+	if m.groups[key] == group {
+		delete(m.groups, key)
+	}
+	clients := group.clients
+	group.clients = nil
+	m.mu.Unlock()
+
+	group.cancel()
+	for _, client := range clients {
+		client.close()
+	}
 }
 
-func (m *WatchManager) removeClient(key string, clientChan chan<- types.WatchEvent) {
-	m.clientsMu.Lock()
-	defer m.clientsMu.Unlock()
+// watchClient guards the channel of a watcher, so events are never sent to it after it was closed
+type watchClient struct {
+	ch     chan types.WatchEvent
+	mu     sync.Mutex
+	closed bool
+}
 
-	clients := m.clients[key]
-	for i, client := range clients {
-		if client == clientChan {
-			m.clients[key] = append(clients[:i], clients[i+1:]...)
-			break
-		}
+func newWatchClient(bufferSize int) *watchClient {
+	return &watchClient{ch: make(chan types.WatchEvent, bufferSize)}
+}
+
+// send returns false when the client hasn't read the event within the timeout
+func (c *watchClient) send(event types.WatchEvent, timeout time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return true
+	}
+
+	select {
+	case c.ch <- event:
+		return true
+	default:
+	}
+
+	select {
+	case c.ch <- event:
+		return true
+	case <-time.After(timeout):
+		return false
 	}
 }
 
-func (m *WatchManager) getClientCount(key string) int {
-	m.clientsMu.RLock()
-	defer m.clientsMu.RUnlock()
-	return len(m.clients[key])
+func (c *watchClient) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.ch)
+	}
 }
 
-func (m *WatchManager) copyClients(key string) []chan<- types.WatchEvent {
-	m.clientsMu.RLock()
-	defer m.clientsMu.RUnlock()
+// eventHistory keeps the latest events of a shared watch for watchers resuming from a recent revision
+type eventHistory struct {
+	events []types.WatchEvent
+	size   int
+	// startRevision is the revision after which every event is still kept
+	startRevision int64
+	// progressRevision is the revision etcd reported every event up to has been seen,
+	// it runs ahead of the events when other types are changed
+	progressRevision int64
+}
 
-	clients := m.clients[key]
-	result := make([]chan<- types.WatchEvent, len(clients))
-	copy(result, clients)
-	return result
+func newEventHistory(size int, startRevision int64) *eventHistory {
+	return &eventHistory{size: size, startRevision: startRevision}
 }
 
-func (m *WatchManager) broadcastEvent(ctx context.Context, key string, event types.WatchEvent) {
-	clients := m.copyClients(key)
+func (h *eventHistory) Add(event types.WatchEvent) {
+	if h.size <= 0 {
+		h.startRevision = event.Revision
+		return
+	}
 
-	for _, client := range clients {
-		select {
-		case client <- event:
-			continue
-		case <-ctx.Done():
-			return
-		default:
-			// Client is not reading
-		}
+	if len(h.events) == h.size {
+		// events of one transaction share the revision, so everything up to the dropped one is incomplete
+		h.startRevision = h.events[0].Revision
+		h.events = h.events[1:]
+	}
+	h.events = append(h.events, event)
+}
 
-		// try to send event to blocking client with timeout, if still not reading, evict this client
-		go func(clientChan chan<- types.WatchEvent) {
-			select {
-			case clientChan <- event:
-			case <-time.After(blockingClientEvictionTimeout):
-				m.logger.Warn("Client not reading events, evicting",
-					zap.String("key", key))
-				m.removeClient(key, clientChan)
-				close(clientChan)
-			case <-ctx.Done():
-			}
-		}(client)
+// Progress records the revision etcd reported every event up to has been seen, true when it moves LastRevision
+func (h *eventHistory) Progress(revision int64) bool {
+	if revision <= h.LastRevision() {
+		return false
+	}
+	h.progressRevision = revision
+	return true
+}
+
+// LastRevision is the revision up to which every event has been seen
+func (h *eventHistory) LastRevision() int64 {
+	revision := h.startRevision
+	if len(h.events) > 0 {
+		revision = h.events[len(h.events)-1].Revision
+	}
+	if h.progressRevision > revision {
+		return h.progressRevision
+	}
+	return revision
+}
+
+// Since returns the events after the revision, false if some of them are no longer kept
+func (h *eventHistory) Since(revision int64) ([]types.WatchEvent, bool) {
+	if revision < h.startRevision {
+		return nil, false
+	}
+
+	var events []types.WatchEvent
+	for _, event := range h.events {
+		if event.Revision > revision {
+			events = append(events, event)
+		}
 	}
+	return events, true
 }
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/lib"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+func TestEventHistory_Since(t *testing.T) {
+	history := newEventHistory(2, 4)
+
+	events, ok := history.Since(4)
+	assert.True(t, ok)
+	assert.Empty(t, events)
+
+	history.Add(types.WatchEvent{Revision: 5})
+	history.Add(types.WatchEvent{Revision: 6})
+	history.Add(types.WatchEvent{Revision: 7})
+
+	// The event of revision 5 was dropped, so only watchers that have seen it can resume
+	_, ok = history.Since(4)
+	assert.False(t, ok)
+
+	events, ok = history.Since(5)
+	assert.True(t, ok)
+	assert.Equal(t, []types.WatchEvent{{Revision: 6}, {Revision: 7}}, events)
+}
+
+func TestWatchManager_ResumeFromHistory(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	objType := &sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: "default"}
+	manager := NewWatchManager(mockStore, zap.NewNop(), types.WatchConfig{HistorySize: 10}, &lib.BackoffManager{})
+
+	// Given: The shared watch starts at revision 10
+	etcdEvents := make(chan chan<- types.WatchEvent, 1)
+	mockStore.EXPECT().List(mock.Anything, objType, &types.Paging{Limit: 1}).Return(&types.ObjectBatch{Revision: 10}, nil)
+	mockStore.EXPECT().Watch(mock.Anything, objType, mock.Anything, int64(11)).
+		Run(func(_ context.Context, _ *sdkmeta.ObjectType, eventChan chan<- types.WatchEvent, _ ...int64) {
+			etcdEvents <- eventChan
+		}).Return(nil)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	defer cancelFirst()
+	first, err := manager.Watch(firstCtx, objType, 0)
+	assert.NoError(t, err)
+
+	eventChan := <-etcdEvents
+	eventChan <- testWatchEvent(types.WatchEventTypeAdded, 11)
+	eventChan <- testWatchEvent(types.WatchEventTypeModified, 12)
+	assert.Equal(t, int64(11), receiveEvent(t, first).Revision)
+	assert.Equal(t, int64(12), receiveEvent(t, first).Revision)
+
+	// When: Another watcher resumes from revision 11
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+	defer cancelSecond()
+	second, err := manager.Watch(secondCtx, objType, 11)
+	assert.NoError(t, err)
+
+	// Then: It gets the missed event from the history and the live ones after it
+	eventChan <- testWatchEvent(types.WatchEventTypeDeleted, 13)
+	assert.Equal(t, int64(12), receiveEvent(t, second).Revision)
+	assert.Equal(t, int64(13), receiveEvent(t, second).Revision)
+	assert.Equal(t, int64(13), receiveEvent(t, first).Revision)
+}
+
+func TestWatchManager_Bookmarks(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	objType := &sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: "default"}
+	config := types.WatchConfig{HistorySize: 10, BookmarkInterval: 10 * time.Millisecond}
+	manager := NewWatchManager(mockStore, zap.NewNop(), config, &lib.BackoffManager{})
+
+	// Given: The shared watch starts at revision 10, nothing changes and etcd doesn't report the progress
+	mockStore.EXPECT().List(mock.Anything, objType, &types.Paging{Limit: 1}).Return(&types.ObjectBatch{Revision: 10}, nil)
+	mockStore.EXPECT().Watch(mock.Anything, objType, mock.Anything, int64(11)).Return(nil)
+	mockStore.EXPECT().RequestProgress(mock.Anything).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// When: Watching
+	events, err := manager.Watch(ctx, objType, 0)
+	assert.NoError(t, err)
+
+	// Then: The watcher still learns the revision it can resume from
+	event := receiveEvent(t, events)
+	assert.Equal(t, types.WatchEventTypeBookmark, event.Type)
+	assert.Equal(t, int64(10), event.Revision)
+}
+
+func TestWatchManager_BookmarksFollowProgress(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	objType := &sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: "default"}
+	config := types.WatchConfig{HistorySize: 10, BookmarkInterval: 10 * time.Millisecond}
+	manager := NewWatchManager(mockStore, zap.NewNop(), config, &lib.BackoffManager{})
+
+	// Given: The shared watch starts at revision 10
+	etcdEvents := make(chan chan<- types.WatchEvent, 1)
+	mockStore.EXPECT().List(mock.Anything, objType, &types.Paging{Limit: 1}).Return(&types.ObjectBatch{Revision: 10}, nil)
+	mockStore.EXPECT().Watch(mock.Anything, objType, mock.Anything, int64(11)).
+		Run(func(_ context.Context, _ *sdkmeta.ObjectType, eventChan chan<- types.WatchEvent, _ ...int64) {
+			etcdEvents <- eventChan
+		}).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := manager.Watch(ctx, objType, 0)
+	assert.NoError(t, err)
+	eventChan := <-etcdEvents
+
+	// When: Writes to another type move etcd to revision 15 and etcd reports the progress of the watch when asked
+	mockStore.EXPECT().RequestProgress(mock.Anything).
+		Run(func(_ context.Context) {
+			eventChan <- newBookmarkEvent(15)
+		}).Return(nil).Once()
+	mockStore.EXPECT().RequestProgress(mock.Anything).Return(nil).Maybe()
+
+	// Then: The bookmark carries the current revision of etcd rather than the one of the last event of the type
+	event := receiveEvent(t, events)
+	assert.Equal(t, types.WatchEventTypeBookmark, event.Type)
+	assert.Equal(t, int64(15), event.Revision)
+
+	// And: Watchers can resume from it
+	resumed, err := manager.Watch(ctx, objType, 15)
+	assert.NoError(t, err)
+	eventChan <- testWatchEvent(types.WatchEventTypeModified, 16)
+	assert.Equal(t, int64(16), receiveEvent(t, resumed).Revision)
+}
+
+func TestWatchManager_ListAndWatch(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	objType := &sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: "default"}
+	config := types.WatchConfig{HistorySize: 10, ReconcileBatchSize: 1}
+	manager := NewWatchManager(mockStore, zap.NewNop(), config, &lib.BackoffManager{})
+
+	// Given: Two objects read in two pages at revision 10
+	first := testWatchEvent(types.WatchEventTypeAdded, 0).Object
+	secondKey := sdkmeta.ObjectKey{ObjectType: *objType, Name: "second"}
+	second := &sdkmeta.Object{ObjectKey: &secondKey, SystemMeta: &sdkmeta.SystemMeta{}}
+
+	mockStore.EXPECT().List(mock.Anything, objType, &types.Paging{Limit: 1}).
+		Return(&types.ObjectBatch{Revision: 10, Objects: []*sdkmeta.Object{first}, More: true, LastKey: objectKeyToDbKey(*first.ObjectKey)}, nil).Once()
+	mockStore.EXPECT().List(mock.Anything, objType, &types.Paging{Limit: 1, LastKey: objectKeyToDbKey(*first.ObjectKey), Revision: 10}).
+		Return(&types.ObjectBatch{Revision: 10, Objects: []*sdkmeta.Object{second}}, nil).Once()
+
+	// And: The changes after them are watched from the same revision
+	etcdEvents := make(chan chan<- types.WatchEvent, 1)
+	mockStore.EXPECT().List(mock.Anything, objType, &types.Paging{Limit: 1, Revision: 10}).Return(&types.ObjectBatch{Revision: 12}, nil).Once()
+	mockStore.EXPECT().Watch(mock.Anything, objType, mock.Anything, int64(11)).
+		Run(func(_ context.Context, _ *sdkmeta.ObjectType, eventChan chan<- types.WatchEvent, _ ...int64) {
+			etcdEvents <- eventChan
+		}).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// When: Watching with the initial events
+	events, err := manager.ListAndWatch(ctx, objType)
+	assert.NoError(t, err)
+
+	// Then: The objects come first, then the bookmark marking their end and then the changes
+	event := receiveEvent(t, events)
+	assert.Equal(t, types.WatchEventTypeAdded, event.Type)
+	assert.Equal(t, first, event.Object)
+	event = receiveEvent(t, events)
+	assert.Equal(t, types.WatchEventTypeAdded, event.Type)
+	assert.Equal(t, second, event.Object)
+
+	event = receiveEvent(t, events)
+	assert.Equal(t, types.WatchEventTypeBookmark, event.Type)
+	assert.True(t, event.InitialEventsEnd)
+	assert.Equal(t, int64(10), event.Revision)
+
+	(<-etcdEvents) <- testWatchEvent(types.WatchEventTypeModified, 11)
+	event = receiveEvent(t, events)
+	assert.Equal(t, types.WatchEventTypeModified, event.Type)
+	assert.Equal(t, int64(11), event.Revision)
+}
+
+func TestWatchManager_CompactedRevision(t *testing.T) {
+	mockStore := mocks.NewMockResourceStore(t)
+	objType := &sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: "default"}
+	manager := NewWatchManager(mockStore, zap.NewNop(), types.WatchConfig{HistorySize: 10}, &lib.BackoffManager{})
+
+	// Given: The requested revision is compacted and no shared watch covers it
+	mockStore.EXPECT().List(mock.Anything, objType, &types.Paging{Limit: 1, Revision: 3}).Return(nil, NewExpiredError(3))
+
+	// When: Watching from it
+	_, err := manager.Watch(context.Background(), objType, 3)
+
+	// Then: The watcher is told to list again
+	assert.True(t, IsExpiredError(err))
+}
+
+func testWatchEvent(eventType types.WatchEventType, revision int64) types.WatchEvent {
+	key := sdkmeta.ObjectKey{ObjectType: sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: "default"}, Name: "test"}
+	return types.WatchEvent{
+		Type:      eventType,
+		ObjectKey: key,
+		Object:    &sdkmeta.Object{ObjectKey: &key, SystemMeta: &sdkmeta.SystemMeta{}},
+		Revision:  revision,
+	}
+}
+
+func receiveEvent(t *testing.T, ch <-chan types.WatchEvent) types.WatchEvent {
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return types.WatchEvent{}
+	}
+}
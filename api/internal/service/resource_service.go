@@ -43,6 +43,14 @@ func (s *resourceService) ReplaceResource(ctx context.Context, params servicetyp
 		return err
 	}
 
+	if payload.ObjectKey == nil {
+		return internalerrors.NewInvalidInputError("object key is required")
+	}
+
+	if err := applyExpectedRevision(params, payload); err != nil {
+		return err
+	}
+
 	paramsWithName := params
 	paramsWithName.Name = payload.ObjectKey.Name
 
@@ -51,6 +59,10 @@ func (s *resourceService) ReplaceResource(ctx context.Context, params servicetyp
 		return err
 	}
 
+	if err := applyNamespaceFromParams(schema, &params, payload.ObjectKey); err != nil {
+		return err
+	}
+
 	if err := sharedservice.ValidateResource(payload, schema); err != nil {
 		return err
 	}
@@ -73,24 +85,48 @@ func (s *resourceService) GetResource(ctx context.Context, params servicetypes.P
 		return nil, err
 	}
 
+	if params.MinRevision > 0 {
+		return s.repo.GetCached(ctx, objectKey, params.MinRevision)
+	}
 	return s.repo.Get(ctx, objectKey)
 }
 
-func (s *resourceService) ListResources(ctx context.Context, params servicetypes.Params) ([]*sdkmeta.Object, error) {
+func (s *resourceService) ListResources(ctx context.Context, params servicetypes.Params, options repositorytypes.ListOptions) (*repositorytypes.ObjectBatch, error) {
+	if options.Limit < 0 {
+		return nil, internalerrors.NewInvalidInputError("limit must not be negative")
+	}
+
+	selector, err := parseLabelSelector(params.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	options.LabelSelector = selector
+
 	schema, err := s.schemaService.Get(ctx, params.Group, params.Kind)
 	if err != nil {
 		return nil, err
 	}
 
-	typeMeta, err := getObjectTypeFromParams(schema, &params)
+	typeMeta, err := getListObjectTypeFromParams(schema, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	options.FieldSelector, err = parseFieldSelector(params.FieldSelector, schema, params.Version)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.repo.List(ctx, typeMeta)
+	return s.repo.List(ctx, typeMeta, options)
 }
 
-func (s *resourceService) DeleteResource(ctx context.Context, params servicetypes.Params) error {
+func (s *resourceService) DeleteResource(ctx context.Context, params servicetypes.Params, options repositorytypes.DeleteOptions) error {
+	switch options.PropagationPolicy {
+	case "", sdkmeta.PropagationPolicyBackground, sdkmeta.PropagationPolicyForeground, sdkmeta.PropagationPolicyOrphan:
+	default:
+		return internalerrors.NewInvalidInputError(fmt.Sprintf("unknown propagation policy %q", options.PropagationPolicy))
+	}
+
 	schema, err := s.schemaService.Get(ctx, params.Group, params.Kind)
 	if err != nil {
 		return err
@@ -101,7 +137,7 @@ func (s *resourceService) DeleteResource(ctx context.Context, params servicetype
 		return err
 	}
 
-	return s.repo.MarkDeleted(ctx, objectKey)
+	return s.repo.MarkDeleted(ctx, objectKey, options)
 }
 
 func (s *resourceService) PatchResource(ctx context.Context, params servicetypes.Params, patchData []byte) (*sdkmeta.Object, error) {
@@ -115,6 +151,10 @@ func (s *resourceService) PatchResource(ctx context.Context, params servicetypes
 		return nil, err
 	}
 
+	if params.ExpectedRevision != 0 {
+		existingResource.SystemMeta.ModRevision = params.ExpectedRevision
+	}
+
 	existingJSON, err := json.Marshal(existingResource)
 	if err != nil {
 		return nil, internalerrors.NewMarshalingError("failed to marshal existing resource")
@@ -143,30 +183,155 @@ func (s *resourceService) PatchResource(ctx context.Context, params servicetypes
 		return nil, err
 	}
 
-	if err := s.repo.Replace(ctx, patchedResource, false); err != nil {
+	if err := s.repo.Replace(ctx, patchedResource, true); err != nil {
 		return nil, err
 	}
 
 	return patchedResource, nil
 }
 
-func (s *resourceService) WatchResource(ctx context.Context, params servicetypes.Params, revision int64) (<-chan repositorytypes.WatchEvent, error) {
+// ReplaceResourceStatus takes only the status from the payload, the rest of the stored object is kept
+func (s *resourceService) ReplaceResourceStatus(ctx context.Context, params servicetypes.Params, jsonData []byte) error {
+	payload, err := s.convertJSONToObject(jsonData)
+	if err != nil {
+		return err
+	}
+
+	if err := applyExpectedRevision(params, payload); err != nil {
+		return err
+	}
+
+	existingResource, err := s.GetResource(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	if payload.ObjectKey != nil && payload.ObjectKey.Name != existingResource.ObjectKey.Name {
+		return internalerrors.NewInvalidInputError(
+			fmt.Sprintf("name %q does not match name %q in path", payload.ObjectKey.Name, existingResource.ObjectKey.Name))
+	}
+
+	return s.replaceStatus(ctx, existingResource, payload)
+}
+
+// PatchResourceStatus applies the patch to the stored object and keeps only the changes of the status
+func (s *resourceService) PatchResourceStatus(ctx context.Context, params servicetypes.Params, patchData []byte) (*sdkmeta.Object, error) {
+	existingResource, err := s.GetResource(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.ExpectedRevision != 0 {
+		existingResource.SystemMeta.ModRevision = params.ExpectedRevision
+	}
+
+	existingJSON, err := json.Marshal(existingResource)
+	if err != nil {
+		return nil, internalerrors.NewMarshalingError("failed to marshal existing resource")
+	}
+
+	patch, err := jsonpatch.DecodePatch(patchData)
+	if err != nil {
+		return nil, internalerrors.NewInvalidInputError("failed to decode patch: " + err.Error())
+	}
+
+	patchedJSON, err := patch.Apply(existingJSON)
+	if err != nil {
+		return nil, internalerrors.NewInvalidInputError("failed to apply patch: " + err.Error())
+	}
+
+	patchedResource, err := s.convertJSONToObject(patchedJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.replaceStatus(ctx, existingResource, patchedResource); err != nil {
+		return nil, err
+	}
+
+	return existingResource, nil
+}
+
+// RemoveFinalizer removes one finalizer by name, so controllers don't have to rewrite the whole object
+func (s *resourceService) RemoveFinalizer(ctx context.Context, params servicetypes.Params, finalizer string) (*sdkmeta.Object, error) {
+	if finalizer == "" {
+		return nil, internalerrors.NewInvalidInputError("finalizer is required")
+	}
+
 	schema, err := s.schemaService.Get(ctx, params.Group, params.Kind)
 	if err != nil {
 		return nil, err
 	}
 
-	objType, err := getObjectTypeFromParams(schema, &params)
+	objectKey, err := getObjectKeyFromParams(schema, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.RemoveFinalizer(ctx, objectKey, finalizer)
+}
+
+// replaceStatus puts the status of the payload into the existing object and saves it
+func (s *resourceService) replaceStatus(ctx context.Context, existingResource *sdkmeta.Object, payload *sdkmeta.Object) error {
+	schema, err := s.schemaService.Get(ctx, existingResource.ObjectKey.Group, existingResource.ObjectKey.Kind)
+	if err != nil {
+		return err
+	}
+
+	existingResource.Status = payload.Status
+	if observed, ok := existingResource.ObservedGeneration(); ok && observed > existingResource.SystemMeta.Generation {
+		return internalerrors.NewInvalidInputError(
+			fmt.Sprintf("%s %d is newer than generation %d", sdkmeta.ObservedGenerationField, observed, existingResource.SystemMeta.Generation))
+	}
+	if payload.SystemMeta != nil && payload.SystemMeta.ModRevision != 0 {
+		existingResource.SystemMeta.ModRevision = payload.SystemMeta.ModRevision
+	}
+
+	if err := sharedservice.ValidateResource(existingResource, schema); err != nil {
+		return err
+	}
+
+	return s.repo.ReplaceStatus(ctx, existingResource, true)
+}
+
+// WatchResource watches the objects of a kind, of every namespace when a namespaced kind is given no namespace
+// An empty kind watches the objects of every kind, in the namespace when one is given
+func (s *resourceService) WatchResource(ctx context.Context, params servicetypes.Params, options repositorytypes.WatchOptions) (<-chan repositorytypes.WatchEvent, error) {
+	labelSelector, err := parseLabelSelector(params.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema *sdkschema.ObjectSchema
+	objType := &sdkmeta.ObjectType{Namespace: params.Namespace}
+
+	if params.Kind != "" {
+		schema, err = s.schemaService.Get(ctx, params.Group, params.Kind)
+		if err != nil {
+			return nil, err
+		}
+
+		objType, err = getListObjectTypeFromParams(schema, &params)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fieldSelector, err := parseFieldSelector(params.FieldSelector, schema, params.Version)
 	if err != nil {
 		return nil, err
 	}
 
-	watchChan, err := s.repo.Watch(ctx, objType, revision)
+	watchChan, err := s.repo.Watch(ctx, objType, options)
 	if err != nil {
 		return nil, err
 	}
 
-	return watchChan, nil
+	if labelSelector.Empty() && fieldSelector.Empty() {
+		return watchChan, nil
+	}
+
+	return filterWatch(ctx, watchChan, matchesSelectors(labelSelector, fieldSelector)), nil
 }
 
 func (s *resourceService) convertJSONToObject(jsonData []byte) (*sdkmeta.Object, error) {
@@ -229,3 +394,62 @@ func getObjectTypeFromParams(schema *sdkschema.ObjectSchema, params *servicetype
 		Namespace: params.Namespace,
 	}, nil
 }
+
+// getListObjectTypeFromParams is like getObjectTypeFromParams, but an empty namespace
+// of a namespaced kind selects objects across all namespaces
+func getListObjectTypeFromParams(schema *sdkschema.ObjectSchema, params *servicetypes.Params) (*sdkmeta.ObjectType, error) {
+	if schema.Scope == sdkschema.ResourceScopeNamespaced && params.Namespace == "" {
+		return &sdkmeta.ObjectType{
+			Group:   params.Group,
+			Version: params.Version,
+			Kind:    params.Kind,
+		}, nil
+	}
+
+	return getObjectTypeFromParams(schema, params)
+}
+
+// applyExpectedRevision takes the revision of the If-Match header, it must agree with the one in the body if both are set
+func applyExpectedRevision(params servicetypes.Params, obj *sdkmeta.Object) error {
+	if params.ExpectedRevision == 0 {
+		return nil
+	}
+
+	if obj.SystemMeta == nil {
+		obj.SystemMeta = &sdkmeta.SystemMeta{}
+	}
+
+	if obj.SystemMeta.ModRevision != 0 && obj.SystemMeta.ModRevision != params.ExpectedRevision {
+		return internalerrors.NewInvalidInputError(
+			fmt.Sprintf("modRevision %d does not match revision %d in If-Match header", obj.SystemMeta.ModRevision, params.ExpectedRevision))
+	}
+
+	obj.SystemMeta.ModRevision = params.ExpectedRevision
+	return nil
+}
+
+// applyNamespaceFromParams makes sure the namespace of the payload agrees with the request path
+func applyNamespaceFromParams(schema *sdkschema.ObjectSchema, params *servicetypes.Params, key *sdkmeta.ObjectKey) error {
+	if schema.Scope == sdkschema.ResourceScopeCluster {
+		if key.Namespace != "" || params.Namespace != "" {
+			return internalerrors.NewInvalidInputError("namespace must not be set for cluster resource")
+		}
+		return nil
+	}
+
+	if params.Namespace == "" {
+		if key.Namespace == "" {
+			return internalerrors.NewInvalidInputError("namespace is required for namespaced resource")
+		}
+		return nil
+	}
+
+	if key.Namespace == "" {
+		key.Namespace = params.Namespace
+	} else if key.Namespace != params.Namespace {
+		return internalerrors.NewInvalidInputError(
+			fmt.Sprintf("namespace %q does not match namespace %q in path", key.Namespace, params.Namespace))
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	repositorytypes "github.com/tsamsiyu/themelio/api/internal/repository/types"
+	servicetypes "github.com/tsamsiyu/themelio/api/internal/service/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+	sdkschema "github.com/tsamsiyu/themelio/sdk/pkg/types/schema"
+)
+
+func TestListResources_AllNamespaces(t *testing.T) {
+	logger := zap.NewNop()
+	mockRepo := mocks.NewMockResourceRepository(t)
+	mockSchema := mocks.NewMockSchemaService(t)
+	service := NewResourceService(logger, mockRepo, mockSchema)
+
+	ctx := context.Background()
+	params := servicetypes.Params{
+		Group:   "example.com",
+		Version: "v1",
+		Kind:    "TestResource",
+	}
+
+	schema := &sdkschema.ObjectSchema{
+		Group: "example.com",
+		Kind:  "TestResource",
+		Scope: sdkschema.ResourceScopeNamespaced,
+	}
+
+	expectedType := &sdkmeta.ObjectType{
+		Group:   "example.com",
+		Version: "v1",
+		Kind:    "TestResource",
+	}
+
+	mockSchema.EXPECT().Get(ctx, "example.com", "TestResource").Return(schema, nil)
+	mockRepo.EXPECT().List(ctx, expectedType, repositorytypes.ListOptions{}).Return(&repositorytypes.ObjectBatch{}, nil)
+
+	_, err := service.ListResources(ctx, params, repositorytypes.ListOptions{})
+	assert.NoError(t, err)
+}
+
+func TestWatchResource_AllNamespaces(t *testing.T) {
+	logger := zap.NewNop()
+	mockRepo := mocks.NewMockResourceRepository(t)
+	mockSchema := mocks.NewMockSchemaService(t)
+	service := NewResourceService(logger, mockRepo, mockSchema)
+
+	ctx := context.Background()
+	params := servicetypes.Params{
+		Group:   "example.com",
+		Version: "v1",
+		Kind:    "TestResource",
+	}
+
+	schema := &sdkschema.ObjectSchema{
+		Group: "example.com",
+		Kind:  "TestResource",
+		Scope: sdkschema.ResourceScopeNamespaced,
+	}
+
+	expectedType := &sdkmeta.ObjectType{
+		Group:   "example.com",
+		Version: "v1",
+		Kind:    "TestResource",
+	}
+
+	mockSchema.EXPECT().Get(ctx, "example.com", "TestResource").Return(schema, nil)
+	mockRepo.EXPECT().Watch(ctx, expectedType, repositorytypes.WatchOptions{}).Return(make(chan repositorytypes.WatchEvent), nil)
+
+	_, err := service.WatchResource(ctx, params, repositorytypes.WatchOptions{})
+	assert.NoError(t, err)
+}
+
+func TestWatchResource_AllKindsOfNamespace(t *testing.T) {
+	logger := zap.NewNop()
+	mockRepo := mocks.NewMockResourceRepository(t)
+	mockSchema := mocks.NewMockSchemaService(t)
+	service := NewResourceService(logger, mockRepo, mockSchema)
+
+	ctx := context.Background()
+	params := servicetypes.Params{
+		Namespace: "default",
+	}
+
+	// No schema is read, the watch spans every kind
+	expectedType := &sdkmeta.ObjectType{Namespace: "default"}
+	mockRepo.EXPECT().Watch(ctx, expectedType, repositorytypes.WatchOptions{Revision: 5}).Return(make(chan repositorytypes.WatchEvent), nil)
+
+	_, err := service.WatchResource(ctx, params, repositorytypes.WatchOptions{Revision: 5})
+	assert.NoError(t, err)
+}
+
+func TestWatchResource_AllKindsSpecFieldSelector(t *testing.T) {
+	logger := zap.NewNop()
+	mockRepo := mocks.NewMockResourceRepository(t)
+	mockSchema := mocks.NewMockSchemaService(t)
+	service := NewResourceService(logger, mockRepo, mockSchema)
+
+	params := servicetypes.Params{
+		FieldSelector: "spec.cidr=10.0.0.0/16",
+	}
+
+	_, err := service.WatchResource(context.Background(), params, repositorytypes.WatchOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "within a kind")
+}
+
+func TestGetResource_NamespaceRequired(t *testing.T) {
+	logger := zap.NewNop()
+	mockRepo := mocks.NewMockResourceRepository(t)
+	mockSchema := mocks.NewMockSchemaService(t)
+	service := NewResourceService(logger, mockRepo, mockSchema)
+
+	ctx := context.Background()
+	params := servicetypes.Params{
+		Group:   "example.com",
+		Version: "v1",
+		Kind:    "TestResource",
+		Name:    "test-resource",
+	}
+
+	schema := &sdkschema.ObjectSchema{
+		Group: "example.com",
+		Kind:  "TestResource",
+		Scope: sdkschema.ResourceScopeNamespaced,
+	}
+
+	mockSchema.EXPECT().Get(ctx, "example.com", "TestResource").Return(schema, nil)
+
+	_, err := service.GetResource(ctx, params)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "namespace is required")
+}
+
+func TestReplaceResource_NamespaceFromPath(t *testing.T) {
+	logger := zap.NewNop()
+	mockRepo := mocks.NewMockResourceRepository(t)
+	mockSchema := mocks.NewMockSchemaService(t)
+	service := NewResourceService(logger, mockRepo, mockSchema)
+
+	ctx := context.Background()
+	params := servicetypes.Params{
+		Group:     "example.com",
+		Version:   "v1",
+		Kind:      "TestResource",
+		Namespace: "default",
+	}
+
+	schema := &sdkschema.ObjectSchema{
+		Group: "example.com",
+		Kind:  "TestResource",
+		Scope: sdkschema.ResourceScopeNamespaced,
+		Versions: []sdkschema.ObjectSchemaVersion{
+			{Name: "v1", Schema: map[string]interface{}{"type": "object"}},
+		},
+	}
+
+	jsonData := []byte(`{"key":{"group":"example.com","version":"v1","kind":"TestResource","name":"test-resource"},"meta":{},"spec":{}}`)
+
+	mockSchema.EXPECT().Get(ctx, "example.com", "TestResource").Return(schema, nil)
+	mockRepo.EXPECT().Replace(ctx, mock.MatchedBy(func(obj *sdkmeta.Object) bool {
+		return obj.ObjectKey.Namespace == "default"
+	}), true).Return(nil)
+
+	err := service.ReplaceResource(ctx, params, jsonData)
+	assert.NoError(t, err)
+}
+
+func TestReplaceResource_NamespaceMismatch(t *testing.T) {
+	logger := zap.NewNop()
+	mockRepo := mocks.NewMockResourceRepository(t)
+	mockSchema := mocks.NewMockSchemaService(t)
+	service := NewResourceService(logger, mockRepo, mockSchema)
+
+	ctx := context.Background()
+	params := servicetypes.Params{
+		Group:     "example.com",
+		Version:   "v1",
+		Kind:      "TestResource",
+		Namespace: "default",
+	}
+
+	schema := &sdkschema.ObjectSchema{
+		Group: "example.com",
+		Kind:  "TestResource",
+		Scope: sdkschema.ResourceScopeNamespaced,
+	}
+
+	jsonData := []byte(`{"key":{"group":"example.com","version":"v1","kind":"TestResource","namespace":"other","name":"test-resource"},"meta":{},"spec":{}}`)
+
+	mockSchema.EXPECT().Get(ctx, "example.com", "TestResource").Return(schema, nil)
+
+	err := service.ReplaceResource(ctx, params, jsonData)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match")
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	servicetypes "github.com/tsamsiyu/themelio/api/internal/service/types"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+func TestApplyExpectedRevision(t *testing.T) {
+	tests := []struct {
+		name         string
+		headerRev    int64
+		bodyRev      int64
+		wantRevision int64
+		wantErr      bool
+	}{
+		{name: "neither set", wantRevision: 0},
+		{name: "body only", bodyRev: 7, wantRevision: 7},
+		{name: "header only", headerRev: 7, wantRevision: 7},
+		{name: "both agree", headerRev: 7, bodyRev: 7, wantRevision: 7},
+		{name: "both disagree", headerRev: 7, bodyRev: 8, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &sdkmeta.Object{}
+			if tt.bodyRev != 0 {
+				obj.SystemMeta = &sdkmeta.SystemMeta{ModRevision: tt.bodyRev}
+			}
+
+			err := applyExpectedRevision(servicetypes.Params{ExpectedRevision: tt.headerRev}, obj)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.wantRevision != 0 {
+				assert.Equal(t, tt.wantRevision, obj.SystemMeta.ModRevision)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	internalerrors "github.com/tsamsiyu/themelio/api/internal/errors"
+	repositorytypes "github.com/tsamsiyu/themelio/api/internal/repository/types"
+	servicetypes "github.com/tsamsiyu/themelio/api/internal/service/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+	sdklabels "github.com/tsamsiyu/themelio/sdk/pkg/labels"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+	sdkschema "github.com/tsamsiyu/themelio/sdk/pkg/types/schema"
+)
+
+func TestListResources_InvalidLabelSelector(t *testing.T) {
+	service := NewResourceService(zap.NewNop(), mocks.NewMockResourceRepository(t), mocks.NewMockSchemaService(t))
+
+	params := servicetypes.Params{Group: "example.com", Version: "v1", Kind: "TestResource", LabelSelector: "env in prod"}
+	_, err := service.ListResources(context.Background(), params, repositorytypes.ListOptions{})
+
+	var invalidInput *internalerrors.InvalidInputError
+	assert.ErrorAs(t, err, &invalidInput)
+}
+
+func TestListResources_FieldSelector(t *testing.T) {
+	mockRepo := mocks.NewMockResourceRepository(t)
+	mockSchema := mocks.NewMockSchemaService(t)
+	service := NewResourceService(zap.NewNop(), mockRepo, mockSchema)
+
+	ctx := context.Background()
+	schema := &sdkschema.ObjectSchema{
+		Group: "example.com",
+		Kind:  "TestResource",
+		Scope: sdkschema.ResourceScopeCluster,
+		Versions: []sdkschema.ObjectSchemaVersion{{
+			Name: "v1",
+			Schema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"type":       "object",
+						"properties": map[string]interface{}{"region": map[string]interface{}{"type": "string"}},
+					},
+				},
+			},
+		}},
+	}
+	mockSchema.EXPECT().Get(ctx, "example.com", "TestResource").Return(schema, nil)
+
+	// Given: A selector on a declared spec field and a system field
+	params := servicetypes.Params{Group: "example.com", Version: "v1", Kind: "TestResource", FieldSelector: "spec.region=eu-west-1,system.deletionTime!="}
+	mockRepo.EXPECT().List(ctx, mock.Anything, mock.MatchedBy(func(options repositorytypes.ListOptions) bool {
+		return options.FieldSelector.String() == "spec.region=eu-west-1,system.deletionTime!="
+	})).Return(&repositorytypes.ObjectBatch{}, nil)
+
+	// When: Listing
+	_, err := service.ListResources(ctx, params, repositorytypes.ListOptions{})
+
+	// Then: The selector is passed on
+	assert.NoError(t, err)
+
+	// When: Selecting on a field the schema doesn't declare
+	params.FieldSelector = "spec.zone=a"
+	_, err = service.ListResources(ctx, params, repositorytypes.ListOptions{})
+
+	// Then: The request is rejected
+	var invalidInput *internalerrors.InvalidInputError
+	assert.ErrorAs(t, err, &invalidInput)
+}
+
+func TestFilterWatchEvent(t *testing.T) {
+	selector, err := sdklabels.Parse("env=prod")
+	assert.NoError(t, err)
+
+	withEnv := func(env string) *sdkmeta.Object {
+		return &sdkmeta.Object{ObjectMeta: &sdkmeta.ObjectMeta{Labels: map[string]string{"env": env}}}
+	}
+
+	tests := []struct {
+		name     string
+		event    repositorytypes.WatchEvent
+		wantType repositorytypes.WatchEventType
+		wantSent bool
+	}{
+		{
+			name:     "added matching",
+			event:    repositorytypes.WatchEvent{Type: repositorytypes.WatchEventTypeAdded, Object: withEnv("prod")},
+			wantType: repositorytypes.WatchEventTypeAdded,
+			wantSent: true,
+		},
+		{
+			name:  "added not matching",
+			event: repositorytypes.WatchEvent{Type: repositorytypes.WatchEventTypeAdded, Object: withEnv("dev")},
+		},
+		{
+			name:     "modified into the selector",
+			event:    repositorytypes.WatchEvent{Type: repositorytypes.WatchEventTypeModified, Object: withEnv("prod"), PrevObject: withEnv("dev")},
+			wantType: repositorytypes.WatchEventTypeAdded,
+			wantSent: true,
+		},
+		{
+			name:     "modified out of the selector",
+			event:    repositorytypes.WatchEvent{Type: repositorytypes.WatchEventTypeModified, Object: withEnv("dev"), PrevObject: withEnv("prod")},
+			wantType: repositorytypes.WatchEventTypeDeleted,
+			wantSent: true,
+		},
+		{
+			name:     "modified within the selector",
+			event:    repositorytypes.WatchEvent{Type: repositorytypes.WatchEventTypeModified, Object: withEnv("prod"), PrevObject: withEnv("prod")},
+			wantType: repositorytypes.WatchEventTypeModified,
+			wantSent: true,
+		},
+		{
+			name:  "modified outside the selector",
+			event: repositorytypes.WatchEvent{Type: repositorytypes.WatchEventTypeModified, Object: withEnv("dev"), PrevObject: withEnv("test")},
+		},
+		{
+			name:     "modified without previous object",
+			event:    repositorytypes.WatchEvent{Type: repositorytypes.WatchEventTypeModified, Object: withEnv("dev")},
+			wantType: repositorytypes.WatchEventTypeDeleted,
+			wantSent: true,
+		},
+		{
+			name:  "deleted not matching",
+			event: repositorytypes.WatchEvent{Type: repositorytypes.WatchEventTypeDeleted, Object: withEnv("dev")},
+		},
+		{
+			name:     "error",
+			event:    repositorytypes.WatchEvent{Type: repositorytypes.WatchEventTypeError},
+			wantType: repositorytypes.WatchEventTypeError,
+			wantSent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, sent := filterWatchEvent(tt.event, matchesSelectors(selector, nil))
+			assert.Equal(t, tt.wantSent, sent)
+			if sent {
+				assert.Equal(t, tt.wantType, event.Type)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	internalerrors "github.com/tsamsiyu/themelio/api/internal/errors"
+	repositorytypes "github.com/tsamsiyu/themelio/api/internal/repository/types"
+	sdkfields "github.com/tsamsiyu/themelio/sdk/pkg/fields"
+	sdklabels "github.com/tsamsiyu/themelio/sdk/pkg/labels"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+	sdkschema "github.com/tsamsiyu/themelio/sdk/pkg/types/schema"
+)
+
+func parseLabelSelector(selector string) (sdklabels.Selector, error) {
+	parsed, err := sdklabels.Parse(selector)
+	if err != nil {
+		return nil, internalerrors.NewInvalidInputError("invalid label selector: " + err.Error())
+	}
+	return parsed, nil
+}
+
+// parseFieldSelector also makes sure spec and status fields are declared by the schema of the version,
+// without a schema they are not allowed at all
+func parseFieldSelector(selector string, schema *sdkschema.ObjectSchema, version string) (sdkfields.Selector, error) {
+	parsed, err := sdkfields.Parse(selector)
+	if err != nil {
+		return nil, internalerrors.NewInvalidInputError("invalid field selector: " + err.Error())
+	}
+
+	for _, requirement := range parsed {
+		if !sdkfields.IsSpecOrStatusField(requirement.Field) {
+			continue
+		}
+		if schema == nil {
+			return nil, internalerrors.NewInvalidInputError(
+				fmt.Sprintf("invalid field selector: field %q can only be selected on within a kind", requirement.Field))
+		}
+		schemaVersion, ok := schema.Version(version)
+		if !ok || !schemaVersion.HasField(requirement.Field) {
+			return nil, internalerrors.NewInvalidInputError(
+				fmt.Sprintf("invalid field selector: field %q is not declared by the schema", requirement.Field))
+		}
+	}
+
+	return parsed, nil
+}
+
+// matchesSelectors reports whether the object satisfies both selectors
+func matchesSelectors(labelSelector sdklabels.Selector, fieldSelector sdkfields.Selector) func(*sdkmeta.Object) bool {
+	return func(object *sdkmeta.Object) bool {
+		return matchesLabels(object, labelSelector) && fieldSelector.Matches(object)
+	}
+}
+
+// filterWatch passes only events of objects the predicate matches
+// An object that stops matching is reported as deleted and one that starts matching as added,
+// so a watcher sees the same set of objects a list with the same selectors would return
+func filterWatch(
+	ctx context.Context,
+	in <-chan repositorytypes.WatchEvent,
+	matches func(*sdkmeta.Object) bool,
+) <-chan repositorytypes.WatchEvent {
+	out := make(chan repositorytypes.WatchEvent)
+
+	go func() {
+		defer close(out)
+
+		for event := range in {
+			event, ok := filterWatchEvent(event, matches)
+			if !ok {
+				continue
+			}
+
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func filterWatchEvent(event repositorytypes.WatchEvent, matches func(*sdkmeta.Object) bool) (repositorytypes.WatchEvent, bool) {
+	switch event.Type {
+	case repositorytypes.WatchEventTypeAdded:
+		return event, matches(event.Object)
+	case repositorytypes.WatchEventTypeDeleted:
+		return event, matches(event.Object)
+	case repositorytypes.WatchEventTypeModified:
+		matchesNow := matches(event.Object)
+
+		// without the previous object the watcher may or may not have seen it, a delete of an unseen object is harmless
+		if event.PrevObject == nil {
+			if !matchesNow {
+				event.Type = repositorytypes.WatchEventTypeDeleted
+			}
+			return event, true
+		}
+
+		matched := matches(event.PrevObject)
+		switch {
+		case matched && matchesNow:
+			return event, true
+		case matchesNow:
+			event.Type = repositorytypes.WatchEventTypeAdded
+			return event, true
+		case matched:
+			event.Type = repositorytypes.WatchEventTypeDeleted
+			return event, true
+		default:
+			return event, false
+		}
+	default:
+		return event, true
+	}
+}
+
+func matchesLabels(object *sdkmeta.Object, selector sdklabels.Selector) bool {
+	if object == nil || object.ObjectMeta == nil {
+		return selector.Matches(nil)
+	}
+	return selector.Matches(object.ObjectMeta.Labels)
+}
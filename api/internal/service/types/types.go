@@ -13,13 +13,24 @@ type Params struct {
 	Kind      string
 	Namespace string
 	Name      string
+	// ExpectedRevision is the mod revision a write is conditional on, zero when the request doesn't set If-Match
+	ExpectedRevision int64
+	// MinRevision lets a get be served from the read cache once it has seen the revision, zero is a quorum read
+	MinRevision int64
+	// LabelSelector restricts lists and watches to objects with matching labels
+	LabelSelector string
+	// FieldSelector restricts lists and watches to objects with matching key, system, spec or status fields
+	FieldSelector string
 }
 
 type ResourceService interface {
 	ReplaceResource(ctx context.Context, params Params, jsonData []byte) error
 	GetResource(ctx context.Context, params Params) (*sdkmeta.Object, error)
-	ListResources(ctx context.Context, params Params) ([]*sdkmeta.Object, error)
-	DeleteResource(ctx context.Context, params Params) error
+	ListResources(ctx context.Context, params Params, options repositorytypes.ListOptions) (*repositorytypes.ObjectBatch, error)
+	DeleteResource(ctx context.Context, params Params, options repositorytypes.DeleteOptions) error
 	PatchResource(ctx context.Context, params Params, patchData []byte) (*sdkmeta.Object, error)
-	WatchResource(ctx context.Context, params Params, revision int64) (<-chan repositorytypes.WatchEvent, error)
+	ReplaceResourceStatus(ctx context.Context, params Params, jsonData []byte) error
+	PatchResourceStatus(ctx context.Context, params Params, patchData []byte) (*sdkmeta.Object, error)
+	RemoveFinalizer(ctx context.Context, params Params, finalizer string) (*sdkmeta.Object, error)
+	WatchResource(ctx context.Context, params Params, options repositorytypes.WatchOptions) (<-chan repositorytypes.WatchEvent, error)
 }
@@ -0,0 +1,247 @@
+package gc
+
+import (
+	"container/heap"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tsamsiyu/themelio/api/internal/lib"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+type queueItem struct {
+	record  types.DeletionRecord
+	readyAt time.Time
+	index   int
+}
+
+// readyHeap orders the items by the time the objects were marked for deletion
+type readyHeap []*queueItem
+
+func (h readyHeap) Len() int { return len(h) }
+
+func (h readyHeap) Less(i, j int) bool {
+	if !h[i].record.Time.Equal(h[j].record.Time) {
+		return h[i].record.Time.Before(h[j].record.Time)
+	}
+	return compareKeys(h[i].record.ObjectKey, h[j].record.ObjectKey) < 0
+}
+
+func (h readyHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *readyHeap) Push(x any) {
+	item := x.(*queueItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *readyHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// delayedHeap orders the items waiting for their backoff by the time they are ready again
+type delayedHeap struct {
+	readyHeap
+}
+
+func (h delayedHeap) Less(i, j int) bool {
+	return h.readyHeap[i].readyAt.Before(h.readyHeap[j].readyAt)
+}
+
+// deletionQueue hands out the deletions oldest first, each object to one consumer at a time
+// A deletion that failed waits for its own backoff before it is handed out again, nothing is dropped
+type deletionQueue struct {
+	mu      sync.Mutex
+	ready   readyHeap
+	delayed delayedHeap
+	items   map[sdkmeta.ObjectKey]*queueItem
+	// processing holds the objects handed out, true once their record is gone
+	processing map[sdkmeta.ObjectKey]bool
+	// dirty holds the records changed while their object was processed, they are queued again when it is done
+	dirty         map[sdkmeta.ObjectKey]types.DeletionRecord
+	backoffs      map[sdkmeta.ObjectKey]*lib.BackoffManager
+	backoffConfig lib.BackoffConfig
+	notify        chan struct{}
+}
+
+func newDeletionQueue(backoffConfig lib.BackoffConfig) *deletionQueue {
+	return &deletionQueue{
+		items:         make(map[sdkmeta.ObjectKey]*queueItem),
+		processing:    make(map[sdkmeta.ObjectKey]bool),
+		dirty:         make(map[sdkmeta.ObjectKey]types.DeletionRecord),
+		backoffs:      make(map[sdkmeta.ObjectKey]*lib.BackoffManager),
+		backoffConfig: backoffConfig,
+		notify:        make(chan struct{}, 1),
+	}
+}
+
+// Add queues the deletion unless it is queued already, a deletion waiting for its backoff keeps waiting
+func (q *deletionQueue) Add(record types.DeletionRecord) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := record.ObjectKey
+	if _, ok := q.processing[key]; ok {
+		q.processing[key] = false
+		q.dirty[key] = record
+		return
+	}
+	if _, ok := q.items[key]; ok {
+		return
+	}
+
+	item := &queueItem{record: record}
+	q.items[key] = item
+	heap.Push(&q.ready, item)
+	q.signal()
+}
+
+// Forget drops the deletion whose record is gone, an object being processed isn't retried anymore
+func (q *deletionQueue) Forget(key sdkmeta.ObjectKey) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.processing[key]; ok {
+		q.processing[key] = true
+	}
+	delete(q.dirty, key)
+	delete(q.backoffs, key)
+
+	item, ok := q.items[key]
+	if !ok {
+		return
+	}
+	delete(q.items, key)
+	if item.readyAt.IsZero() {
+		heap.Remove(&q.ready, item.index)
+	} else {
+		heap.Remove(&q.delayed, item.index)
+	}
+}
+
+// Get blocks until a deletion is ready and hands it out, it returns false once ctx is done
+func (q *deletionQueue) Get(ctx context.Context) (types.DeletionRecord, bool) {
+	for {
+		q.mu.Lock()
+		q.promote(time.Now())
+
+		if q.ready.Len() > 0 {
+			item := heap.Pop(&q.ready).(*queueItem)
+			delete(q.items, item.record.ObjectKey)
+			q.processing[item.record.ObjectKey] = false
+			// the next ready deletion goes to another waiting consumer
+			if q.ready.Len() > 0 {
+				q.signal()
+			}
+			q.mu.Unlock()
+			return item.record, true
+		}
+
+		var timer *time.Timer
+		var wait <-chan time.Time
+		if q.delayed.Len() > 0 {
+			timer = time.NewTimer(time.Until(q.delayed.readyHeap[0].readyAt))
+			wait = timer.C
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+		case <-q.notify:
+		case <-wait:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return types.DeletionRecord{}, false
+		}
+	}
+}
+
+// Done finishes the processing of the object, a record changed in the meantime is queued again
+func (q *deletionQueue) Done(key sdkmeta.ObjectKey) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.processing, key)
+	delete(q.backoffs, key)
+
+	if record, ok := q.dirty[key]; ok {
+		delete(q.dirty, key)
+		item := &queueItem{record: record}
+		q.items[key] = item
+		heap.Push(&q.ready, item)
+		q.signal()
+	}
+}
+
+// Retry finishes the processing of the object and queues it again after its backoff,
+// unless its record is gone
+func (q *deletionQueue) Retry(record types.DeletionRecord) time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := record.ObjectKey
+	forgotten := q.processing[key]
+	delete(q.processing, key)
+	delete(q.dirty, key)
+	if forgotten {
+		return 0
+	}
+
+	backoff, ok := q.backoffs[key]
+	if !ok {
+		backoff = lib.NewBackoffManager(q.backoffConfig)
+		q.backoffs[key] = backoff
+	}
+	delay := backoff.NextBackoff()
+
+	item := &queueItem{record: record, readyAt: time.Now().Add(delay)}
+	q.items[key] = item
+	heap.Push(&q.delayed, item)
+	q.signal()
+	return delay
+}
+
+// Len returns the number of queued deletions, including the ones waiting for their backoff
+func (q *deletionQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// promote moves the deletions whose backoff is over to the ready ones
+func (q *deletionQueue) promote(now time.Time) {
+	for q.delayed.Len() > 0 && !q.delayed.readyHeap[0].readyAt.After(now) {
+		item := heap.Pop(&q.delayed).(*queueItem)
+		item.readyAt = time.Time{}
+		heap.Push(&q.ready, item)
+	}
+}
+
+// signal wakes a consumer waiting in Get without blocking when one is woken already
+func (q *deletionQueue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func compareKeys(a, b sdkmeta.ObjectKey) int {
+	return strings.Compare(keyString(a), keyString(b))
+}
+
+func keyString(key sdkmeta.ObjectKey) string {
+	return strings.Join([]string{key.Group, key.Version, key.Kind, key.Namespace, key.Name}, "/")
+}
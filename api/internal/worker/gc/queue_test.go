@@ -0,0 +1,125 @@
+package gc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tsamsiyu/themelio/api/internal/lib"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+var testBackoff = lib.BackoffConfig{
+	InitialBackoff:    20 * time.Millisecond,
+	MaxBackoff:        time.Second,
+	BackoffMultiplier: 2.0,
+	ResetAfter:        time.Hour,
+}
+
+func newTestRecord(name string, markedAt time.Time) types.DeletionRecord {
+	return types.DeletionRecord{
+		ObjectKey: sdkmeta.ObjectKey{
+			ObjectType: sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: "default"},
+			Name:       name,
+		},
+		Time: markedAt,
+	}
+}
+
+func getRecord(t *testing.T, queue *deletionQueue) types.DeletionRecord {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	record, ok := queue.Get(ctx)
+	require.True(t, ok, "no deletion was handed out")
+	return record
+}
+
+func TestDeletionQueue_OldestFirst(t *testing.T) {
+	queue := newDeletionQueue(testBackoff)
+	now := time.Now()
+
+	// Given: Deletions queued out of order, one of them twice
+	queue.Add(newTestRecord("newest", now))
+	queue.Add(newTestRecord("oldest", now.Add(-2*time.Minute)))
+	queue.Add(newTestRecord("middle", now.Add(-time.Minute)))
+	queue.Add(newTestRecord("oldest", now.Add(-2*time.Minute)))
+
+	// When: They are handed out
+	var names []string
+	for i := 0; i < 3; i++ {
+		names = append(names, getRecord(t, queue).ObjectKey.Name)
+	}
+
+	// Then: The oldest deletion comes first and each one only once
+	assert.Equal(t, []string{"oldest", "middle", "newest"}, names)
+	assert.Equal(t, 0, queue.Len())
+}
+
+func TestDeletionQueue_Retry(t *testing.T) {
+	queue := newDeletionQueue(testBackoff)
+	now := time.Now()
+
+	// Given: A deletion that failed while a newer one waits
+	queue.Add(newTestRecord("failing", now.Add(-time.Minute)))
+	failing := getRecord(t, queue)
+	queue.Add(newTestRecord("other", now))
+	delay := queue.Retry(failing)
+
+	// When: The deletions are handed out
+	first := getRecord(t, queue)
+	start := time.Now()
+	second := getRecord(t, queue)
+
+	// Then: The failed one comes back after its backoff instead of being dropped
+	assert.Equal(t, "other", first.ObjectKey.Name)
+	assert.Equal(t, "failing", second.ObjectKey.Name)
+	assert.Greater(t, delay, time.Duration(0))
+	assert.GreaterOrEqual(t, time.Since(start), delay-5*time.Millisecond)
+}
+
+func TestDeletionQueue_Forget(t *testing.T) {
+	queue := newDeletionQueue(testBackoff)
+	now := time.Now()
+
+	// Given: A deletion waiting for its backoff and another one being processed
+	queue.Add(newTestRecord("delayed", now))
+	queue.Add(newTestRecord("processing", now.Add(time.Second)))
+	delayed := getRecord(t, queue)
+	queue.Retry(delayed)
+	processing := getRecord(t, queue)
+
+	// When: Their records are gone
+	queue.Forget(delayed.ObjectKey)
+	queue.Forget(processing.ObjectKey)
+	queue.Retry(processing)
+
+	// Then: Neither is retried
+	assert.Equal(t, 0, queue.Len())
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, ok := queue.Get(ctx)
+	assert.False(t, ok)
+}
+
+func TestDeletionQueue_AddWhileProcessing(t *testing.T) {
+	queue := newDeletionQueue(testBackoff)
+	now := time.Now()
+
+	// Given: A deletion being processed
+	queue.Add(newTestRecord("resource", now))
+	record := getRecord(t, queue)
+
+	// When: Its record is written again before the processing is done
+	queue.Add(newTestRecord("resource", now.Add(time.Second)))
+	assert.Equal(t, 0, queue.Len())
+	queue.Done(record.ObjectKey)
+
+	// Then: It is handed out again with the new record
+	again := getRecord(t, queue)
+	assert.Equal(t, now.Add(time.Second), again.Time)
+}
@@ -0,0 +1,94 @@
+package gc
+
+import (
+	"hash/fnv"
+	"slices"
+	"sync"
+
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+// shard decides which deletions this member processes, every object belongs to the live member with the
+// highest rendezvous hash of the pair, so a member joining or leaving only moves the objects it gains or had
+type shard struct {
+	identity string
+
+	mu      sync.RWMutex
+	members []string
+	changed chan struct{}
+}
+
+func newShard(identity string) *shard {
+	return &shard{
+		identity: identity,
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// SetMembers replaces the live members, this member always counts as one while it processes deletions
+func (s *shard) SetMembers(members []string) {
+	if !slices.Contains(members, s.identity) {
+		members = append(slices.Clone(members), s.identity)
+	}
+	slices.Sort(members)
+
+	s.mu.Lock()
+	if slices.Equal(s.members, members) {
+		s.mu.Unlock()
+		return
+	}
+	s.members = members
+	s.mu.Unlock()
+
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Members returns the live members the objects are shared among
+func (s *shard) Members() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.members
+}
+
+// Changed receives a value after the members changed
+func (s *shard) Changed() <-chan struct{} {
+	return s.changed
+}
+
+// Owns reports whether the deletion of the object belongs to this member, nothing does until the members are known
+func (s *shard) Owns(key sdkmeta.ObjectKey) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return ownerOf(s.members, key) == s.identity
+}
+
+func ownerOf(members []string, key sdkmeta.ObjectKey) string {
+	objectKey := keyString(key)
+
+	var owner string
+	var best uint64
+	for _, member := range members {
+		h := fnv.New64a()
+		h.Write([]byte(member))
+		h.Write([]byte{0})
+		h.Write([]byte(objectKey))
+		if score := mix64(h.Sum64()); owner == "" || score > best {
+			owner = member
+			best = score
+		}
+	}
+	return owner
+}
+
+// mix64 is the finalizer of splitmix64, fnv alone spreads similar inputs poorly
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,102 @@
+package gc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+func testKeys(count int) []sdkmeta.ObjectKey {
+	keys := make([]sdkmeta.ObjectKey, 0, count)
+	for i := 0; i < count; i++ {
+		keys = append(keys, sdkmeta.ObjectKey{
+			ObjectType: sdkmeta.ObjectType{Group: "example.com", Version: "v1", Kind: "TestResource", Namespace: "default"},
+			Name:       fmt.Sprintf("resource-%d", i),
+		})
+	}
+	return keys
+}
+
+func TestShard_EveryObjectHasOneOwner(t *testing.T) {
+	members := []string{"a", "b", "c"}
+	shards := make([]*shard, 0, len(members))
+	for _, member := range members {
+		s := newShard(member)
+		s.SetMembers(members)
+		shards = append(shards, s)
+	}
+
+	// Given: Objects shared among three members
+	keys := testKeys(3000)
+
+	// When: Every member checks which objects it owns
+	owned := make(map[string]int)
+	for _, key := range keys {
+		owners := 0
+		for i, s := range shards {
+			if s.Owns(key) {
+				owners++
+				owned[members[i]]++
+			}
+		}
+
+		// Then: Each object belongs to exactly one member
+		assert.Equal(t, 1, owners)
+	}
+
+	// Then: The objects are spread over all members
+	for _, member := range members {
+		assert.Greater(t, owned[member], 800, "member %s owns too few objects", member)
+	}
+}
+
+func TestShard_LeavingMemberOnlyMovesItsObjects(t *testing.T) {
+	keys := testKeys(1000)
+
+	// Given: The owners of the objects among three members
+	before := make(map[sdkmeta.ObjectKey]string)
+	for _, key := range keys {
+		before[key] = ownerOf([]string{"a", "b", "c"}, key)
+	}
+
+	// When: A member leaves
+	for _, key := range keys {
+		after := ownerOf([]string{"a", "c"}, key)
+
+		// Then: Only the objects of the member that left get a new owner
+		if before[key] != "b" {
+			assert.Equal(t, before[key], after)
+		}
+	}
+}
+
+func TestShard_Changed(t *testing.T) {
+	s := newShard("a")
+
+	// Given: A shard without members owns nothing
+	assert.False(t, s.Owns(testKeys(1)[0]))
+
+	// When: The members are set without this member
+	s.SetMembers([]string{"b"})
+
+	// Then: The change is signaled and this member counts as one
+	select {
+	case <-s.Changed():
+	default:
+		t.Fatal("members change not signaled")
+	}
+	assert.Equal(t, []string{"a", "b"}, s.Members())
+
+	// When: The same members are set again
+	s.SetMembers([]string{"b", "a"})
+
+	// Then: Nothing is signaled
+	select {
+	case <-s.Changed():
+		t.Fatal("unchanged members signaled")
+	default:
+	}
+}
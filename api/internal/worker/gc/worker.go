@@ -6,205 +6,490 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/tsamsiyu/themelio/api/internal/lib"
+	"github.com/tsamsiyu/themelio/api/internal/repository"
 	"github.com/tsamsiyu/themelio/api/internal/repository/types"
 	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
 )
 
-// DeletionEvent represents a resource that needs to be processed for deletion
-type DeletionEvent struct {
-	ObjectKey sdkmeta.ObjectKey
-	Timestamp time.Time
-}
-
 // Config holds configuration for the GC worker
 type Config struct {
-	PollInterval time.Duration
-	Workers      int
-	LockKey      string
-	LockExp      time.Duration
-	BatchLimit   int
+	Workers int
+	LockKey string
+	LockExp time.Duration
+	// BatchLimit is the page size of listing the deletion records
+	BatchLimit int
+	// ResyncInterval is how often the deletion records are listed again in case the watch missed a change
+	ResyncInterval time.Duration
+	// ScanInterval is how often the owner reference index is walked for children of owners that are gone
+	ScanInterval time.Duration
+	// ScanElection elects the replica that walks the owner reference index
+	ScanElection string
+	// RetryBackoff spaces the attempts of a deletion that failed or waits for other objects
+	RetryBackoff lib.BackoffConfig
+	// MembershipGroup is the group the replicas share the deletions in
+	MembershipGroup string
+	// MembershipTTL is how long a replica that stopped keeps its share of the deletions and the scan election
+	MembershipTTL time.Duration
+	JoinRetry     time.Duration
 }
 
 // DefaultConfig returns default configuration for the GC worker
 func DefaultConfig() *Config {
 	return &Config{
-		PollInterval: 1 * time.Second,
-		Workers:      3,
-		LockKey:      "gc-worker", // todo: generate randomly
-		LockExp:      5 * time.Minute,
-		BatchLimit:   10,
+		Workers:        3,
+		LockKey:        lib.NewIdentity(),
+		LockExp:        5 * time.Minute,
+		BatchLimit:     500,
+		ResyncInterval: 5 * time.Minute,
+		ScanInterval:   10 * time.Minute,
+		ScanElection:   "gc-scanner",
+		RetryBackoff: lib.BackoffConfig{
+			InitialBackoff:    500 * time.Millisecond,
+			MaxBackoff:        30 * time.Second,
+			BackoffMultiplier: 2.0,
+			ResetAfter:        time.Hour,
+		},
+		MembershipGroup: "gc-worker",
+		MembershipTTL:   15 * time.Second,
+		JoinRetry:       5 * time.Second,
 	}
 }
 
 // Worker is responsible for deleting resources and cleaning up owner references of children who reference the deleted resource
-// It picks up resources marked for deletion
-// It does not delete children resource, it marks them for deletion
+// It picks up resources marked for deletion from a watch of the deletion records, oldest first,
+// and retries a deletion that can't be done yet with a backoff of its own
+// It does not delete children resource, it marks them for deletion or orphans them by the propagation policy of the resource
+// The replica elected for it also scans the owner reference index for children whose owner is gone outside of the deletion path,
+// the scan walks the whole index, so it is done by a single replica
 // This is the single source of truth for deleting resources
+// Every replica joins the membership group and processes only the deletions of the objects its shard owns,
+// the objects are shared anew whenever a replica joins or leaves
 type Worker struct {
-	logger    *zap.Logger
-	repo      types.ResourceRepository
-	config    *Config
-	eventChan chan DeletionEvent
-	stopChan  chan struct{}
+	logger     *zap.Logger
+	repo       types.ResourceRepository
+	membership types.Membership
+	election   types.LeaderElection
+	config     *Config
 }
 
-func NewWorker(logger *zap.Logger, repo types.ResourceRepository, config *Config) *Worker {
+func NewWorker(
+	logger *zap.Logger,
+	repo types.ResourceRepository,
+	membership types.Membership,
+	election types.LeaderElection,
+	config *Config,
+) *Worker {
 	if config == nil {
 		config = DefaultConfig()
 	}
 
 	return &Worker{
-		logger:    logger,
-		repo:      repo,
-		config:    config,
-		eventChan: make(chan DeletionEvent, 100),
-		stopChan:  make(chan struct{}),
+		logger:     logger,
+		repo:       repo,
+		membership: membership,
+		election:   election,
+		config:     config,
 	}
 }
 
 func (w *Worker) Start(ctx context.Context) error {
 	w.logger.Info("Starting GC Worker",
-		zap.Duration("pollInterval", w.config.PollInterval),
-		zap.Int("workers", w.config.Workers))
+		zap.Duration("resyncInterval", w.config.ResyncInterval),
+		zap.Int("workers", w.config.Workers),
+		zap.String("identity", w.membership.Identity()))
 
-	defer close(w.stopChan)
-	defer close(w.eventChan)
+	for {
+		if err := w.membership.Join(ctx); err != nil {
+			if ctx.Err() != nil {
+				break
+			}
 
-	go w.producer(ctx)
+			w.logger.Error("Failed to join GC membership group", zap.Error(err))
 
-	for i := 0; i < w.config.Workers; i++ {
-		go w.consumer(ctx, i)
-	}
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.config.JoinRetry):
+			}
+			continue
+		}
 
-	<-ctx.Done()
+		w.serve(ctx)
+
+		if ctx.Err() != nil {
+			leaveCtx, cancel := context.WithTimeout(context.Background(), w.config.JoinRetry)
+			if err := w.membership.Leave(leaveCtx); err != nil {
+				w.logger.Warn("Failed to leave GC membership group", zap.Error(err))
+			}
+			cancel()
+			break
+		}
+	}
 
 	w.logger.Info("GC Worker has stopped")
 
 	return nil
 }
 
-// producer regularly queries ListDeletions and sends events to the channel
-func (w *Worker) producer(ctx context.Context) {
-	ticker := time.NewTicker(w.config.PollInterval)
+// serve processes the deletions of the shard until membership is lost or ctx is done
+func (w *Worker) serve(ctx context.Context) {
+	serveCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-w.membership.Done():
+			w.logger.Warn("GC membership lost, stopping deletions")
+			cancel()
+		case <-serveCtx.Done():
+		}
+	}()
+
+	shard := newShard(w.membership.Identity())
+	queue := newDeletionQueue(w.config.RetryBackoff)
+
+	go w.observeMembers(serveCtx, shard)
+	go w.producer(serveCtx, queue, shard)
+	go w.scanner(serveCtx)
+
+	for i := 0; i < w.config.Workers; i++ {
+		go w.consumer(serveCtx, i, queue, shard)
+	}
+
+	<-serveCtx.Done()
+}
+
+// observeMembers keeps the members of the shard up to date with the live members of the group
+func (w *Worker) observeMembers(ctx context.Context, shard *shard) {
+	for ctx.Err() == nil {
+		members, err := w.membership.Observe(ctx)
+		if err != nil {
+			w.logger.Error("Failed to observe GC members", zap.Error(err))
+		} else {
+			for identities := range members {
+				shard.SetMembers(identities)
+				w.logger.Info("GC members changed", zap.Strings("members", shard.Members()))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(w.config.JoinRetry):
+		}
+	}
+}
+
+// scanner campaigns for the scan election and walks the owner reference index every ScanInterval while it leads
+func (w *Worker) scanner(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := w.election.Campaign(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			w.logger.Error("Failed to campaign for the GC scan", zap.Error(err))
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.config.JoinRetry):
+			}
+			continue
+		}
+
+		w.scanWhileLeading(ctx)
+	}
+}
+
+// scanWhileLeading scans until leadership is lost or ctx is done, leadership is given up then for another replica
+func (w *Worker) scanWhileLeading(ctx context.Context) {
+	ticker := time.NewTicker(w.config.ScanInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
+			resignCtx, cancel := context.WithTimeout(context.Background(), w.config.JoinRetry)
+			if err := w.election.Resign(resignCtx); err != nil {
+				w.logger.Warn("Failed to resign from the GC scan", zap.Error(err))
+			}
+			cancel()
+			return
+		case <-w.election.Done():
+			w.logger.Warn("GC scan leadership lost")
 			return
 		case <-ticker.C:
-			w.pollDeletions(ctx)
+			w.scanOwnerReferences(ctx)
 		}
 	}
 }
 
-// pollDeletions queries the repository for resources marked for deletion
-func (w *Worker) pollDeletions(ctx context.Context) {
-	deletionBatch, err := w.repo.ListDeletions(ctx, w.config.LockKey, w.config.LockExp, w.config.BatchLimit)
-	if err != nil {
-		w.logger.Error("Failed to list deletions", zap.Error(err))
-		return
-	}
+// scanOwnerReferences checks every entry of the owner reference index,
+// children of owners that are gone are marked for deletion and entries of children that are gone are removed
+func (w *Worker) scanOwnerReferences(ctx context.Context) {
+	checks := make(map[types.OwnerReferenceCheck]int)
+	lastKey := ""
 
-	if len(deletionBatch.ObjectKeys) == 0 {
-		return
+	for {
+		batch, err := w.repo.ListOwnerReferences(ctx, lastKey, w.config.BatchLimit)
+		if err != nil {
+			w.logger.Error("Failed to list owner references", zap.Error(err))
+			return
+		}
+
+		for _, entry := range batch.Entries {
+			check, err := w.repo.CheckOwnerReference(ctx, entry)
+			if err != nil {
+				// a child modified in the meantime is checked again by the next scan
+				w.logger.Warn("Failed to check owner reference",
+					zap.Any("parentKey", entry.Parent),
+					zap.Any("childKey", entry.Child),
+					zap.Error(err))
+				continue
+			}
+			checks[check]++
+
+			if check != types.OwnerReferenceValid {
+				w.logger.Info("Settled dangling owner reference",
+					zap.Any("parentKey", entry.Parent),
+					zap.Any("childKey", entry.Child),
+					zap.String("check", string(check)))
+			}
+		}
+
+		if !batch.More || ctx.Err() != nil {
+			break
+		}
+		lastKey = batch.LastKey
 	}
 
-	w.logger.Debug("Found resources marked for deletion", zap.Int("count", len(deletionBatch.ObjectKeys)))
+	w.logger.Debug("Scanned owner references",
+		zap.Int("valid", checks[types.OwnerReferenceValid]),
+		zap.Int("stale", checks[types.OwnerReferenceStale]),
+		zap.Int("orphaned", checks[types.OwnerReferenceOrphaned]),
+		zap.Int("dropped", checks[types.OwnerReferenceDropped]))
+}
+
+// producer lists the deletion records the shard owns into the queue and follows them with a watch,
+// they are listed again every ResyncInterval, whenever the watch breaks and whenever the members change
+func (w *Worker) producer(ctx context.Context, queue *deletionQueue, shard *shard) {
+	backoff := lib.NewBackoffManager(w.config.RetryBackoff)
+
+	for ctx.Err() == nil {
+		records, revision, err := w.repo.ListDeletions(ctx, w.config.BatchLimit)
+		if err != nil {
+			w.logger.Error("Failed to list deletions", zap.Error(err))
 
-	for _, objectKey := range deletionBatch.ObjectKeys {
-		event := DeletionEvent{
-			ObjectKey: objectKey,
-			Timestamp: time.Now(),
+			select {
+			case <-ctx.Done():
+			case <-time.After(backoff.NextBackoff()):
+			}
+			continue
 		}
+		backoff.Reset()
 
-		select {
-		case w.eventChan <- event:
-			w.logger.Debug("Sent deletion event to channel", zap.Any("objectKey", objectKey))
-		case <-ctx.Done():
-			return
-		default:
-			w.logger.Warn("Deletion event channel full, skipping event", zap.Any("objectKey", objectKey))
+		owned := 0
+		for _, record := range records {
+			if shard.Owns(record.ObjectKey) {
+				queue.Add(record)
+				owned++
+			}
 		}
+
+		w.logger.Debug("Resynced deletions",
+			zap.Int("records", len(records)),
+			zap.Int("owned", owned),
+			zap.Int("queued", queue.Len()))
+
+		w.watchDeletions(ctx, queue, shard, revision)
 	}
 }
 
-// consumer processes deletion events from the channel
-func (w *Worker) consumer(ctx context.Context, workerID int) {
-	w.logger.Debug("Starting GC consumer", zap.Int("workerID", workerID))
+// watchDeletions queues the owned deletion records changed after the revision until the next resync is due
+func (w *Worker) watchDeletions(ctx context.Context, queue *deletionQueue, shard *shard, revision int64) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, err := w.repo.WatchDeletions(watchCtx, revision+1)
+	if err != nil {
+		w.logger.Error("Failed to watch deletions", zap.Error(err))
+		return
+	}
+
+	resync := time.NewTimer(w.config.ResyncInterval)
+	defer resync.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			w.logger.Debug("GC consumer stopping", zap.Int("workerID", workerID))
 			return
-		case event, ok := <-w.eventChan:
+		case <-resync.C:
+			return
+		case <-shard.Changed():
+			return
+		case event, ok := <-events:
 			if !ok {
-				w.logger.Debug("Deletion event channel closed", zap.Int("workerID", workerID))
 				return
 			}
-			w.processDeletionEvent(ctx, event, workerID)
+			if event.Error != nil {
+				w.logger.Warn("Deletion watch ended", zap.Error(event.Error))
+				return
+			}
+
+			if event.Removed {
+				queue.Forget(event.Record.ObjectKey)
+			} else if shard.Owns(event.Record.ObjectKey) {
+				queue.Add(event.Record)
+			}
 		}
 	}
 }
 
-// processDeletionEvent handles a single deletion event
-func (w *Worker) processDeletionEvent(ctx context.Context, event DeletionEvent, workerID int) {
-	w.logger.Debug("Processing deletion event",
+// consumer processes the deletions handed out by the queue, the ones another member took over are skipped
+func (w *Worker) consumer(ctx context.Context, workerID int, queue *deletionQueue, shard *shard) {
+	w.logger.Debug("Starting GC consumer", zap.Int("workerID", workerID))
+
+	for {
+		record, ok := queue.Get(ctx)
+		if !ok {
+			w.logger.Debug("GC consumer stopping", zap.Int("workerID", workerID))
+			return
+		}
+
+		if !shard.Owns(record.ObjectKey) {
+			queue.Done(record.ObjectKey)
+			continue
+		}
+
+		done, err := w.processDeletion(ctx, record.ObjectKey, workerID)
+		if err != nil {
+			w.logger.Error("Failed to process deletion",
+				zap.Any("objectKey", record.ObjectKey),
+				zap.Error(err))
+		}
+
+		if done {
+			queue.Done(record.ObjectKey)
+			continue
+		}
+
+		delay := queue.Retry(record)
+		w.logger.Debug("Deletion retried later",
+			zap.Any("objectKey", record.ObjectKey),
+			zap.Duration("backoff", delay))
+	}
+}
+
+// processDeletion handles a single deletion, it returns false when the deletion has to be retried
+func (w *Worker) processDeletion(ctx context.Context, key sdkmeta.ObjectKey, workerID int) (bool, error) {
+	w.logger.Debug("Processing deletion",
 		zap.Int("workerID", workerID),
-		zap.Any("objectKey", event.ObjectKey))
+		zap.Any("objectKey", key))
 
-	resource, err := w.repo.Get(ctx, event.ObjectKey)
+	acquired, err := w.repo.AcquireDeletion(ctx, key, w.config.LockKey, w.config.LockExp)
 	if err != nil {
-		w.logger.Error("Failed to get resource for deletion",
-			zap.Any("objectKey", event.ObjectKey),
-			zap.Error(err))
-		return
+		return false, err
+	}
+	if !acquired {
+		w.logger.Debug("Deletion is locked by another worker", zap.Any("objectKey", key))
+		return false, nil
 	}
 
-	if resource == nil {
-		w.logger.Debug("Resource not found, skipping deletion",
-			zap.Any("objectKey", event.ObjectKey))
-		return
+	done, err := w.deleteLocked(ctx, key)
+	if !done {
+		// the lock is only held while the deletion is processed, so a member taking the object over gets it
+		if releaseErr := w.repo.ReleaseDeletion(ctx, key, w.config.LockKey); releaseErr != nil {
+			w.logger.Warn("Failed to release deletion lock",
+				zap.Any("objectKey", key),
+				zap.Error(releaseErr))
+		}
+	}
+	return done, err
+}
+
+// deleteLocked deletes the resource once nothing holds it anymore, the deletion lock has to be held
+func (w *Worker) deleteLocked(ctx context.Context, key sdkmeta.ObjectKey) (bool, error) {
+	resource, err := w.repo.Get(ctx, key)
+	if err != nil {
+		// the object is gone already, only its deletion record is left
+		if repository.IsNotFoundError(err) {
+			if err := w.repo.Delete(ctx, key, w.config.LockKey); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+		return false, err
+	}
+
+	if len(resource.ObjectMeta.Finalizers) > 0 {
+		if err := w.deferDeletion(ctx, resource); err != nil {
+			return false, err
+		}
+		return true, nil
 	}
 
 	shouldSkip, err := w.shouldSkipDeletion(ctx, resource)
 	if err != nil {
-		w.logger.Error("Failed to check whether deletion should be skipped",
-			zap.Any("objectKey", event.ObjectKey),
-			zap.Error(err))
-		return
+		return false, err
 	}
 
 	if shouldSkip {
-		w.logger.Debug("Skipping deletion", zap.Any("objectKey", event.ObjectKey))
-		return
+		w.logger.Debug("Deletion waits for a blocking owner", zap.Any("objectKey", key))
+		return false, nil
 	}
 
-	err = w.repo.Delete(ctx, event.ObjectKey, w.config.LockKey)
-	if err != nil {
-		w.logger.Error("Failed to delete resource", zap.Any("objectKey", event.ObjectKey), zap.Error(err))
-		return
+	// in the foreground the resource is kept until the children blocking its deletion are gone
+	if resource.SystemMeta.PropagationPolicy == sdkmeta.PropagationPolicyForeground {
+		pending, err := w.repo.MarkChildrenDeleted(ctx, resource, w.config.LockKey)
+		if err != nil {
+			return false, err
+		}
+
+		if pending > 0 {
+			w.logger.Debug("Deletion waits for children",
+				zap.Any("objectKey", key),
+				zap.Int("children", pending))
+			return false, nil
+		}
+	}
+
+	if err := w.repo.Delete(ctx, key, w.config.LockKey); err != nil {
+		return false, err
 	}
 
-	w.logger.Info("Successfully deleted resource", zap.Any("objectKey", event.ObjectKey))
+	w.logger.Info("Successfully deleted resource", zap.Any("objectKey", key))
+	return true, nil
 }
 
-// shouldSkipDeletion checks if deletion should be skipped due to blocking owner references
-func (w *Worker) shouldSkipDeletion(ctx context.Context, resource *sdkmeta.Object) (bool, error) {
-	if len(resource.ObjectMeta.Finalizers) > 0 {
-		return true, nil
+// deferDeletion drops the deletion record of a resource held by finalizers, removing the last one queues it again
+func (w *Worker) deferDeletion(ctx context.Context, resource *sdkmeta.Object) error {
+	if err := w.repo.DeferDeletion(ctx, resource, w.config.LockKey); err != nil {
+		return err
 	}
 
+	w.logger.Debug("Deletion waits for finalizers",
+		zap.Any("objectKey", resource.ObjectKey),
+		zap.Strings("finalizers", resource.ObjectMeta.Finalizers))
+	return nil
+}
+
+// shouldSkipDeletion checks if deletion should be skipped due to blocking owner references
+// An owner that is gone, replaced or being deleted itself doesn't block, a foreground owner waits for its blocking
+// children to be deleted, so holding them back would deadlock both
+func (w *Worker) shouldSkipDeletion(ctx context.Context, resource *sdkmeta.Object) (bool, error) {
 	ownerRefs := resource.ObjectMeta.OwnerReferences
 	for _, ownerRef := range ownerRefs {
 		if ownerRef.BlockOwnerDeletion {
 			parentKey := ownerRef.ToObjectKey()
 			parent, err := w.repo.Get(ctx, parentKey)
 			if err != nil {
+				if repository.IsNotFoundError(err) {
+					continue
+				}
 				return false, err
 			}
-			if parent != nil {
+			// an owner stored before uids were assigned can only be matched by its key
+			replaced := parent.SystemMeta.UID != "" && parent.SystemMeta.UID != ownerRef.UID
+			if !replaced && parent.SystemMeta.DeletionTime == nil {
 				return true, nil // parent resource is blocking deletion
 			}
 		}
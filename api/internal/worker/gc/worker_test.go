@@ -0,0 +1,215 @@
+package gc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/tsamsiyu/themelio/api/internal/repository"
+	"github.com/tsamsiyu/themelio/api/internal/repository/types"
+	"github.com/tsamsiyu/themelio/api/mocks"
+	sdkmeta "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+func TestWorker_ScanOwnerReferences(t *testing.T) {
+	mockRepo := mocks.NewMockResourceRepository(t)
+	config := DefaultConfig()
+	config.BatchLimit = 2
+	worker := NewWorker(zap.NewNop(), mockRepo, nil, nil, config)
+	ctx := context.Background()
+
+	// Given: An index over two pages
+	keys := testKeys(4)
+	parent := keys[0]
+	entries := []types.OwnerReferenceEntry{
+		{Parent: parent, Child: keys[1]},
+		{Parent: parent, Child: keys[2]},
+		{Parent: parent, Child: keys[3]},
+	}
+
+	mockRepo.EXPECT().ListOwnerReferences(ctx, "", 2).
+		Return(&types.OwnerReferenceBatch{Entries: entries[:2], LastKey: "page-1", More: true}, nil)
+	mockRepo.EXPECT().ListOwnerReferences(ctx, "page-1", 2).
+		Return(&types.OwnerReferenceBatch{Entries: entries[2:], LastKey: "page-2"}, nil)
+
+	var checked []types.OwnerReferenceEntry
+	mockRepo.EXPECT().CheckOwnerReference(ctx, mock.Anything).RunAndReturn(
+		func(_ context.Context, entry types.OwnerReferenceEntry) (types.OwnerReferenceCheck, error) {
+			checked = append(checked, entry)
+			return types.OwnerReferenceOrphaned, nil
+		})
+
+	// When: The index is scanned
+	worker.scanOwnerReferences(ctx)
+
+	// Then: Every page is walked and every entry is checked
+	assert.Equal(t, entries, checked)
+}
+
+func TestWorker_ScannerScansWhileLeading(t *testing.T) {
+	mockRepo := mocks.NewMockResourceRepository(t)
+	mockElection := mocks.NewMockLeaderElection(t)
+	config := DefaultConfig()
+	config.ScanInterval = 10 * time.Millisecond
+	worker := NewWorker(zap.NewNop(), mockRepo, nil, mockElection, config)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Given: The replica wins the scan election
+	mockElection.EXPECT().Campaign(mock.Anything).Return(nil).Once()
+	mockElection.EXPECT().Done().Return(make(chan struct{}))
+
+	scanned := make(chan struct{}, 1)
+	mockRepo.EXPECT().ListOwnerReferences(mock.Anything, "", config.BatchLimit).
+		RunAndReturn(func(_ context.Context, _ string, _ int) (*types.OwnerReferenceBatch, error) {
+			select {
+			case scanned <- struct{}{}:
+			default:
+			}
+			return &types.OwnerReferenceBatch{}, nil
+		})
+
+	resigned := make(chan struct{})
+	mockElection.EXPECT().Resign(mock.Anything).Run(func(_ context.Context) { close(resigned) }).Return(nil).Once()
+
+	// When: The scanner runs until the replica stops
+	go worker.scanner(ctx)
+
+	// Then: The index is scanned while leading
+	select {
+	case <-scanned:
+	case <-time.After(time.Second):
+		t.Fatal("index was not scanned")
+	}
+
+	// And: Leadership is given up for another replica once it stops
+	cancel()
+	select {
+	case <-resigned:
+	case <-time.After(time.Second):
+		t.Fatal("leadership was not given up")
+	}
+}
+
+func TestWorker_ForegroundDeletionWithBlockingChild(t *testing.T) {
+	mockRepo := mocks.NewMockResourceRepository(t)
+	worker := NewWorker(zap.NewNop(), mockRepo, nil, nil, DefaultConfig())
+	ctx := context.Background()
+
+	// Given: A parent deleted in the foreground and a child blocking its deletion
+	keys := testKeys(2)
+	parentKey, childKey := keys[0], keys[1]
+	now := time.Now()
+	objects := map[sdkmeta.ObjectKey]*sdkmeta.Object{
+		parentKey: {
+			ObjectKey:  &parentKey,
+			ObjectMeta: &sdkmeta.ObjectMeta{},
+			SystemMeta: &sdkmeta.SystemMeta{
+				UID:               "parent-uid",
+				DeletionTime:      &now,
+				PropagationPolicy: sdkmeta.PropagationPolicyForeground,
+			},
+		},
+		childKey: {
+			ObjectKey: &childKey,
+			ObjectMeta: &sdkmeta.ObjectMeta{
+				OwnerReferences: []sdkmeta.OwnerReference{
+					{TypeMeta: &parentKey.ObjectType, Name: parentKey.Name, UID: "parent-uid", BlockOwnerDeletion: true},
+				},
+			},
+			SystemMeta: &sdkmeta.SystemMeta{UID: "child-uid"},
+		},
+	}
+
+	mockRepo.EXPECT().AcquireDeletion(ctx, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	mockRepo.EXPECT().ReleaseDeletion(ctx, mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockRepo.EXPECT().Get(ctx, mock.Anything).RunAndReturn(
+		func(_ context.Context, key sdkmeta.ObjectKey) (*sdkmeta.Object, error) {
+			if obj, ok := objects[key]; ok {
+				return obj, nil
+			}
+			return nil, repository.NewNotFoundError(key.Name)
+		})
+	mockRepo.EXPECT().MarkChildrenDeleted(ctx, mock.Anything, mock.Anything).RunAndReturn(
+		func(_ context.Context, obj *sdkmeta.Object, _ string) (int, error) {
+			pending := 0
+			for _, child := range objects {
+				for _, ownerRef := range child.ObjectMeta.OwnerReferences {
+					if ownerRef.UID != obj.SystemMeta.UID {
+						continue
+					}
+					if child.SystemMeta.DeletionTime == nil {
+						child.SystemMeta.DeletionTime = &now
+						child.SystemMeta.PropagationPolicy = sdkmeta.PropagationPolicyForeground
+					}
+					if ownerRef.BlockOwnerDeletion {
+						pending++
+					}
+				}
+			}
+			return pending, nil
+		})
+	mockRepo.EXPECT().Delete(ctx, mock.Anything, mock.Anything).RunAndReturn(
+		func(_ context.Context, key sdkmeta.ObjectKey, _ string) error {
+			delete(objects, key)
+			return nil
+		})
+
+	// When: The parent, then the child it marked and then the parent again are processed
+	done, err := worker.processDeletion(ctx, parentKey, 0)
+	require.NoError(t, err)
+	assert.False(t, done, "the parent waits for the blocking child")
+	assert.NotNil(t, objects[childKey].SystemMeta.DeletionTime)
+
+	done, err = worker.processDeletion(ctx, childKey, 0)
+	require.NoError(t, err)
+	assert.True(t, done, "the child is not held by a parent that is being deleted")
+
+	done, err = worker.processDeletion(ctx, parentKey, 0)
+	require.NoError(t, err)
+
+	// Then: Both objects are deleted
+	assert.True(t, done)
+	assert.Empty(t, objects)
+}
+
+func TestWorker_DeletionOfChildWithGoneOwner(t *testing.T) {
+	mockRepo := mocks.NewMockResourceRepository(t)
+	worker := NewWorker(zap.NewNop(), mockRepo, nil, nil, DefaultConfig())
+	ctx := context.Background()
+
+	// Given: A child marked for deletion whose blocking owner is gone
+	keys := testKeys(2)
+	parentKey, childKey := keys[0], keys[1]
+	now := time.Now()
+	child := &sdkmeta.Object{
+		ObjectKey: &childKey,
+		ObjectMeta: &sdkmeta.ObjectMeta{
+			OwnerReferences: []sdkmeta.OwnerReference{
+				{TypeMeta: &parentKey.ObjectType, Name: parentKey.Name, UID: "parent-uid", BlockOwnerDeletion: true},
+			},
+		},
+		SystemMeta: &sdkmeta.SystemMeta{
+			UID:               "child-uid",
+			DeletionTime:      &now,
+			PropagationPolicy: sdkmeta.PropagationPolicyBackground,
+		},
+	}
+
+	mockRepo.EXPECT().AcquireDeletion(ctx, childKey, mock.Anything, mock.Anything).Return(true, nil)
+	mockRepo.EXPECT().Get(ctx, childKey).Return(child, nil)
+	mockRepo.EXPECT().Get(ctx, parentKey).Return(nil, repository.NewNotFoundError(parentKey.Name))
+	mockRepo.EXPECT().Delete(ctx, childKey, mock.Anything).Return(nil)
+
+	// When: The child is processed
+	done, err := worker.processDeletion(ctx, childKey, 0)
+
+	// Then: The child is deleted instead of being retried
+	require.NoError(t, err)
+	assert.True(t, done)
+}
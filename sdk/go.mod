@@ -2,10 +2,19 @@ module github.com/tsamsiyu/themelio/sdk
 
 go 1.24.0
 
-require github.com/xeipuuv/gojsonschema v1.2.0
+require (
+	github.com/gorilla/websocket v1.5.3
+	github.com/xeipuuv/gojsonschema v1.2.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7
+	google.golang.org/grpc v1.75.1
+	google.golang.org/protobuf v1.36.6
+)
 
 require (
 	github.com/stretchr/testify v1.10.0 // indirect
 	github.com/xeipuuv/gojsonpointer v0.0.0-20180127040702-4e3ac2762d5f // indirect
 	github.com/xeipuuv/gojsonreference v0.0.0-20180127040603-bd5ef7bd5415 // indirect
+	golang.org/x/net v0.41.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.26.0 // indirect
 )
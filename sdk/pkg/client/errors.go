@@ -0,0 +1,113 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// APIError represents an error response returned by the API that has no more specific type
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error (status %d): %s", e.StatusCode, e.Message)
+}
+
+func NewAPIError(statusCode int, message string) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Message:    message,
+	}
+}
+
+// NotFoundError represents when a resource or its schema is not found
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+func NewNotFoundError(message string) *NotFoundError {
+	return &NotFoundError{
+		Message: message,
+	}
+}
+
+// InvalidInputError represents when the API rejected the request as invalid
+type InvalidInputError struct {
+	Message string
+	Details []string
+}
+
+func (e *InvalidInputError) Error() string {
+	if len(e.Details) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Message, strings.Join(e.Details, "; "))
+}
+
+func NewInvalidInputError(message string, details []string) *InvalidInputError {
+	return &InvalidInputError{
+		Message: message,
+		Details: details,
+	}
+}
+
+// ConflictError represents when a write was based on a revision that is no longer the current one
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+func NewConflictError(message string) *ConflictError {
+	return &ConflictError{
+		Message: message,
+	}
+}
+
+// ExpiredError represents when the revision a list or watch continues from is no longer available
+type ExpiredError struct {
+	Message string
+}
+
+func (e *ExpiredError) Error() string {
+	return e.Message
+}
+
+func NewExpiredError(message string) *ExpiredError {
+	return &ExpiredError{
+		Message: message,
+	}
+}
+
+// IsNotFoundError checks if an error is a NotFoundError
+func IsNotFoundError(err error) bool {
+	var target *NotFoundError
+	return errors.As(err, &target)
+}
+
+// IsInvalidInputError checks if an error is an InvalidInputError
+func IsInvalidInputError(err error) bool {
+	var target *InvalidInputError
+	return errors.As(err, &target)
+}
+
+// IsConflictError checks if an error is a ConflictError
+func IsConflictError(err error) bool {
+	var target *ConflictError
+	return errors.As(err, &target)
+}
+
+// IsExpiredError checks if an error is an ExpiredError
+func IsExpiredError(err error) bool {
+	var target *ExpiredError
+	return errors.As(err, &target)
+}
@@ -0,0 +1,410 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/grpcapi"
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+// GRPCConfig holds configuration for the gRPC client
+type GRPCConfig struct {
+	Target string
+	// DialOptions are passed to grpc.NewClient, the default config dials without TLS
+	DialOptions             []grpc.DialOption
+	ReconnectInitialBackoff time.Duration
+	ReconnectMaxBackoff     time.Duration
+	// BearerToken is sent with every call when set, watching every object requires the admin token
+	BearerToken string
+}
+
+// DefaultGRPCConfig returns default configuration for the gRPC client
+func DefaultGRPCConfig(target string) *GRPCConfig {
+	return &GRPCConfig{
+		Target:                  target,
+		DialOptions:             []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		ReconnectInitialBackoff: 500 * time.Millisecond,
+		ReconnectMaxBackoff:     30 * time.Second,
+	}
+}
+
+// httpStatuses translates gRPC codes back to the HTTP statuses the REST API replies with,
+// so both clients return the same errors
+var httpStatuses = map[codes.Code]int{
+	codes.InvalidArgument:   http.StatusBadRequest,
+	codes.Unauthenticated:   http.StatusUnauthorized,
+	codes.PermissionDenied:  http.StatusForbidden,
+	codes.NotFound:          http.StatusNotFound,
+	codes.Aborted:           http.StatusConflict,
+	codes.OutOfRange:        http.StatusGone,
+	codes.ResourceExhausted: http.StatusTooManyRequests,
+	codes.Unimplemented:     http.StatusNotImplemented,
+	codes.Unavailable:       http.StatusServiceUnavailable,
+	codes.DeadlineExceeded:  http.StatusGatewayTimeout,
+}
+
+var resourceEventTypes = map[grpcapi.WatchEvent_Type]ResourceEventType{
+	grpcapi.WatchEvent_TYPE_ADDED:    ResourceEventTypeAdded,
+	grpcapi.WatchEvent_TYPE_MODIFIED: ResourceEventTypeModified,
+	grpcapi.WatchEvent_TYPE_DELETED:  ResourceEventTypeDeleted,
+	grpcapi.WatchEvent_TYPE_ERROR:    ResourceEventTypeError,
+}
+
+type grpcClient struct {
+	config    *GRPCConfig
+	conn      *grpc.ClientConn
+	resources grpcapi.ResourceServiceClient
+}
+
+// NewGRPCClient creates a Client talking to the gRPC API
+// Watches reconnect on their own starting from the last seen revision
+func NewGRPCClient(config *GRPCConfig) (Client, error) {
+	conn, err := grpc.NewClient(config.Target, config.DialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+
+	return &grpcClient{
+		config:    config,
+		conn:      conn,
+		resources: grpcapi.NewResourceServiceClient(conn),
+	}, nil
+}
+
+func (c *grpcClient) ReplaceResource(ctx context.Context, params Params, jsonData []byte) error {
+	params.Name = ""
+	_, err := c.resources.ReplaceResource(c.authorize(ctx), &grpcapi.ReplaceResourceRequest{
+		Params: resourceParams(params),
+		Object: jsonData,
+	})
+	return grpcError(err)
+}
+
+func (c *grpcClient) GetResource(ctx context.Context, params Params) (*meta.Object, error) {
+	resp, err := c.resources.GetResource(c.authorize(ctx), &grpcapi.GetResourceRequest{Params: resourceParams(params)})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return unmarshalObject(resp.GetObject())
+}
+
+func (c *grpcClient) ListResources(ctx context.Context, params Params, options ListOptions) (*ObjectList, error) {
+	params.Name = ""
+	resp, err := c.resources.ListResources(c.authorize(ctx), &grpcapi.ListResourcesRequest{
+		Params:   resourceParams(params),
+		Limit:    int32(options.Limit),
+		Continue: options.Continue,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	items := make([]*meta.Object, 0, len(resp.GetItems()))
+	for _, data := range resp.GetItems() {
+		obj, err := unmarshalObject(data)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, obj)
+	}
+
+	return &ObjectList{
+		Items:    items,
+		Total:    len(items),
+		Revision: resp.GetRevision(),
+		Continue: resp.GetContinue(),
+	}, nil
+}
+
+func (c *grpcClient) DeleteResource(ctx context.Context, params Params, options DeleteOptions) error {
+	_, err := c.resources.DeleteResource(c.authorize(ctx), &grpcapi.DeleteResourceRequest{
+		Params:            resourceParams(params),
+		PropagationPolicy: string(options.PropagationPolicy),
+	})
+	return grpcError(err)
+}
+
+func (c *grpcClient) PatchResource(ctx context.Context, params Params, patchData []byte) (*meta.Object, error) {
+	resp, err := c.resources.PatchResource(c.authorize(ctx), &grpcapi.PatchResourceRequest{
+		Params: resourceParams(params),
+		Patch:  patchData,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return unmarshalObject(resp.GetObject())
+}
+
+func (c *grpcClient) ReplaceResourceStatus(ctx context.Context, params Params, jsonData []byte) error {
+	_, err := c.resources.ReplaceResourceStatus(c.authorize(ctx), &grpcapi.ReplaceResourceRequest{
+		Params: resourceParams(params),
+		Object: jsonData,
+	})
+	return grpcError(err)
+}
+
+func (c *grpcClient) PatchResourceStatus(ctx context.Context, params Params, patchData []byte) (*meta.Object, error) {
+	resp, err := c.resources.PatchResourceStatus(c.authorize(ctx), &grpcapi.PatchResourceRequest{
+		Params: resourceParams(params),
+		Patch:  patchData,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return unmarshalObject(resp.GetObject())
+}
+
+func (c *grpcClient) RemoveFinalizer(ctx context.Context, params Params, finalizer string) (*meta.Object, error) {
+	resp, err := c.resources.RemoveFinalizer(c.authorize(ctx), &grpcapi.RemoveFinalizerRequest{
+		Params:    resourceParams(params),
+		Finalizer: finalizer,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return unmarshalObject(resp.GetObject())
+}
+
+func (c *grpcClient) WatchResource(ctx context.Context, params Params, options WatchOptions) (<-chan WatchEvent, error) {
+	params.Name = ""
+
+	stream, err := c.openWatch(ctx, params, options)
+	if err != nil {
+		return nil, err
+	}
+
+	eventChan := make(chan WatchEvent, 100)
+	go c.watchLoop(ctx, params, options, stream, eventChan)
+
+	return eventChan, nil
+}
+
+// openWatch starts the stream and waits for its headers, so a watch the server refuses fails here
+func (c *grpcClient) openWatch(ctx context.Context, params Params, options WatchOptions) (grpcapi.ResourceService_WatchResourceClient, error) {
+	stream, err := c.resources.WatchResource(c.authorize(ctx), &grpcapi.WatchResourceRequest{
+		Params:            resourceParams(params),
+		Revision:          options.Revision,
+		SendInitialEvents: options.SendInitialEvents,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	// no headers means the stream ended before the watch started, Recv returns the reason
+	if header, err := stream.Header(); err != nil || header == nil {
+		if _, err := stream.Recv(); err != nil && err != io.EOF {
+			return nil, grpcError(err)
+		}
+		return nil, NewAPIError(http.StatusServiceUnavailable, "Watch ended")
+	}
+	return stream, nil
+}
+
+// watchLoop streams events from the current stream and reopens it from the last seen revision when it ends
+func (c *grpcClient) watchLoop(
+	ctx context.Context,
+	params Params,
+	options WatchOptions,
+	stream grpcapi.ResourceService_WatchResourceClient,
+	eventChan chan<- WatchEvent,
+) {
+	defer close(eventChan)
+
+	backoff := c.config.ReconnectInitialBackoff
+
+	for {
+		resumed, err := c.readWatchStream(ctx, stream, options, eventChan)
+		if resumed != options {
+			options = resumed
+			backoff = c.config.ReconnectInitialBackoff
+		}
+
+		for err == nil || isRetryableError(err) {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > c.config.ReconnectMaxBackoff {
+				backoff = c.config.ReconnectMaxBackoff
+			}
+
+			stream, err = c.openWatch(ctx, params, options)
+			if err == nil {
+				break
+			}
+		}
+
+		if err != nil {
+			sendEvent(ctx, eventChan, WatchEvent{
+				Type: WatchEventTypeEvent,
+				Payload: &ResourceEvent{
+					Type:      ResourceEventTypeError,
+					Timestamp: time.Now().UTC(),
+					Revision:  options.Revision,
+					Error:     err.Error(),
+				},
+			})
+			return
+		}
+	}
+}
+
+// readWatchStream forwards events until the stream ends, it returns where the watch resumes from
+// and the error the stream ended with, nil when the server closed it or the caller went away
+func (c *grpcClient) readWatchStream(
+	ctx context.Context,
+	stream grpcapi.ResourceService_WatchResourceClient,
+	options WatchOptions,
+	eventChan chan<- WatchEvent,
+) (WatchOptions, error) {
+	for {
+		message, err := stream.Recv()
+		if err == io.EOF || ctx.Err() != nil {
+			return options, nil
+		}
+		if err != nil {
+			return options, grpcError(err)
+		}
+
+		if message.GetType() == grpcapi.WatchEvent_TYPE_ERROR {
+			continue
+		}
+
+		event, err := watchEventFromProto(message)
+		if err != nil {
+			continue
+		}
+
+		options = resumeAfter(options, message.GetRevision(), event)
+
+		if !sendEvent(ctx, eventChan, event) {
+			return options, nil
+		}
+	}
+}
+
+func (c *grpcClient) authorize(ctx context.Context) context.Context {
+	if c.config.BearerToken == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.config.BearerToken)
+}
+
+func resourceParams(params Params) *grpcapi.ResourceParams {
+	return &grpcapi.ResourceParams{
+		Group:         params.Group,
+		Version:       params.Version,
+		Kind:          params.Kind,
+		Namespace:     params.Namespace,
+		Name:          params.Name,
+		LabelSelector: params.LabelSelector,
+		FieldSelector: params.FieldSelector,
+	}
+}
+
+func watchEventFromProto(message *grpcapi.WatchEvent) (WatchEvent, error) {
+	timestamp := message.GetTimestamp().AsTime()
+
+	if message.GetType() == grpcapi.WatchEvent_TYPE_BOOKMARK {
+		return WatchEvent{
+			Type: WatchEventTypeBookmark,
+			Payload: &BookmarkEvent{
+				Timestamp:        timestamp,
+				Revision:         message.GetRevision(),
+				InitialEventsEnd: message.GetInitialEventsEnd(),
+			},
+		}, nil
+	}
+
+	eventType, ok := resourceEventTypes[message.GetType()]
+	if !ok {
+		return WatchEvent{}, fmt.Errorf("unknown watch event type: %s", message.GetType())
+	}
+
+	key := message.GetObjectKey()
+	event := &ResourceEvent{
+		Type: eventType,
+		ObjectKey: meta.ObjectKey{
+			ObjectType: meta.ObjectType{
+				Group:     key.GetGroup(),
+				Version:   key.GetVersion(),
+				Kind:      key.GetKind(),
+				Namespace: key.GetNamespace(),
+			},
+			Name: key.GetName(),
+		},
+		Timestamp: timestamp,
+		Revision:  message.GetRevision(),
+		Error:     message.GetError(),
+	}
+
+	if len(message.GetObject()) > 0 {
+		obj, err := unmarshalObject(message.GetObject())
+		if err != nil {
+			return WatchEvent{}, err
+		}
+		event.Object = obj
+	}
+
+	return WatchEvent{Type: WatchEventTypeEvent, Payload: event}, nil
+}
+
+func unmarshalObject(data []byte) (*meta.Object, error) {
+	var obj meta.Object
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("failed to decode object: %w", err)
+	}
+	return &obj, nil
+}
+
+// grpcError returns the error the REST client would for the same failure
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("request failed: %w", err)
+	}
+
+	statusCode, ok := httpStatuses[st.Code()]
+	if !ok {
+		statusCode = http.StatusInternalServerError
+	}
+
+	body := errorResponse{Error: st.Message()}
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				body.Details = append(body.Details, violation.GetDescription())
+			}
+		}
+	}
+
+	return statusError(statusCode, body)
+}
+
+func sendEvent(ctx context.Context, eventChan chan<- WatchEvent, event WatchEvent) bool {
+	select {
+	case eventChan <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/grpcapi"
+)
+
+// testResourceServer answers gets with getErr and hands each watch to watch
+type testResourceServer struct {
+	grpcapi.UnimplementedResourceServiceServer
+	getErr error
+	watch  func(req *grpcapi.WatchResourceRequest, stream grpcapi.ResourceService_WatchResourceServer) error
+
+	mu       sync.Mutex
+	watches  []*grpcapi.WatchResourceRequest
+	metadata []metadata.MD
+}
+
+func (s *testResourceServer) GetResource(ctx context.Context, req *grpcapi.GetResourceRequest) (*grpcapi.GetResourceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	s.metadata = append(s.metadata, md)
+	s.mu.Unlock()
+
+	if s.getErr != nil {
+		return nil, s.getErr
+	}
+	return &grpcapi.GetResourceResponse{Object: []byte(`{"key":{"kind":"Network","name":"main"},"system":{"modRevision":42}}`)}, nil
+}
+
+func (s *testResourceServer) WatchResource(req *grpcapi.WatchResourceRequest, stream grpcapi.ResourceService_WatchResourceServer) error {
+	s.mu.Lock()
+	s.watches = append(s.watches, req)
+	s.mu.Unlock()
+	return s.watch(req, stream)
+}
+
+func (s *testResourceServer) recorded() []*grpcapi.WatchResourceRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*grpcapi.WatchResourceRequest(nil), s.watches...)
+}
+
+func newTestGRPCClient(t *testing.T, server *testResourceServer) Client {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	grpcapi.RegisterResourceServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	config := DefaultGRPCConfig("passthrough:///bufnet")
+	config.DialOptions = []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	config.ReconnectInitialBackoff = 10 * time.Millisecond
+	config.ReconnectMaxBackoff = 50 * time.Millisecond
+	config.BearerToken = "secret"
+
+	client, err := NewGRPCClient(config)
+	if err != nil {
+		t.Fatalf("NewGRPCClient() error = %v", err)
+	}
+	return client
+}
+
+func TestGRPCClient_GetResource(t *testing.T) {
+	server := &testResourceServer{}
+	client := newTestGRPCClient(t, server)
+
+	obj, err := client.GetResource(context.Background(), Params{Group: "example.com", Version: "v1", Kind: "Network", Name: "main"})
+	if err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	if obj.ObjectKey.Name != "main" || obj.SystemMeta.ModRevision != 42 {
+		t.Errorf("unexpected object %+v", obj)
+	}
+
+	if got := server.metadata[0].Get("authorization"); len(got) != 1 || got[0] != "Bearer secret" {
+		t.Errorf("authorization = %v, want Bearer secret", got)
+	}
+}
+
+func TestGRPCClient_Errors(t *testing.T) {
+	invalid, _ := status.New(codes.InvalidArgument, "Validation failed").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Description: "name is required"}},
+	})
+
+	tests := []struct {
+		name  string
+		err   error
+		check func(error) bool
+	}{
+		{name: "not found", err: status.Error(codes.NotFound, "resource not found"), check: IsNotFoundError},
+		{name: "conflict", err: status.Error(codes.Aborted, "modified concurrently"), check: IsConflictError},
+		{name: "expired", err: status.Error(codes.OutOfRange, "revision 3 has been compacted"), check: IsExpiredError},
+		{name: "invalid", err: invalid.Err(), check: func(err error) bool {
+			e, ok := err.(*InvalidInputError)
+			return ok && len(e.Details) == 1 && e.Details[0] == "name is required"
+		}},
+		{name: "unavailable", err: status.Error(codes.Unavailable, "shutting down"), check: func(err error) bool {
+			e, ok := err.(*APIError)
+			return ok && e.StatusCode == 503
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestGRPCClient(t, &testResourceServer{getErr: tt.err})
+
+			_, err := client.GetResource(context.Background(), Params{Group: "example.com", Version: "v1", Kind: "Network", Name: "main"})
+			if !tt.check(err) {
+				t.Errorf("GetResource() error = %#v", err)
+			}
+		})
+	}
+}
+
+func TestGRPCClient_WatchReconnect(t *testing.T) {
+	var mu sync.Mutex
+	var attempts int64
+
+	server := &testResourceServer{watch: func(req *grpcapi.WatchResourceRequest, stream grpcapi.ResourceService_WatchResourceServer) error {
+		mu.Lock()
+		attempts++
+		attempt := attempts
+		mu.Unlock()
+
+		stream.Send(&grpcapi.WatchEvent{
+			Type:      grpcapi.WatchEvent_TYPE_ADDED,
+			Revision:  attempt * 10,
+			ObjectKey: &grpcapi.ObjectKey{Kind: "Network", Name: "main"},
+			Object:    []byte(`{"key":{"kind":"Network","name":"main"}}`),
+		})
+		// the watch ends after the event
+		return status.Error(codes.Unavailable, "Watch ended")
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := newTestGRPCClient(t, server).WatchResource(ctx, Params{Group: "example.com", Version: "v1", Kind: "Network"}, WatchOptions{Revision: 5})
+	if err != nil {
+		t.Fatalf("WatchResource() error = %v", err)
+	}
+
+	first := receiveResourceEvent(t, events)
+	second := receiveResourceEvent(t, events)
+	if first.Revision != 10 || second.Revision != 20 {
+		t.Errorf("unexpected revisions %d, %d", first.Revision, second.Revision)
+	}
+	if first.Type != ResourceEventTypeAdded || first.Object == nil || first.ObjectKey.Name != "main" {
+		t.Errorf("unexpected event %+v", first)
+	}
+
+	requests := server.recorded()
+	if requests[0].GetRevision() != 5 || requests[1].GetRevision() != 10 {
+		t.Errorf("watched from revisions %d, %d, want 5, 10", requests[0].GetRevision(), requests[1].GetRevision())
+	}
+}
+
+func TestGRPCClient_WatchSendInitialEvents(t *testing.T) {
+	server := &testResourceServer{watch: func(req *grpcapi.WatchResourceRequest, stream grpcapi.ResourceService_WatchResourceServer) error {
+		stream.Send(&grpcapi.WatchEvent{Type: grpcapi.WatchEvent_TYPE_BOOKMARK, Revision: 30, InitialEventsEnd: req.GetSendInitialEvents()})
+		<-stream.Context().Done()
+		return nil
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := newTestGRPCClient(t, server).WatchResource(ctx, Params{Group: "example.com", Version: "v1", Kind: "Network"}, WatchOptions{SendInitialEvents: true})
+	if err != nil {
+		t.Fatalf("WatchResource() error = %v", err)
+	}
+
+	select {
+	case event := <-events:
+		bookmark, ok := event.Payload.(*BookmarkEvent)
+		if !ok || !bookmark.InitialEventsEnd || bookmark.Revision != 30 {
+			t.Errorf("unexpected event %+v, want the initial events end at revision 30", event.Payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+}
+
+func TestGRPCClient_WatchRejected(t *testing.T) {
+	server := &testResourceServer{watch: func(req *grpcapi.WatchResourceRequest, stream grpcapi.ResourceService_WatchResourceServer) error {
+		return status.Error(codes.OutOfRange, "revision 3 has been compacted")
+	}}
+
+	_, err := newTestGRPCClient(t, server).WatchResource(context.Background(), Params{Group: "example.com", Version: "v1", Kind: "Network"}, WatchOptions{Revision: 3})
+	if !IsExpiredError(err) {
+		t.Errorf("WatchResource() error = %v, want ExpiredError", err)
+	}
+}
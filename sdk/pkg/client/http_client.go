@@ -0,0 +1,548 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+const (
+	sseEventError = "error"
+)
+
+// HTTPConfig holds configuration for the HTTP client
+type HTTPConfig struct {
+	BaseURL                 string
+	HTTPClient              *http.Client
+	ReconnectInitialBackoff time.Duration
+	ReconnectMaxBackoff     time.Duration
+	// BearerToken is sent with every request when set, watching every object requires the admin token
+	BearerToken string
+	// WatchTransport selects how watches are streamed, WatchTransportSSE when empty
+	WatchTransport WatchTransport
+}
+
+type WatchTransport string
+
+const (
+	// WatchTransportSSE opens a text/event-stream request per watch
+	WatchTransportSSE WatchTransport = "sse"
+	// WatchTransportWebSocket carries all watches of the client over one WebSocket
+	WatchTransportWebSocket WatchTransport = "websocket"
+)
+
+// DefaultHTTPConfig returns default configuration for the HTTP client
+func DefaultHTTPConfig(baseURL string) *HTTPConfig {
+	return &HTTPConfig{
+		BaseURL:                 baseURL,
+		HTTPClient:              &http.Client{},
+		ReconnectInitialBackoff: 500 * time.Millisecond,
+		ReconnectMaxBackoff:     30 * time.Second,
+	}
+}
+
+type httpClient struct {
+	config  *HTTPConfig
+	baseURL string
+	http    *http.Client
+	socket  *socketWatcher
+}
+
+// NewHTTPClient creates a Client talking to the REST API
+// Watches reconnect on their own starting from the last seen revision, over SSE or a WebSocket shared by all of them
+func NewHTTPClient(config *HTTPConfig) Client {
+	httpClient := &httpClient{
+		config:  config,
+		baseURL: strings.TrimSuffix(config.BaseURL, "/"),
+		http:    config.HTTPClient,
+	}
+	if httpClient.http == nil {
+		httpClient.http = &http.Client{}
+	}
+	if config.WatchTransport == WatchTransportWebSocket {
+		httpClient.socket = newSocketWatcher(httpClient)
+	}
+	return httpClient
+}
+
+type errorResponse struct {
+	Error   string   `json:"error"`
+	Details []string `json:"details"`
+}
+
+type sseMessage struct {
+	ID    string
+	Event string
+	Data  []byte
+}
+
+type sseEnvelope struct {
+	Type    WatchEventType  `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+func (c *httpClient) ReplaceResource(ctx context.Context, params Params, jsonData []byte) error {
+	params.Name = ""
+	return c.do(ctx, http.MethodPut, c.resourcePath(params), jsonData, nil)
+}
+
+func (c *httpClient) GetResource(ctx context.Context, params Params) (*meta.Object, error) {
+	var obj meta.Object
+	if err := c.do(ctx, http.MethodGet, c.resourcePath(params), nil, &obj); err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+
+func (c *httpClient) ListResources(ctx context.Context, params Params, options ListOptions) (*ObjectList, error) {
+	params.Name = ""
+
+	query := url.Values{}
+	if options.Limit > 0 {
+		query.Set("limit", strconv.Itoa(options.Limit))
+	}
+	if options.Continue != "" {
+		query.Set("continue", options.Continue)
+	}
+	if params.LabelSelector != "" {
+		query.Set("labelSelector", params.LabelSelector)
+	}
+	if params.FieldSelector != "" {
+		query.Set("fieldSelector", params.FieldSelector)
+	}
+
+	path := c.resourcePath(params)
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var list ObjectList
+	if err := c.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (c *httpClient) DeleteResource(ctx context.Context, params Params, options DeleteOptions) error {
+	path := c.resourcePath(params)
+	if options.PropagationPolicy != "" {
+		path += "?" + url.Values{"propagationPolicy": {string(options.PropagationPolicy)}}.Encode()
+	}
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+func (c *httpClient) PatchResource(ctx context.Context, params Params, patchData []byte) (*meta.Object, error) {
+	var obj meta.Object
+	if err := c.do(ctx, http.MethodPatch, c.resourcePath(params), patchData, &obj); err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+
+func (c *httpClient) ReplaceResourceStatus(ctx context.Context, params Params, jsonData []byte) error {
+	return c.do(ctx, http.MethodPut, c.resourcePath(params)+"/status", jsonData, nil)
+}
+
+func (c *httpClient) PatchResourceStatus(ctx context.Context, params Params, patchData []byte) (*meta.Object, error) {
+	var obj meta.Object
+	if err := c.do(ctx, http.MethodPatch, c.resourcePath(params)+"/status", patchData, &obj); err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+
+func (c *httpClient) RemoveFinalizer(ctx context.Context, params Params, finalizer string) (*meta.Object, error) {
+	var obj meta.Object
+	if err := c.do(ctx, http.MethodDelete, c.resourcePath(params)+"/finalizers/"+url.PathEscape(finalizer), nil, &obj); err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+
+func (c *httpClient) WatchResource(ctx context.Context, params Params, options WatchOptions) (<-chan WatchEvent, error) {
+	if c.socket != nil {
+		return c.socket.Watch(ctx, params, options)
+	}
+
+	body, err := c.openWatch(ctx, params, options)
+	if err != nil {
+		return nil, err
+	}
+
+	eventChan := make(chan WatchEvent, 100)
+	go c.watchLoop(ctx, params, options, body, eventChan)
+
+	return eventChan, nil
+}
+
+// watchLoop streams events from the current connection and reconnects from the last seen revision when it drops
+func (c *httpClient) watchLoop(ctx context.Context, params Params, options WatchOptions, body io.ReadCloser, eventChan chan<- WatchEvent) {
+	defer close(eventChan)
+
+	backoff := c.config.ReconnectInitialBackoff
+
+	for {
+		resumed := c.readWatchStream(ctx, body, options, eventChan)
+		body.Close()
+
+		if resumed != options {
+			options = resumed
+			backoff = c.config.ReconnectInitialBackoff
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > c.config.ReconnectMaxBackoff {
+				backoff = c.config.ReconnectMaxBackoff
+			}
+
+			var err error
+			body, err = c.openWatch(ctx, params, options)
+			if err == nil {
+				break
+			}
+
+			if !isRetryableError(err) {
+				c.sendWatchEvent(ctx, eventChan, WatchEvent{
+					Type: WatchEventTypeEvent,
+					Payload: &ResourceEvent{
+						Type:      ResourceEventTypeError,
+						Timestamp: time.Now().UTC(),
+						Revision:  options.Revision,
+						Error:     err.Error(),
+					},
+				})
+				return
+			}
+		}
+	}
+}
+
+// readWatchStream forwards events until the stream ends and returns where the watch resumes from
+func (c *httpClient) readWatchStream(ctx context.Context, body io.Reader, options WatchOptions, eventChan chan<- WatchEvent) WatchOptions {
+	messages := make(chan sseMessage)
+	go func() {
+		defer close(messages)
+		readSSEMessages(body, func(msg sseMessage) bool {
+			select {
+			case messages <- msg:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	for msg := range messages {
+		if msg.Event == sseEventError {
+			continue // the server closes the stream after an error, reconnect will follow
+		}
+
+		event, err := decodeWatchEvent(msg.Data)
+		if err != nil {
+			continue
+		}
+
+		if resourceEvent, ok := event.Payload.(*ResourceEvent); ok && resourceEvent.Type == ResourceEventTypeError {
+			continue
+		}
+
+		options = resumeAfter(options, messageRevision(msg, event), event)
+
+		if !c.sendWatchEvent(ctx, eventChan, event) {
+			break
+		}
+	}
+
+	return options
+}
+
+// resumeAfter returns where the watch resumes from once the event with the seen revision is delivered
+// The initial events all carry the revision of the list, a watch dropped before their end sends them again
+// instead of resuming from it and missing the objects that weren't sent yet
+func resumeAfter(options WatchOptions, seen int64, event WatchEvent) WatchOptions {
+	if options.SendInitialEvents {
+		if bookmark, ok := event.Payload.(*BookmarkEvent); ok && bookmark.InitialEventsEnd {
+			return WatchOptions{Revision: bookmark.Revision}
+		}
+		return options
+	}
+
+	if seen > options.Revision {
+		options.Revision = seen
+	}
+	return options
+}
+
+// messageRevision is the revision the stream has been seen up to, taken from the id of the message when it has one
+func messageRevision(msg sseMessage, event WatchEvent) int64 {
+	if id, err := strconv.ParseInt(msg.ID, 10, 64); err == nil {
+		return id
+	}
+
+	switch payload := event.Payload.(type) {
+	case *ResourceEvent:
+		return payload.Revision
+	case *BookmarkEvent:
+		return payload.Revision
+	default:
+		return 0
+	}
+}
+
+func (c *httpClient) sendWatchEvent(ctx context.Context, eventChan chan<- WatchEvent, event WatchEvent) bool {
+	select {
+	case eventChan <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (c *httpClient) openWatch(ctx context.Context, params Params, options WatchOptions) (io.ReadCloser, error) {
+	params.Name = ""
+	query := url.Values{}
+	if options.Revision > 0 {
+		query.Set("revision", strconv.FormatInt(options.Revision, 10))
+	}
+	if options.SendInitialEvents {
+		query.Set("sendInitialEvents", "true")
+	}
+	if params.LabelSelector != "" {
+		query.Set("labelSelector", params.LabelSelector)
+	}
+	if params.FieldSelector != "" {
+		query.Set("fieldSelector", params.FieldSelector)
+	}
+
+	path := c.watchPath(params)
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watch request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	c.authorize(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watch: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, decodeErrorResponse(resp)
+	}
+
+	return resp.Body, nil
+}
+
+func (c *httpClient) do(ctx context.Context, method string, path string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authorize(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return decodeErrorResponse(resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// resourcePath builds the URL of a resource, of its collection if Name is empty
+func (c *httpClient) resourcePath(params Params) string {
+	var b strings.Builder
+	b.WriteString(c.baseURL)
+	b.WriteString("/api/v1/resources/")
+	b.WriteString(url.PathEscape(params.Group))
+	b.WriteString("/")
+	b.WriteString(url.PathEscape(params.Version))
+	if params.Namespace != "" {
+		b.WriteString("/namespaces/")
+		b.WriteString(url.PathEscape(params.Namespace))
+	}
+	b.WriteString("/")
+	b.WriteString(url.PathEscape(params.Kind))
+	if params.Name != "" {
+		b.WriteString("/")
+		b.WriteString(url.PathEscape(params.Name))
+	}
+	return b.String()
+}
+
+// watchPath is the watch URL of the kind, without a kind the objects of every kind are watched,
+// in the namespace when it is set
+func (c *httpClient) watchPath(params Params) string {
+	if params.Kind != "" {
+		return c.resourcePath(params) + "/watch"
+	}
+	if params.Namespace != "" {
+		return c.baseURL + "/api/v1/namespaces/" + url.PathEscape(params.Namespace) + "/watch"
+	}
+	return c.baseURL + "/api/v1/watch"
+}
+
+func (c *httpClient) authorize(req *http.Request) {
+	if c.config.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.BearerToken)
+	}
+}
+
+func decodeErrorResponse(resp *http.Response) error {
+	data, _ := io.ReadAll(resp.Body)
+
+	var body errorResponse
+	if err := json.Unmarshal(data, &body); err != nil || body.Error == "" {
+		body.Error = strings.TrimSpace(string(data))
+		if body.Error == "" {
+			body.Error = http.StatusText(resp.StatusCode)
+		}
+	}
+
+	return statusError(resp.StatusCode, body)
+}
+
+// statusError is the error of a status the API replied with, over HTTP or as a socket error message
+func statusError(statusCode int, body errorResponse) error {
+	switch statusCode {
+	case http.StatusNotFound:
+		return NewNotFoundError(body.Error)
+	case http.StatusBadRequest:
+		return NewInvalidInputError(body.Error, body.Details)
+	case http.StatusConflict:
+		return NewConflictError(body.Error)
+	case http.StatusGone:
+		return NewExpiredError(body.Error)
+	default:
+		return NewAPIError(statusCode, body.Error)
+	}
+}
+
+func isRetryableError(err error) bool {
+	switch e := err.(type) {
+	case *NotFoundError, *InvalidInputError, *ConflictError, *ExpiredError:
+		return false
+	case *APIError:
+		return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+	default:
+		return true
+	}
+}
+
+func decodeWatchEvent(data []byte) (WatchEvent, error) {
+	var envelope sseEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return WatchEvent{}, fmt.Errorf("failed to decode watch event: %w", err)
+	}
+
+	return decodeWatchPayload(envelope.Type, envelope.Payload)
+}
+
+// decodeWatchPayload decodes the payload of the envelope of a watch event by its type
+func decodeWatchPayload(eventType WatchEventType, data json.RawMessage) (WatchEvent, error) {
+	var payload interface{}
+	switch eventType {
+	case WatchEventTypeEvent:
+		payload = &ResourceEvent{}
+	case WatchEventTypeConnected:
+		payload = &ConnectedEvent{}
+	case WatchEventTypeHeartbeat:
+		payload = &HeartbeatEvent{}
+	case WatchEventTypeBookmark:
+		payload = &BookmarkEvent{}
+	default:
+		return WatchEvent{}, fmt.Errorf("unknown watch event type: %s", eventType)
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, payload); err != nil {
+			return WatchEvent{}, fmt.Errorf("failed to decode %s payload: %w", eventType, err)
+		}
+	}
+
+	return WatchEvent{Type: eventType, Payload: payload}, nil
+}
+
+// readSSEMessages parses a text/event-stream and calls emit for every dispatched message until emit returns false
+func readSSEMessages(r io.Reader, emit func(sseMessage) bool) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var msg sseMessage
+	var data [][]byte
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if len(data) > 0 {
+				msg.Data = bytes.Join(data, []byte("\n"))
+				if !emit(msg) {
+					return
+				}
+			}
+			msg = sseMessage{}
+			data = nil
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			msg.ID = value
+		case "event":
+			msg.Event = value
+		case "data":
+			data = append(data, []byte(value))
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestClient(server *httptest.Server) Client {
+	config := DefaultHTTPConfig(server.URL)
+	config.ReconnectInitialBackoff = 10 * time.Millisecond
+	config.ReconnectMaxBackoff = 50 * time.Millisecond
+	return NewHTTPClient(config)
+}
+
+func TestHTTPClient_ResourcePath(t *testing.T) {
+	c := NewHTTPClient(DefaultHTTPConfig("http://localhost:8080/")).(*httpClient)
+
+	tests := []struct {
+		name   string
+		params Params
+		want   string
+	}{
+		{
+			name:   "cluster collection",
+			params: Params{Group: "example.com", Version: "v1", Kind: "Network"},
+			want:   "http://localhost:8080/api/v1/resources/example.com/v1/Network",
+		},
+		{
+			name:   "namespaced object",
+			params: Params{Group: "example.com", Version: "v1", Kind: "Subnet", Namespace: "default", Name: "a"},
+			want:   "http://localhost:8080/api/v1/resources/example.com/v1/namespaces/default/Subnet/a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.resourcePath(tt.params); got != tt.want {
+				t.Errorf("resourcePath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_GetResource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/resources/example.com/v1/Network/main" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"key":{"group":"example.com","version":"v1","kind":"Network","name":"main"},"meta":{},"spec":{"cidr":"10.0.0.0/16"}}`)
+	}))
+	defer server.Close()
+
+	obj, err := newTestClient(server).GetResource(context.Background(), Params{Group: "example.com", Version: "v1", Kind: "Network", Name: "main"})
+	if err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	if obj.ObjectKey.Name != "main" {
+		t.Errorf("GetResource() name = %s, want main", obj.ObjectKey.Name)
+	}
+}
+
+func TestHTTPClient_ListResources_Paging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("limit"); got != "2" {
+			t.Errorf("limit = %q, want 2", got)
+		}
+		if r.URL.Query().Get("continue") == "" {
+			fmt.Fprint(w, `{"items":[],"total":0,"revision":10,"continue":"next"}`)
+			return
+		}
+		fmt.Fprint(w, `{"items":[],"total":0,"revision":10}`)
+	}))
+	defer server.Close()
+
+	c := newTestClient(server)
+	params := Params{Group: "example.com", Version: "v1", Kind: "Network"}
+
+	first, err := c.ListResources(context.Background(), params, ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListResources() error = %v", err)
+	}
+	if first.Continue != "next" {
+		t.Fatalf("ListResources() continue = %q, want next", first.Continue)
+	}
+
+	last, err := c.ListResources(context.Background(), params, ListOptions{Limit: 2, Continue: first.Continue})
+	if err != nil {
+		t.Fatalf("ListResources() error = %v", err)
+	}
+	if last.Continue != "" {
+		t.Errorf("ListResources() continue = %q on last page", last.Continue)
+	}
+}
+
+func TestHTTPClient_ListResources_Selectors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("labelSelector"); got != "env=prod,tier in (web,api)" {
+			t.Errorf("labelSelector = %q", got)
+		}
+		if got := r.URL.Query().Get("fieldSelector"); got != "spec.region=eu-west-1" {
+			t.Errorf("fieldSelector = %q", got)
+		}
+		fmt.Fprint(w, `{"items":[],"total":0,"revision":10}`)
+	}))
+	defer server.Close()
+
+	c := newTestClient(server)
+	params := Params{Group: "example.com", Version: "v1", Kind: "Network", LabelSelector: "env=prod,tier in (web,api)", FieldSelector: "spec.region=eu-west-1"}
+
+	if _, err := c.ListResources(context.Background(), params, ListOptions{}); err != nil {
+		t.Fatalf("ListResources() error = %v", err)
+	}
+}
+
+func TestHTTPClient_ReplaceResourceStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/v1/resources/example.com/v1/namespaces/default/Subnet/a/status" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `{"OK":true}`)
+	}))
+	defer server.Close()
+
+	params := Params{Group: "example.com", Version: "v1", Kind: "Subnet", Namespace: "default", Name: "a"}
+	if err := newTestClient(server).ReplaceResourceStatus(context.Background(), params, []byte(`{"status":{}}`)); err != nil {
+		t.Fatalf("ReplaceResourceStatus() error = %v", err)
+	}
+}
+
+func TestHTTPClient_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		check  func(error) bool
+	}{
+		{
+			name:   "not found",
+			status: http.StatusNotFound,
+			body:   `{"error":"resource /example.com/v1/Network/main not found"}`,
+			check:  IsNotFoundError,
+		},
+		{
+			name:   "invalid input",
+			status: http.StatusBadRequest,
+			body:   `{"error":"Validation failed","details":["spec.cidr is required"]}`,
+			check:  IsInvalidInputError,
+		},
+		{
+			name:   "conflict",
+			status: http.StatusConflict,
+			body:   `{"error":"resource /example.com/v1/Network/main was modified concurrently"}`,
+			check:  IsConflictError,
+		},
+		{
+			name:   "expired",
+			status: http.StatusGone,
+			body:   `{"error":"revision 10 has been compacted"}`,
+			check:  IsExpiredError,
+		},
+		{
+			name:   "internal error",
+			status: http.StatusInternalServerError,
+			body:   `{"error":"Internal server error"}`,
+			check: func(err error) bool {
+				apiErr, ok := err.(*APIError)
+				return ok && apiErr.StatusCode == http.StatusInternalServerError
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			err := newTestClient(server).DeleteResource(context.Background(), Params{Group: "example.com", Version: "v1", Kind: "Network", Name: "main"}, DeleteOptions{})
+			if err == nil || !tt.check(err) {
+				t.Errorf("DeleteResource() error = %v (%T)", err, err)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_WatchResource_Reconnect(t *testing.T) {
+	var mu sync.Mutex
+	var revisions []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		revisions = append(revisions, r.URL.Query().Get("revision"))
+		attempt := len(revisions)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: connected\ndata: {\"type\":\"connected\",\"payload\":{\"message\":\"ok\"}}\n\n")
+		fmt.Fprintf(w, "event: event\ndata: {\"type\":\"event\",\"payload\":{\"type\":\"added\",\"revision\":%d,\"objectKey\":{\"name\":\"n%d\"}}}\n\n", attempt*10, attempt)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := newTestClient(server).WatchResource(ctx, Params{Group: "example.com", Version: "v1", Kind: "Network"}, WatchOptions{Revision: 5})
+	if err != nil {
+		t.Fatalf("WatchResource() error = %v", err)
+	}
+
+	var resourceEvents []*ResourceEvent
+	for event := range events {
+		if re, ok := event.Payload.(*ResourceEvent); ok {
+			resourceEvents = append(resourceEvents, re)
+		}
+		if len(resourceEvents) == 2 {
+			cancel()
+			break
+		}
+	}
+
+	if resourceEvents[0].Revision != 10 || resourceEvents[1].Revision != 20 {
+		t.Errorf("unexpected revisions %d, %d", resourceEvents[0].Revision, resourceEvents[1].Revision)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if revisions[0] != "5" || revisions[1] != "10" {
+		t.Errorf("watch requested revisions %v, want [5 10 ...]", revisions)
+	}
+}
+
+func TestHTTPClient_WatchResource_ResumeFromBookmark(t *testing.T) {
+	var mu sync.Mutex
+	var revisions []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		revisions = append(revisions, r.URL.Query().Get("revision"))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: 42\nevent: bookmark\ndata: {\"type\":\"bookmark\",\"payload\":{\"revision\":42}}\n\n")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := newTestClient(server).WatchResource(ctx, Params{Group: "example.com", Version: "v1", Kind: "Network"}, WatchOptions{Revision: 5})
+	if err != nil {
+		t.Fatalf("WatchResource() error = %v", err)
+	}
+
+	var bookmarks []*BookmarkEvent
+	for event := range events {
+		if bookmark, ok := event.Payload.(*BookmarkEvent); ok {
+			bookmarks = append(bookmarks, bookmark)
+		}
+		if len(bookmarks) == 2 {
+			cancel()
+			break
+		}
+	}
+
+	if bookmarks[0].Revision != 42 {
+		t.Errorf("bookmark revision = %d, want 42", bookmarks[0].Revision)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if revisions[0] != "5" || revisions[1] != "42" {
+		t.Errorf("watch requested revisions %v, want [5 42 ...]", revisions)
+	}
+}
+
+func TestHTTPClient_WatchResource_SendInitialEvents(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		attempt := len(queries)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		if attempt > 2 {
+			return
+		}
+		// the first stream drops before the initial events end
+		fmt.Fprint(w, "event: event\ndata: {\"type\":\"event\",\"payload\":{\"type\":\"added\",\"revision\":30,\"objectKey\":{\"name\":\"a\"}}}\n\n")
+		if attempt == 2 {
+			fmt.Fprint(w, "id: 30\nevent: bookmark\ndata: {\"type\":\"bookmark\",\"payload\":{\"revision\":30,\"initialEventsEnd\":true}}\n\n")
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := newTestClient(server).WatchResource(ctx, Params{Group: "example.com", Version: "v1", Kind: "Network"}, WatchOptions{SendInitialEvents: true})
+	if err != nil {
+		t.Fatalf("WatchResource() error = %v", err)
+	}
+
+	var end *BookmarkEvent
+	for event := range events {
+		if bookmark, ok := event.Payload.(*BookmarkEvent); ok && bookmark.InitialEventsEnd {
+			end = bookmark
+			break
+		}
+	}
+	if end == nil || end.Revision != 30 {
+		t.Fatalf("initial events end = %+v, want a bookmark at revision 30", end)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		count := len(queries)
+		mu.Unlock()
+		if count >= 3 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"sendInitialEvents=true", "sendInitialEvents=true", "revision=30"}
+	if len(queries) < 3 || queries[0] != want[0] || queries[1] != want[1] || queries[2] != want[2] {
+		t.Errorf("watch requested %v, want %v", queries, want)
+	}
+}
+
+func TestHTTPClient_WatchResource_AllKinds(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		wantPath  string
+	}{
+		{"every object", "", "/api/v1/watch"},
+		{"every kind of the namespace", "default", "/api/v1/namespaces/default/watch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path, authorization string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				authorization = r.Header.Get("Authorization")
+				w.WriteHeader(http.StatusForbidden)
+			}))
+			defer server.Close()
+
+			config := DefaultHTTPConfig(server.URL)
+			config.BearerToken = "secret"
+
+			_, err := NewHTTPClient(config).WatchResource(context.Background(), Params{Namespace: tt.namespace}, WatchOptions{})
+			if err == nil {
+				t.Fatal("WatchResource() error = nil, want APIError")
+			}
+			if path != tt.wantPath {
+				t.Errorf("path = %q, want %q", path, tt.wantPath)
+			}
+			if authorization != "Bearer secret" {
+				t.Errorf("Authorization = %q, want bearer token", authorization)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_WatchResource_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"resource /schema/example.com/Network not found"}`)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server).WatchResource(context.Background(), Params{Group: "example.com", Version: "v1", Kind: "Network"}, WatchOptions{})
+	if !IsNotFoundError(err) {
+		t.Errorf("WatchResource() error = %v, want NotFoundError", err)
+	}
+}
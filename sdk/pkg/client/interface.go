@@ -13,6 +13,10 @@ type Params struct {
 	Kind      string
 	Namespace string
 	Name      string
+	// LabelSelector restricts lists and watches to objects with matching labels, see the labels package
+	LabelSelector string
+	// FieldSelector restricts lists and watches to objects with matching fields, see the fields package
+	FieldSelector string
 }
 
 type WatchEventType string
@@ -21,6 +25,7 @@ const (
 	WatchEventTypeEvent     WatchEventType = "event"
 	WatchEventTypeConnected WatchEventType = "connected"
 	WatchEventTypeHeartbeat WatchEventType = "heartbeat"
+	WatchEventTypeBookmark  WatchEventType = "bookmark"
 )
 
 type ResourceEventType string
@@ -52,16 +57,62 @@ type HeartbeatEvent struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// BookmarkEvent carries the latest revision of the watch, a watch resumed from it misses no events
+type BookmarkEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Revision  int64     `json:"revision"`
+	// InitialEventsEnd marks the bookmark that follows the current objects of a watch started with them
+	InitialEventsEnd bool `json:"initialEventsEnd,omitempty"`
+}
+
+// WatchEvent is the envelope of every message of a watch stream
+// Payload holds *ResourceEvent, *ConnectedEvent, *HeartbeatEvent or *BookmarkEvent depending on Type
 type WatchEvent struct {
 	Type    WatchEventType `json:"type"`
 	Payload interface{}    `json:"payload"`
 }
 
+// ObjectList is a page of objects together with the revision it was read at
+type ObjectList struct {
+	Items    []*meta.Object `json:"items"`
+	Total    int            `json:"total"`
+	Revision int64          `json:"revision"`
+	// Continue is passed in ListOptions to get the next page, empty on the last page
+	Continue string `json:"continue"`
+}
+
+// ListOptions selects a page of a list, zero Limit lists all objects at once
+// All pages of one list are read at the revision of the first page
+type ListOptions struct {
+	Limit    int
+	Continue string
+}
+
+// WatchOptions tell where a watch starts, zero Revision starts it at the current revision
+// With SendInitialEvents the current objects come first as added events, followed by a BookmarkEvent with
+// InitialEventsEnd set, so no list is needed before the watch. It can't be combined with Revision
+type WatchOptions struct {
+	Revision          int64
+	SendInitialEvents bool
+}
+
+// DeleteOptions tell what happens to the children of a deleted object, zero PropagationPolicy is background
+type DeleteOptions struct {
+	PropagationPolicy meta.PropagationPolicy
+}
+
 type Client interface {
 	ReplaceResource(ctx context.Context, params Params, jsonData []byte) error
 	GetResource(ctx context.Context, params Params) (*meta.Object, error)
-	ListResources(ctx context.Context, params Params) ([]*meta.Object, error)
-	DeleteResource(ctx context.Context, params Params) error
+	ListResources(ctx context.Context, params Params, options ListOptions) (*ObjectList, error)
+	DeleteResource(ctx context.Context, params Params, options DeleteOptions) error
 	PatchResource(ctx context.Context, params Params, patchData []byte) (*meta.Object, error)
-	WatchResource(ctx context.Context, params Params, revision int64) (<-chan WatchEvent, error)
+	// ReplaceResourceStatus and PatchResourceStatus write only the status, the spec and metadata are left as stored
+	ReplaceResourceStatus(ctx context.Context, params Params, jsonData []byte) error
+	PatchResourceStatus(ctx context.Context, params Params, patchData []byte) (*meta.Object, error)
+	// RemoveFinalizer removes one finalizer by name, the deletion of an object waiting for it resumes once none is left
+	RemoveFinalizer(ctx context.Context, params Params, finalizer string) (*meta.Object, error)
+	// WatchResource watches the kind, across all namespaces when Namespace is empty,
+	// and without a Kind the objects of every kind, every event then carries the ObjectKey of its object
+	WatchResource(ctx context.Context, params Params, options WatchOptions) (<-chan WatchEvent, error)
 }
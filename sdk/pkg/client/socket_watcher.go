@@ -0,0 +1,453 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	socketMessageError = "error"
+)
+
+// socketRequest is a message to the server, see the WebSocket watch handler of the API
+type socketRequest struct {
+	Type              string        `json:"type"`
+	Subscription      string        `json:"subscription"`
+	Params            *socketParams `json:"params,omitempty"`
+	Revision          int64         `json:"revision,omitempty"`
+	SendInitialEvents bool          `json:"sendInitialEvents,omitempty"`
+}
+
+type socketParams struct {
+	Group         string `json:"group,omitempty"`
+	Version       string `json:"version,omitempty"`
+	Kind          string `json:"kind,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+	FieldSelector string `json:"fieldSelector,omitempty"`
+}
+
+// socketMessage is the envelope of the SSE stream tagged with the subscription it belongs to
+type socketMessage struct {
+	Subscription string          `json:"subscription"`
+	Type         WatchEventType  `json:"type"`
+	Payload      json.RawMessage `json:"payload"`
+}
+
+type socketErrorPayload struct {
+	errorResponse
+	Code int `json:"code"`
+}
+
+// socketWatcher carries all watches of a client over one WebSocket, it is dialed with the first watch
+// and closed after the last one ends. When the socket drops every watch on it is subscribed again from
+// where it has been seen up to on a new one. Events are read one at a time, a watch that isn't consumed holds up the others
+type socketWatcher struct {
+	client        *httpClient
+	dialer        *websocket.Dialer
+	conn          *websocket.Conn
+	subscriptions map[string]*socketSubscription
+	nextID        int64
+	mu            sync.Mutex
+	// dialMu makes watches started together share the socket one of them dials
+	dialMu sync.Mutex
+	// writeMu serializes writes, a connection takes one writer at a time
+	writeMu sync.Mutex
+}
+
+type socketSubscription struct {
+	id     string
+	params Params
+	ctx    context.Context
+	events chan WatchEvent
+	// started gets the result of the first subscribe, the watch isn't returned before it
+	started chan error
+	// conn, options, backoff and isStarted are guarded by the mu of the watcher
+	conn      *websocket.Conn
+	options   WatchOptions
+	backoff   time.Duration
+	isStarted bool
+	// closed is guarded by closeMu, events is closed only while nothing is being sent on it
+	closed  bool
+	closeMu sync.Mutex
+}
+
+func newSocketWatcher(client *httpClient) *socketWatcher {
+	return &socketWatcher{
+		client:        client,
+		dialer:        &websocket.Dialer{Proxy: http.ProxyFromEnvironment, HandshakeTimeout: 45 * time.Second},
+		subscriptions: make(map[string]*socketSubscription),
+	}
+}
+
+func (w *socketWatcher) Watch(ctx context.Context, params Params, options WatchOptions) (<-chan WatchEvent, error) {
+	params.Name = ""
+
+	w.mu.Lock()
+	w.nextID++
+	subscription := &socketSubscription{
+		id:      strconv.FormatInt(w.nextID, 10),
+		params:  params,
+		ctx:     ctx,
+		events:  make(chan WatchEvent, 100),
+		started: make(chan error, 1),
+		options: options,
+		backoff: w.client.config.ReconnectInitialBackoff,
+	}
+	w.subscriptions[subscription.id] = subscription
+	w.mu.Unlock()
+
+	conn, err := w.connection(ctx)
+	if err != nil {
+		w.remove(subscription)
+		return nil, err
+	}
+	w.subscribe(subscription, conn)
+
+	select {
+	case err := <-subscription.started:
+		if err != nil {
+			w.remove(subscription)
+			return nil, err
+		}
+	case <-ctx.Done():
+		w.unsubscribe(subscription)
+		return nil, ctx.Err()
+	}
+
+	go func() {
+		<-ctx.Done()
+		w.unsubscribe(subscription)
+	}()
+
+	return subscription.events, nil
+}
+
+// connection returns the socket, dialing it when there is none
+func (w *socketWatcher) connection(ctx context.Context) (*websocket.Conn, error) {
+	w.dialMu.Lock()
+	defer w.dialMu.Unlock()
+
+	w.mu.Lock()
+	conn := w.conn
+	w.mu.Unlock()
+	if conn != nil {
+		return conn, nil
+	}
+
+	header := http.Header{}
+	if w.client.config.BearerToken != "" {
+		header.Set("Authorization", "Bearer "+w.client.config.BearerToken)
+	}
+
+	conn, resp, err := w.dialer.DialContext(ctx, w.socketURL(), header)
+	if err != nil {
+		if resp != nil {
+			defer resp.Body.Close()
+			return nil, decodeErrorResponse(resp)
+		}
+		return nil, fmt.Errorf("failed to open watch socket: %w", err)
+	}
+
+	w.mu.Lock()
+	w.conn = conn
+	w.mu.Unlock()
+
+	go w.readLoop(conn)
+	return conn, nil
+}
+
+func (w *socketWatcher) socketURL() string {
+	base := w.client.baseURL
+	if strings.HasPrefix(base, "https://") {
+		base = "wss://" + strings.TrimPrefix(base, "https://")
+	} else {
+		base = "ws://" + strings.TrimPrefix(base, "http://")
+	}
+	return base + "/api/v1/ws"
+}
+
+// subscribe asks the server for the events of the subscription after its last seen revision
+func (w *socketWatcher) subscribe(subscription *socketSubscription, conn *websocket.Conn) {
+	w.mu.Lock()
+	subscription.conn = conn
+	options := subscription.options
+	w.mu.Unlock()
+
+	params := subscription.params
+	w.write(conn, socketRequest{
+		Type:              "subscribe",
+		Subscription:      subscription.id,
+		Revision:          options.Revision,
+		SendInitialEvents: options.SendInitialEvents,
+		Params: &socketParams{
+			Group:         params.Group,
+			Version:       params.Version,
+			Kind:          params.Kind,
+			Namespace:     params.Namespace,
+			LabelSelector: params.LabelSelector,
+			FieldSelector: params.FieldSelector,
+		},
+	})
+}
+
+// unsubscribe ends a watch the caller is done with
+func (w *socketWatcher) unsubscribe(subscription *socketSubscription) {
+	w.mu.Lock()
+	_, active := w.subscriptions[subscription.id]
+	conn := subscription.conn
+	w.mu.Unlock()
+
+	if active && conn != nil {
+		w.write(conn, socketRequest{Type: "unsubscribe", Subscription: subscription.id})
+	}
+	w.remove(subscription)
+}
+
+// remove forgets the subscription and closes its events, the socket is closed with the last subscription
+func (w *socketWatcher) remove(subscription *socketSubscription) {
+	w.mu.Lock()
+	delete(w.subscriptions, subscription.id)
+	var idle *websocket.Conn
+	if len(w.subscriptions) == 0 {
+		idle = w.conn
+		w.conn = nil
+	}
+	w.mu.Unlock()
+
+	if idle != nil {
+		idle.Close()
+	}
+
+	subscription.closeMu.Lock()
+	defer subscription.closeMu.Unlock()
+	if !subscription.closed {
+		subscription.closed = true
+		close(subscription.events)
+	}
+}
+
+// write sends a request, a failed write breaks the socket and its read loop reconnects
+func (w *socketWatcher) write(conn *websocket.Conn, request socketRequest) {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
+	if err := conn.WriteJSON(request); err != nil {
+		conn.Close()
+	}
+}
+
+// readLoop dispatches the messages of the socket to their subscriptions until it drops
+func (w *socketWatcher) readLoop(conn *websocket.Conn) {
+	for {
+		var message socketMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			break
+		}
+		w.dispatch(conn, message)
+	}
+	conn.Close()
+
+	w.mu.Lock()
+	current := w.conn == conn
+	if current {
+		w.conn = nil
+	}
+	w.mu.Unlock()
+
+	if current {
+		w.reconnect(conn)
+	}
+}
+
+// reconnect subscribes the watches of a dropped socket again on a new one
+func (w *socketWatcher) reconnect(dropped *websocket.Conn) {
+	backoff := w.client.config.ReconnectInitialBackoff
+
+	for {
+		subscriptions := w.subscribedOn(dropped)
+		if len(subscriptions) == 0 {
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > w.client.config.ReconnectMaxBackoff {
+			backoff = w.client.config.ReconnectMaxBackoff
+		}
+
+		conn, err := w.connection(context.Background())
+		if err != nil {
+			if isRetryableError(err) {
+				continue
+			}
+			for _, subscription := range subscriptions {
+				w.fail(subscription, err)
+			}
+			return
+		}
+
+		for _, subscription := range subscriptions {
+			w.subscribe(subscription, conn)
+		}
+		return
+	}
+}
+
+func (w *socketWatcher) subscribedOn(conn *websocket.Conn) []*socketSubscription {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var subscriptions []*socketSubscription
+	for _, subscription := range w.subscriptions {
+		if subscription.conn == conn {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions
+}
+
+func (w *socketWatcher) dispatch(conn *websocket.Conn, message socketMessage) {
+	w.mu.Lock()
+	subscription, ok := w.subscriptions[message.Subscription]
+	if ok && subscription.conn != conn {
+		ok = false
+	}
+	w.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if message.Type == socketMessageError {
+		var payload socketErrorPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return
+		}
+		w.handleError(conn, subscription, statusError(payload.Code, payload.errorResponse))
+		return
+	}
+
+	event, err := decodeWatchPayload(message.Type, message.Payload)
+	if err != nil {
+		return
+	}
+
+	if event.Type == WatchEventTypeConnected {
+		w.start(subscription, nil)
+	}
+
+	if resourceEvent, ok := event.Payload.(*ResourceEvent); ok && resourceEvent.Type == ResourceEventTypeError {
+		return
+	}
+
+	w.mu.Lock()
+	resumed := resumeAfter(subscription.options, messageRevision(sseMessage{}, event), event)
+	if resumed != subscription.options {
+		subscription.options = resumed
+		subscription.backoff = w.client.config.ReconnectInitialBackoff
+	}
+	w.mu.Unlock()
+
+	if !w.deliver(subscription, event) {
+		return
+	}
+
+	// the server holds back further bookmarks until the delivered one is acked
+	if bookmark, ok := event.Payload.(*BookmarkEvent); ok {
+		w.write(conn, socketRequest{Type: "ack", Subscription: subscription.id, Revision: bookmark.Revision})
+	}
+}
+
+// handleError fails the first subscribe of a watch, later the server is asked again after a backoff
+// unless the error won't go away
+func (w *socketWatcher) handleError(conn *websocket.Conn, subscription *socketSubscription, err error) {
+	if w.start(subscription, err) {
+		return
+	}
+
+	if !isRetryableError(err) {
+		w.fail(subscription, err)
+		return
+	}
+
+	w.mu.Lock()
+	backoff := subscription.backoff
+	subscription.backoff *= 2
+	if subscription.backoff > w.client.config.ReconnectMaxBackoff {
+		subscription.backoff = w.client.config.ReconnectMaxBackoff
+	}
+	w.mu.Unlock()
+
+	go func() {
+		select {
+		case <-time.After(backoff):
+		case <-subscription.ctx.Done():
+			return
+		}
+
+		// a socket dropped in the meantime subscribes its watches again on its own
+		w.mu.Lock()
+		_, active := w.subscriptions[subscription.id]
+		current := w.conn == conn && subscription.conn == conn
+		w.mu.Unlock()
+
+		if active && current {
+			w.subscribe(subscription, conn)
+		}
+	}()
+}
+
+// start reports the result of the first subscribe, false when it has already been reported
+func (w *socketWatcher) start(subscription *socketSubscription, err error) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if subscription.isStarted {
+		return false
+	}
+	subscription.isStarted = true
+	subscription.started <- err
+	return true
+}
+
+// fail ends a watch with an error event, the same as a watch over SSE that can't be reopened
+func (w *socketWatcher) fail(subscription *socketSubscription, err error) {
+	w.mu.Lock()
+	revision := subscription.options.Revision
+	w.mu.Unlock()
+
+	w.deliver(subscription, WatchEvent{
+		Type: WatchEventTypeEvent,
+		Payload: &ResourceEvent{
+			Type:      ResourceEventTypeError,
+			Timestamp: time.Now().UTC(),
+			Revision:  revision,
+			Error:     err.Error(),
+		},
+	})
+	w.remove(subscription)
+}
+
+func (w *socketWatcher) deliver(subscription *socketSubscription, event WatchEvent) bool {
+	subscription.closeMu.Lock()
+	defer subscription.closeMu.Unlock()
+
+	if subscription.closed {
+		return false
+	}
+
+	select {
+	case subscription.events <- event:
+		return true
+	case <-subscription.ctx.Done():
+		return false
+	}
+}
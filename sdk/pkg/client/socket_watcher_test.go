@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// testSocketServer records the requests of the clients, each connection is handed to serve
+type testSocketServer struct {
+	*httptest.Server
+	mu          sync.Mutex
+	connections int
+	requests    []socketRequest
+}
+
+func newTestSocketServer(t *testing.T, serve func(conn *websocket.Conn, requests <-chan socketRequest)) *testSocketServer {
+	server := &testSocketServer{}
+	upgrader := websocket.Upgrader{}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/ws" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		server.mu.Lock()
+		server.connections++
+		server.mu.Unlock()
+
+		requests := make(chan socketRequest, 10)
+		go func() {
+			defer close(requests)
+			for {
+				var request socketRequest
+				if err := conn.ReadJSON(&request); err != nil {
+					return
+				}
+				server.mu.Lock()
+				server.requests = append(server.requests, request)
+				server.mu.Unlock()
+				requests <- request
+			}
+		}()
+
+		serve(conn, requests)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *testSocketServer) recorded() (int, []socketRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections, append([]socketRequest(nil), s.requests...)
+}
+
+func newTestSocketClient(server *testSocketServer) Client {
+	config := DefaultHTTPConfig(server.URL)
+	config.ReconnectInitialBackoff = 10 * time.Millisecond
+	config.ReconnectMaxBackoff = 50 * time.Millisecond
+	config.WatchTransport = WatchTransportWebSocket
+	return NewHTTPClient(config)
+}
+
+func writeSocketMessage(conn *websocket.Conn, message string) {
+	conn.WriteMessage(websocket.TextMessage, []byte(message))
+}
+
+func receiveResourceEvent(t *testing.T, events <-chan WatchEvent) *ResourceEvent {
+	t.Helper()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatal("watch closed")
+			}
+			if resourceEvent, ok := event.Payload.(*ResourceEvent); ok {
+				return resourceEvent
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no event received")
+		}
+	}
+}
+
+func TestSocketWatcher_SharedSocket(t *testing.T) {
+	server := newTestSocketServer(t, func(conn *websocket.Conn, requests <-chan socketRequest) {
+		for request := range requests {
+			if request.Type != "subscribe" {
+				continue
+			}
+			writeSocketMessage(conn, fmt.Sprintf(`{"subscription":%q,"type":"connected","payload":{"message":"ok"}}`, request.Subscription))
+			writeSocketMessage(conn, fmt.Sprintf(`{"subscription":%q,"type":"event","payload":{"type":"added","revision":10,"objectKey":{"kind":%q,"name":"a"}}}`,
+				request.Subscription, request.Params.Kind))
+			writeSocketMessage(conn, fmt.Sprintf(`{"subscription":%q,"type":"bookmark","payload":{"revision":11}}`, request.Subscription))
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newTestSocketClient(server)
+
+	networks, err := client.WatchResource(ctx, Params{Group: "example.com", Version: "v1", Kind: "Network"}, WatchOptions{Revision: 5})
+	if err != nil {
+		t.Fatalf("WatchResource() error = %v", err)
+	}
+	subnets, err := client.WatchResource(ctx, Params{Group: "example.com", Version: "v1", Kind: "Subnet", Namespace: "default"}, WatchOptions{})
+	if err != nil {
+		t.Fatalf("WatchResource() error = %v", err)
+	}
+
+	if event := receiveResourceEvent(t, networks); event.ObjectKey.Kind != "Network" {
+		t.Errorf("networks got an event of %s", event.ObjectKey.Kind)
+	}
+	if event := receiveResourceEvent(t, subnets); event.ObjectKey.Kind != "Subnet" {
+		t.Errorf("subnets got an event of %s", event.ObjectKey.Kind)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	connections, requests := server.recorded()
+	if connections != 1 {
+		t.Errorf("watches opened %d sockets, want 1", connections)
+	}
+
+	var subscribes, acks int
+	for _, request := range requests {
+		switch request.Type {
+		case "subscribe":
+			subscribes++
+			if request.Params.Kind == "Network" && request.Revision != 5 {
+				t.Errorf("networks subscribed from revision %d, want 5", request.Revision)
+			}
+		case "ack":
+			acks++
+			if request.Revision != 11 {
+				t.Errorf("ack revision = %d, want 11", request.Revision)
+			}
+		}
+	}
+	if subscribes != 2 || acks != 2 {
+		t.Errorf("got %d subscribes and %d acks, want 2 and 2", subscribes, acks)
+	}
+}
+
+func TestSocketWatcher_Reconnect(t *testing.T) {
+	var mu sync.Mutex
+	var attempts int
+
+	server := newTestSocketServer(t, func(conn *websocket.Conn, requests <-chan socketRequest) {
+		request := <-requests
+		mu.Lock()
+		attempts++
+		attempt := attempts
+		mu.Unlock()
+
+		writeSocketMessage(conn, fmt.Sprintf(`{"subscription":%q,"type":"connected","payload":{}}`, request.Subscription))
+		writeSocketMessage(conn, fmt.Sprintf(`{"subscription":%q,"type":"event","payload":{"type":"added","revision":%d}}`, request.Subscription, attempt*10))
+		// the socket drops after the event
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := newTestSocketClient(server).WatchResource(ctx, Params{Group: "example.com", Version: "v1", Kind: "Network"}, WatchOptions{Revision: 5})
+	if err != nil {
+		t.Fatalf("WatchResource() error = %v", err)
+	}
+
+	first := receiveResourceEvent(t, events)
+	second := receiveResourceEvent(t, events)
+	if first.Revision != 10 || second.Revision != 20 {
+		t.Errorf("unexpected revisions %d, %d", first.Revision, second.Revision)
+	}
+
+	_, requests := server.recorded()
+	if requests[0].Revision != 5 || requests[1].Revision != 10 {
+		t.Errorf("subscribed from revisions %d, %d, want 5, 10", requests[0].Revision, requests[1].Revision)
+	}
+}
+
+func TestSocketWatcher_SubscribeError(t *testing.T) {
+	server := newTestSocketServer(t, func(conn *websocket.Conn, requests <-chan socketRequest) {
+		for request := range requests {
+			writeSocketMessage(conn, fmt.Sprintf(`{"subscription":%q,"type":"error","payload":{"error":"revision 3 has been compacted","code":410}}`, request.Subscription))
+		}
+	})
+
+	_, err := newTestSocketClient(server).WatchResource(context.Background(), Params{Group: "example.com", Version: "v1", Kind: "Network"}, WatchOptions{Revision: 3})
+	if !IsExpiredError(err) {
+		t.Errorf("WatchResource() error = %v, want ExpiredError", err)
+	}
+}
@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/informer"
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+// Result tells the controller what to do with a key after a successful reconcile
+type Result struct {
+	// RequeueAfter reconciles the key again after the duration, zero means no requeue
+	RequeueAfter time.Duration
+}
+
+// Reconciler drives the actual state of one object towards its desired state
+// A returned error requeues the key with exponential backoff
+type Reconciler interface {
+	Reconcile(ctx context.Context, key meta.ObjectKey) (Result, error)
+}
+
+// ReconcilerFunc adapts a plain function to Reconciler
+type ReconcilerFunc func(ctx context.Context, key meta.ObjectKey) (Result, error)
+
+func (f ReconcilerFunc) Reconcile(ctx context.Context, key meta.ObjectKey) (Result, error) {
+	return f(ctx, key)
+}
+
+// MapFunc maps a changed object to the keys that have to be reconciled
+type MapFunc func(obj *meta.Object) []meta.ObjectKey
+
+// Config holds configuration for a controller
+type Config struct {
+	Name    string
+	Workers int
+	Backoff BackoffConfig
+	// LeaderElector is optional, when set workers only run while this replica is the leader
+	LeaderElector LeaderElector
+}
+
+// DefaultConfig returns default configuration for a controller
+func DefaultConfig(name string) *Config {
+	return &Config{
+		Name:    name,
+		Workers: 1,
+		Backoff: BackoffConfig{
+			InitialBackoff:    5 * time.Millisecond,
+			MaxBackoff:        5 * time.Minute,
+			BackoffMultiplier: 2.0,
+		},
+	}
+}
+
+type watch struct {
+	informer informer.Informer
+	mapper   MapFunc
+}
+
+// Controller feeds keys of changed objects from informers into a work queue and reconciles them with a pool of workers
+type Controller struct {
+	config     *Config
+	reconciler Reconciler
+	watches    []watch
+	queueMu    sync.RWMutex
+	queue      *WorkQueue
+	logger     *log.Logger
+}
+
+func New(reconciler Reconciler, config *Config) *Controller {
+	return &Controller{
+		config:     config,
+		reconciler: reconciler,
+		logger:     log.Default(),
+	}
+}
+
+// Enqueue adds a key from a source other than informers, it is dropped while the workers are not running
+func (c *Controller) Enqueue(key meta.ObjectKey) {
+	c.queueMu.RLock()
+	defer c.queueMu.RUnlock()
+
+	if c.queue != nil {
+		c.queue.Add(key)
+	}
+}
+
+// Watch enqueues keys for every change seen by the informer, a nil mapper enqueues the key of the changed object itself
+func (c *Controller) Watch(inf informer.Informer, mapper MapFunc) {
+	if mapper == nil {
+		mapper = func(obj *meta.Object) []meta.ObjectKey {
+			return []meta.ObjectKey{*obj.ObjectKey}
+		}
+	}
+
+	enqueue := func(obj *meta.Object) {
+		for _, key := range mapper(obj) {
+			c.Enqueue(key)
+		}
+	}
+
+	c.watches = append(c.watches, watch{informer: inf, mapper: mapper})
+	inf.AddEventHandler(informer.ResourceEventHandlerFuncs{
+		AddFunc:    enqueue,
+		UpdateFunc: func(_, newObj *meta.Object) { enqueue(newObj) },
+		DeleteFunc: enqueue,
+	})
+}
+
+// OwnerMapper maps an object to the owners of the given type referenced by it
+func OwnerMapper(ownerType meta.ObjectType) MapFunc {
+	return func(obj *meta.Object) []meta.ObjectKey {
+		if obj.ObjectMeta == nil {
+			return nil
+		}
+
+		var keys []meta.ObjectKey
+		for _, ownerRef := range obj.ObjectMeta.OwnerReferences {
+			if ownerRef.TypeMeta == nil {
+				continue
+			}
+			ownerKey := ownerRef.ToObjectKey()
+			if ownerKey.Group == ownerType.Group && ownerKey.Version == ownerType.Version && ownerKey.Kind == ownerType.Kind {
+				keys = append(keys, ownerKey)
+			}
+		}
+		return keys
+	}
+}
+
+// Run blocks until ctx is done
+// With a leader elector workers are started on becoming the leader and stopped when leadership is lost
+func (c *Controller) Run(ctx context.Context) error {
+	if c.config.LeaderElector == nil {
+		c.run(ctx)
+		return nil
+	}
+
+	return c.config.LeaderElector.Run(ctx, c.run)
+}
+
+// run reconciles until ctx is done, every run starts with a fresh queue holding all objects known to the informers
+func (c *Controller) run(ctx context.Context) {
+	for _, w := range c.watches {
+		if !c.waitForSync(ctx, w.informer) {
+			return
+		}
+	}
+
+	queue := NewWorkQueue(NewItemBackoff(c.config.Backoff))
+	c.setQueue(queue)
+	defer c.setQueue(nil)
+
+	for _, w := range c.watches {
+		for _, obj := range w.informer.Store().List() {
+			for _, key := range w.mapper(obj) {
+				queue.Add(key)
+			}
+		}
+	}
+
+	c.logger.Printf("controller %s: starting %d workers", c.config.Name, c.config.Workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.worker(ctx, queue)
+		}()
+	}
+
+	<-ctx.Done()
+
+	queue.ShutDown()
+	wg.Wait()
+
+	c.logger.Printf("controller %s: workers stopped", c.config.Name)
+}
+
+func (c *Controller) waitForSync(ctx context.Context, inf informer.Informer) bool {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for !inf.HasSynced() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+func (c *Controller) setQueue(queue *WorkQueue) {
+	c.queueMu.Lock()
+	defer c.queueMu.Unlock()
+	c.queue = queue
+}
+
+func (c *Controller) worker(ctx context.Context, queue *WorkQueue) {
+	for {
+		key, shutdown := queue.Get()
+		if shutdown {
+			return
+		}
+		c.processItem(ctx, queue, key)
+	}
+}
+
+func (c *Controller) processItem(ctx context.Context, queue *WorkQueue, key meta.ObjectKey) {
+	defer queue.Done(key)
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	result, err := c.reconcile(ctx, key)
+	if err != nil {
+		c.logger.Printf("controller %s: reconcile of %s failed (attempt %d): %v",
+			c.config.Name, key.Name, queue.NumRequeues(key)+1, err)
+		queue.AddRateLimited(key)
+		return
+	}
+
+	queue.Forget(key)
+
+	if result.RequeueAfter > 0 {
+		queue.AddAfter(key, result.RequeueAfter)
+	}
+}
+
+func (c *Controller) reconcile(ctx context.Context, key meta.ObjectKey) (result Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("reconcile panicked: %v", r)
+		}
+	}()
+
+	return c.reconciler.Reconcile(ctx, key)
+}
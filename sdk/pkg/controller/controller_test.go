@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/informer"
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+func testKey(name string) meta.ObjectKey {
+	return meta.ObjectKey{
+		ObjectType: meta.ObjectType{Group: "example.com", Version: "v1", Kind: "Network"},
+		Name:       name,
+	}
+}
+
+func TestWorkQueue_Deduplicates(t *testing.T) {
+	q := NewWorkQueue(NewItemBackoff(DefaultConfig("test").Backoff))
+
+	q.Add(testKey("a"))
+	q.Add(testKey("a"))
+	q.Add(testKey("b"))
+
+	if q.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", q.Len())
+	}
+
+	key, _ := q.Get()
+	q.Add(key) // added while processing
+	if q.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1 while key is processing", q.Len())
+	}
+
+	q.Done(key)
+	if q.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2 after Done", q.Len())
+	}
+
+	q.ShutDown()
+	q.Get()
+	q.Get()
+	if _, shutdown := q.Get(); !shutdown {
+		t.Error("Get() did not report shutdown on drained queue")
+	}
+}
+
+func TestItemBackoff_Grows(t *testing.T) {
+	b := NewItemBackoff(BackoffConfig{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 40 * time.Millisecond, BackoffMultiplier: 2})
+	key := testKey("a")
+
+	var last time.Duration
+	for i := 0; i < 4; i++ {
+		last = b.NextBackoff(key)
+	}
+
+	if last < 40*time.Millisecond || last > 44*time.Millisecond {
+		t.Errorf("NextBackoff() = %v, want capped at 40ms plus jitter", last)
+	}
+
+	b.Reset(key)
+	if next := b.NextBackoff(key); next > 11*time.Millisecond {
+		t.Errorf("NextBackoff() after Reset = %v, want about 10ms", next)
+	}
+}
+
+type staticInformer struct {
+	informer.Informer
+	mu       sync.Mutex
+	handlers []informer.ResourceEventHandler
+	objects  []*meta.Object
+}
+
+func (i *staticInformer) AddEventHandler(handler informer.ResourceEventHandler) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.handlers = append(i.handlers, handler)
+}
+
+func (i *staticInformer) HasSynced() bool { return true }
+
+func (i *staticInformer) Store() informer.Store { return i }
+
+func (i *staticInformer) List() []*meta.Object { return i.objects }
+
+func (i *staticInformer) Get(meta.ObjectKey) (*meta.Object, bool) { return nil, false }
+
+func (i *staticInformer) Keys() []meta.ObjectKey { return nil }
+
+func (i *staticInformer) ByIndex(string, string) ([]*meta.Object, error) { return nil, nil }
+
+func (i *staticInformer) emitAdd(obj *meta.Object) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, handler := range i.handlers {
+		handler.OnAdd(obj)
+	}
+}
+
+func TestController_ReconcilesAndRetries(t *testing.T) {
+	key := testKey("a")
+	inf := &staticInformer{objects: []*meta.Object{{ObjectKey: &key}}}
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	done := make(chan struct{})
+
+	reconciler := ReconcilerFunc(func(ctx context.Context, key meta.ObjectKey) (Result, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[key.Name]++
+		if key.Name == "a" && calls["a"] == 1 {
+			return Result{}, errors.New("transient")
+		}
+		if calls["a"] == 2 && calls["b"] == 1 {
+			close(done)
+		}
+		return Result{}, nil
+	})
+
+	c := New(reconciler, DefaultConfig("test"))
+	c.Watch(inf, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runDone := make(chan struct{})
+	go func() {
+		defer close(runDone)
+		c.Run(ctx)
+	}()
+
+	keyB := testKey("b")
+	for {
+		c.queueMu.RLock()
+		running := c.queue != nil
+		c.queueMu.RUnlock()
+		if running {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	inf.emitAdd(&meta.Object{ObjectKey: &keyB})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("reconciles did not complete, calls = %v", calls)
+	}
+
+	cancel()
+	<-runDone
+}
@@ -0,0 +1,320 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/client"
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/schema"
+)
+
+// LeaderElector makes sure only one replica of a controller does the work at a time
+type LeaderElector interface {
+	// Run campaigns until ctx is done, onStartedLeading is called every time leadership is acquired
+	// and the context passed to it is cancelled when leadership is lost
+	Run(ctx context.Context, onStartedLeading func(ctx context.Context)) error
+}
+
+// LeaseObjectType is the kind used to store leases of controllers, LeaseSchema must be registered for it
+var LeaseObjectType = meta.ObjectType{
+	Group:   "coordination.themelio.io",
+	Version: "v1",
+	Kind:    "Lease",
+}
+
+// LeaseSchema returns the schema of the Lease kind
+func LeaseSchema() *schema.ObjectSchema {
+	return &schema.ObjectSchema{
+		Group: LeaseObjectType.Group,
+		Kind:  LeaseObjectType.Kind,
+		Scope: schema.ResourceScopeCluster,
+		Versions: []schema.ObjectSchemaVersion{
+			{
+				Name: LeaseObjectType.Version,
+				Schema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"holderIdentity":       map[string]interface{}{"type": "string"},
+								"leaseDurationSeconds": map[string]interface{}{"type": "integer"},
+								"renewTime":            map[string]interface{}{"type": "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type leaseSpec struct {
+	HolderIdentity       string    `json:"holderIdentity"`
+	LeaseDurationSeconds int64     `json:"leaseDurationSeconds"`
+	RenewTime            time.Time `json:"renewTime"`
+}
+
+// LeaseConfig holds configuration for the lease based leader elector
+type LeaseConfig struct {
+	Name          string
+	Identity      string
+	LeaseDuration time.Duration
+	RenewPeriod   time.Duration
+	RetryPeriod   time.Duration
+}
+
+// DefaultLeaseConfig returns default configuration for the lease named after the controller
+func DefaultLeaseConfig(name string) *LeaseConfig {
+	return &LeaseConfig{
+		Name:          name,
+		Identity:      NewIdentity(),
+		LeaseDuration: 15 * time.Second,
+		RenewPeriod:   5 * time.Second,
+		RetryPeriod:   2 * time.Second,
+	}
+}
+
+// NewIdentity returns an identity unique to the process
+func NewIdentity() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	return fmt.Sprintf("%s-%s", hostname, hex.EncodeToString(suffix))
+}
+
+type leaseElector struct {
+	client client.Client
+	config *LeaseConfig
+	logger *log.Logger
+}
+
+// NewLeaseElector creates a leader elector that holds a Lease object through the API
+func NewLeaseElector(c client.Client, config *LeaseConfig) LeaderElector {
+	return &leaseElector{
+		client: c,
+		config: config,
+		logger: log.Default(),
+	}
+}
+
+func (e *leaseElector) Run(ctx context.Context, onStartedLeading func(ctx context.Context)) error {
+	for {
+		acquiredAt, ok := e.acquire(ctx)
+		if !ok {
+			return nil
+		}
+
+		e.logger.Printf("lease %s: acquired by %s", e.config.Name, e.config.Identity)
+
+		leadCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			onStartedLeading(leadCtx)
+		}()
+
+		e.renew(leadCtx, acquiredAt)
+		cancel()
+		<-done
+
+		if ctx.Err() != nil {
+			e.release()
+			return nil
+		}
+
+		e.logger.Printf("lease %s: lost by %s", e.config.Name, e.config.Identity)
+	}
+}
+
+// acquire retries until the lease is held or ctx is done, it returns when the successful attempt started
+func (e *leaseElector) acquire(ctx context.Context) (time.Time, bool) {
+	for {
+		startedAt := time.Now()
+		attemptCtx, cancel := context.WithTimeout(ctx, e.config.LeaseDuration)
+		acquired, err := e.tryAcquireOrRenew(attemptCtx)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			e.logger.Printf("lease %s: failed to acquire: %v", e.config.Name, err)
+		}
+		if acquired {
+			return startedAt, true
+		}
+
+		select {
+		case <-ctx.Done():
+			return time.Time{}, false
+		case <-time.After(e.config.RetryPeriod):
+		}
+	}
+}
+
+// renew keeps the lease until ctx is done or it isn't renewed before it expires, the expiry is counted
+// from the start of the last successful attempt and ends leadership even when a call still hangs
+func (e *leaseElector) renew(ctx context.Context, renewedAt time.Time) {
+	ticker := time.NewTicker(e.config.RenewPeriod)
+	defer ticker.Stop()
+
+	for {
+		leaseCtx, cancel := context.WithDeadline(ctx, renewedAt.Add(e.config.LeaseDuration))
+		startedAt, renewed, err := e.renewOnce(leaseCtx, ticker.C)
+		expired := leaseCtx.Err() != nil && ctx.Err() == nil
+		cancel()
+
+		if renewed {
+			renewedAt = startedAt
+			continue
+		}
+
+		if ctx.Err() != nil || err == nil {
+			return // somebody else holds the lease
+		}
+
+		if expired {
+			e.logger.Printf("lease %s: expired before it was renewed: %v", e.config.Name, err)
+			return
+		}
+
+		e.logger.Printf("lease %s: failed to renew: %v", e.config.Name, err)
+	}
+}
+
+// renewOnce renews the lease at the next tick, it gives up as soon as ctx is done without waiting
+// for the call to return
+func (e *leaseElector) renewOnce(ctx context.Context, tick <-chan time.Time) (time.Time, bool, error) {
+	select {
+	case <-ctx.Done():
+		return time.Time{}, false, ctx.Err()
+	case <-tick:
+	}
+
+	type result struct {
+		renewed bool
+		err     error
+	}
+
+	startedAt := time.Now()
+	done := make(chan result, 1)
+	go func() {
+		renewed, err := e.tryAcquireOrRenew(ctx)
+		done <- result{renewed: renewed, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return startedAt, false, ctx.Err()
+	case r := <-done:
+		return startedAt, r.renewed, r.err
+	}
+}
+
+func (e *leaseElector) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.config.RetryPeriod)
+	defer cancel()
+
+	obj, spec, err := e.get(ctx)
+	if err != nil || spec == nil || spec.HolderIdentity != e.config.Identity {
+		return
+	}
+
+	spec.HolderIdentity = ""
+	if err := e.put(ctx, obj, spec); err != nil {
+		e.logger.Printf("lease %s: failed to release: %v", e.config.Name, err)
+	}
+}
+
+func (e *leaseElector) tryAcquireOrRenew(ctx context.Context) (bool, error) {
+	obj, spec, err := e.get(ctx)
+	if err != nil && !client.IsNotFoundError(err) {
+		return false, err
+	}
+
+	now := time.Now()
+	if spec != nil && spec.HolderIdentity != "" && spec.HolderIdentity != e.config.Identity {
+		expiresAt := spec.RenewTime.Add(time.Duration(spec.LeaseDurationSeconds) * time.Second)
+		if now.Before(expiresAt) {
+			return false, nil
+		}
+	}
+
+	newSpec := &leaseSpec{
+		HolderIdentity:       e.config.Identity,
+		LeaseDurationSeconds: int64(e.config.LeaseDuration.Seconds()),
+		RenewTime:            now.UTC(),
+	}
+	// a concurrent candidate that wrote the lease since it was read makes the write fail with a conflict,
+	// the race is lost then and the lease is not held
+	if err := e.put(ctx, obj, newSpec); err != nil {
+		if client.IsConflictError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (e *leaseElector) get(ctx context.Context) (*meta.Object, *leaseSpec, error) {
+	obj, err := e.client.GetResource(ctx, e.params())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := json.Marshal(obj.Spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal lease spec: %w", err)
+	}
+
+	var spec leaseSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal lease spec: %w", err)
+	}
+
+	return obj, &spec, nil
+}
+
+func (e *leaseElector) put(ctx context.Context, existing *meta.Object, spec *leaseSpec) error {
+	obj := &meta.Object{
+		ObjectKey: &meta.ObjectKey{
+			ObjectType: LeaseObjectType,
+			Name:       e.config.Name,
+		},
+		ObjectMeta: &meta.ObjectMeta{},
+		Spec:       spec,
+	}
+	// the write only succeeds at the revision that was read, a lease that was missing is only created
+	if existing != nil {
+		obj.ObjectMeta = existing.ObjectMeta
+		obj.SystemMeta = &meta.SystemMeta{ModRevision: existing.SystemMeta.ModRevision}
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lease: %w", err)
+	}
+
+	return e.client.ReplaceResource(ctx, e.params(), data)
+}
+
+func (e *leaseElector) params() client.Params {
+	return client.Params{
+		Group:   LeaseObjectType.Group,
+		Version: LeaseObjectType.Version,
+		Kind:    LeaseObjectType.Kind,
+		Name:    e.config.Name,
+	}
+}
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/client"
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+// leaseClient keeps one lease and writes it only at the revision it was read at, like the API does
+type leaseClient struct {
+	client.Client
+
+	mu       sync.Mutex
+	lease    *meta.Object
+	revision int64
+	// beforeWrite runs once before the next write is applied, so another candidate can get in between
+	beforeWrite func()
+	// hang blocks reads until it's closed, whatever the context says, when it's set
+	hang chan struct{}
+}
+
+func (c *leaseClient) GetResource(_ context.Context, _ client.Params) (*meta.Object, error) {
+	c.mu.Lock()
+	hang := c.hang
+	c.mu.Unlock()
+	if hang != nil {
+		<-hang
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lease == nil {
+		return nil, client.NewNotFoundError("lease not found")
+	}
+	obj := *c.lease
+	obj.SystemMeta = &meta.SystemMeta{ModRevision: c.revision}
+	return &obj, nil
+}
+
+func (c *leaseClient) ReplaceResource(_ context.Context, _ client.Params, data []byte) error {
+	if hook := c.beforeWrite; hook != nil {
+		c.beforeWrite = nil
+		hook()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var obj meta.Object
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	var expected int64
+	if obj.SystemMeta != nil {
+		expected = obj.SystemMeta.ModRevision
+	}
+	if (c.lease == nil && expected != 0) || (c.lease != nil && expected != c.revision) {
+		return client.NewConflictError("lease was modified")
+	}
+
+	c.revision++
+	c.lease = &obj
+	return nil
+}
+
+func (c *leaseClient) holder(t *testing.T) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.Marshal(c.lease.Spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec leaseSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+	return spec.HolderIdentity
+}
+
+func newTestElector(c client.Client, identity string) *leaseElector {
+	config := DefaultLeaseConfig("test-controller")
+	config.Identity = identity
+	return NewLeaseElector(c, config).(*leaseElector)
+}
+
+func TestLeaseElector_OnlyOneCandidateWinsTheRace(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing bool
+	}{
+		{name: "lease missing"},
+		{name: "lease expired", existing: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &leaseClient{}
+			if tt.existing {
+				c.lease = &meta.Object{
+					ObjectKey: &meta.ObjectKey{ObjectType: LeaseObjectType, Name: "test-controller"},
+					Spec: &leaseSpec{
+						HolderIdentity:       "gone",
+						LeaseDurationSeconds: 1,
+						RenewTime:            time.Now().Add(-time.Minute),
+					},
+				}
+				c.revision = 5
+			}
+			first := newTestElector(c, "first")
+			second := newTestElector(c, "second")
+			ctx := context.Background()
+
+			// Given: The second candidate takes the lease after the first one read it
+			var secondAcquired bool
+			var secondErr error
+			c.beforeWrite = func() {
+				secondAcquired, secondErr = second.tryAcquireOrRenew(ctx)
+			}
+
+			// When: The first candidate writes the lease it read
+			firstAcquired, err := first.tryAcquireOrRenew(ctx)
+
+			// Then: Only the second candidate leads
+			if err != nil || secondErr != nil {
+				t.Fatalf("unexpected errors: %v, %v", err, secondErr)
+			}
+			if firstAcquired {
+				t.Fatal("the candidate that lost the race must not lead")
+			}
+			if !secondAcquired {
+				t.Fatal("the candidate that won the race must lead")
+			}
+			if holder := c.holder(t); holder != "second" {
+				t.Fatalf("lease is held by %q", holder)
+			}
+		})
+	}
+}
+
+func TestLeaseElector_StopsLeadingWhenRenewHangs(t *testing.T) {
+	c := &leaseClient{}
+	elector := newTestElector(c, "leader")
+	elector.config.LeaseDuration = 300 * time.Millisecond
+	elector.config.RenewPeriod = 50 * time.Millisecond
+	elector.config.RetryPeriod = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hang := make(chan struct{})
+	started := make(chan time.Time, 1)
+	lost := make(chan time.Time, 1)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = elector.Run(ctx, func(leadCtx context.Context) {
+			select {
+			case started <- time.Now():
+			default:
+			}
+			<-leadCtx.Done()
+			select {
+			case lost <- time.Now():
+			default:
+			}
+		})
+	}()
+	defer func() {
+		cancel()
+		close(hang)
+		wg.Wait()
+	}()
+
+	// Given: The elector leads
+	var startedAt time.Time
+	select {
+	case startedAt = <-started:
+	case <-time.After(time.Second):
+		t.Fatal("leadership was not acquired")
+	}
+
+	// When: Every following call to the API hangs
+	c.mu.Lock()
+	c.hang = hang
+	c.mu.Unlock()
+
+	// Then: Leadership ends once the lease expires
+	select {
+	case lostAt := <-lost:
+		if held := lostAt.Sub(startedAt); held > elector.config.LeaseDuration+elector.config.RenewPeriod {
+			t.Fatalf("leadership was held for %v after the last renewal", held)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("leadership was kept while the lease could not be renewed")
+	}
+}
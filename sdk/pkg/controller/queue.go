@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+type BackoffConfig struct {
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+}
+
+// ItemBackoff tracks an exponential backoff per queue item
+type ItemBackoff struct {
+	config   BackoffConfig
+	mu       sync.Mutex
+	failures map[meta.ObjectKey]int
+}
+
+func NewItemBackoff(config BackoffConfig) *ItemBackoff {
+	return &ItemBackoff{
+		config:   config,
+		failures: make(map[meta.ObjectKey]int),
+	}
+}
+
+func (b *ItemBackoff) NextBackoff(key meta.ObjectKey) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	backoff := float64(b.config.InitialBackoff)
+	for i := 0; i < b.failures[key] && backoff < float64(b.config.MaxBackoff); i++ {
+		backoff *= b.config.BackoffMultiplier
+	}
+	if backoff > float64(b.config.MaxBackoff) {
+		backoff = float64(b.config.MaxBackoff)
+	}
+	b.failures[key]++
+
+	jitter := rand.Float64() * backoff * 0.1
+	return time.Duration(backoff + jitter)
+}
+
+func (b *ItemBackoff) Failures(key meta.ObjectKey) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures[key]
+}
+
+func (b *ItemBackoff) Reset(key meta.ObjectKey) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, key)
+}
+
+// WorkQueue is a deduplicating queue of object keys
+// A key added several times before it is picked up is processed once
+// A key added while it is being processed is queued again once Done is called for it
+type WorkQueue struct {
+	mu           sync.Mutex
+	cond         *sync.Cond
+	queue        []meta.ObjectKey
+	dirty        map[meta.ObjectKey]struct{}
+	processing   map[meta.ObjectKey]struct{}
+	backoff      *ItemBackoff
+	shuttingDown bool
+}
+
+func NewWorkQueue(backoff *ItemBackoff) *WorkQueue {
+	q := &WorkQueue{
+		dirty:      make(map[meta.ObjectKey]struct{}),
+		processing: make(map[meta.ObjectKey]struct{}),
+		backoff:    backoff,
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *WorkQueue) Add(key meta.ObjectKey) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.shuttingDown {
+		return
+	}
+
+	if _, exists := q.dirty[key]; exists {
+		return
+	}
+
+	q.dirty[key] = struct{}{}
+	if _, exists := q.processing[key]; exists {
+		return
+	}
+
+	q.queue = append(q.queue, key)
+	q.cond.Signal()
+}
+
+// AddAfter adds the key once the delay has passed
+func (q *WorkQueue) AddAfter(key meta.ObjectKey, delay time.Duration) {
+	if delay <= 0 {
+		q.Add(key)
+		return
+	}
+	time.AfterFunc(delay, func() { q.Add(key) })
+}
+
+// AddRateLimited adds the key after its backoff, the backoff grows with every call until Forget
+func (q *WorkQueue) AddRateLimited(key meta.ObjectKey) {
+	q.AddAfter(key, q.backoff.NextBackoff(key))
+}
+
+// Forget resets the backoff of the key
+func (q *WorkQueue) Forget(key meta.ObjectKey) {
+	q.backoff.Reset(key)
+}
+
+func (q *WorkQueue) NumRequeues(key meta.ObjectKey) int {
+	return q.backoff.Failures(key)
+}
+
+// Get blocks until a key is available, shutdown is true once the queue is shut down and drained
+func (q *WorkQueue) Get() (meta.ObjectKey, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.queue) == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+
+	if len(q.queue) == 0 {
+		return meta.ObjectKey{}, true
+	}
+
+	key := q.queue[0]
+	q.queue = q.queue[1:]
+
+	q.processing[key] = struct{}{}
+	delete(q.dirty, key)
+
+	return key, false
+}
+
+// Done marks the key as processed, it must be called for every key returned by Get
+func (q *WorkQueue) Done(key meta.ObjectKey) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.processing, key)
+	if _, exists := q.dirty[key]; exists {
+		q.queue = append(q.queue, key)
+		q.cond.Signal()
+	}
+}
+
+func (q *WorkQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.queue)
+}
+
+func (q *WorkQueue) ShutDown() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
@@ -0,0 +1,125 @@
+package fields
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+const (
+	FieldName         = "key.name"
+	FieldNamespace    = "key.namespace"
+	FieldDeletionTime = "system.deletionTime"
+
+	specPrefix   = "spec."
+	statusPrefix = "status."
+)
+
+// IsSupportedField reports whether the field can be selected on, spec and status paths are dot separated
+func IsSupportedField(field string) bool {
+	switch field {
+	case FieldName, FieldNamespace, FieldDeletionTime:
+		return true
+	}
+	return IsSpecOrStatusField(field)
+}
+
+func IsSpecOrStatusField(field string) bool {
+	for _, prefix := range []string{specPrefix, statusPrefix} {
+		path := strings.TrimPrefix(field, prefix)
+		if path != field && path != "" && !strings.Contains(path, "..") && !strings.HasSuffix(path, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// ObjectValue returns the value of the field as it is compared by selectors and stored in indexes
+// Strings are returned as is, other scalars in their json form and the deletion time in RFC 3339,
+// false is returned when the field is unset or holds an object or an array
+func ObjectValue(obj *meta.Object, field string) (string, bool) {
+	if obj == nil {
+		return "", false
+	}
+
+	switch field {
+	case FieldName:
+		if obj.ObjectKey == nil {
+			return "", false
+		}
+		return obj.ObjectKey.Name, obj.ObjectKey.Name != ""
+	case FieldNamespace:
+		if obj.ObjectKey == nil {
+			return "", false
+		}
+		return obj.ObjectKey.Namespace, obj.ObjectKey.Namespace != ""
+	case FieldDeletionTime:
+		if obj.SystemMeta == nil || obj.SystemMeta.DeletionTime == nil {
+			return "", false
+		}
+		return obj.SystemMeta.DeletionTime.UTC().Format(time.RFC3339Nano), true
+	}
+
+	if path := strings.TrimPrefix(field, specPrefix); path != field {
+		return pathValue(obj.Spec, path)
+	}
+	if path := strings.TrimPrefix(field, statusPrefix); path != field {
+		return pathValue(obj.Status, path)
+	}
+
+	return "", false
+}
+
+func pathValue(root interface{}, path string) (string, bool) {
+	current, ok := toGeneric(root)
+	if !ok {
+		return "", false
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		current, ok = object[segment]
+		if !ok {
+			return "", false
+		}
+	}
+
+	switch value := current.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return "", false
+	case string:
+		return value, true
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+}
+
+// toGeneric turns typed specs into the maps they are decoded into on the server
+func toGeneric(value interface{}) (interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		return object, true
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, false
+	}
+	return generic, true
+}
@@ -0,0 +1,115 @@
+package fields
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+type Operator string
+
+const (
+	OperatorEquals    Operator = "="
+	OperatorNotEquals Operator = "!="
+)
+
+// Requirement is a single condition on one field, an unset field has the empty value
+type Requirement struct {
+	Field    string
+	Operator Operator
+	Value    string
+}
+
+func (r Requirement) Matches(obj *meta.Object) bool {
+	value, _ := ObjectValue(obj, r.Field)
+
+	switch r.Operator {
+	case OperatorEquals:
+		return value == r.Value
+	case OperatorNotEquals:
+		return value != r.Value
+	default:
+		return false
+	}
+}
+
+func (r Requirement) String() string {
+	return r.Field + string(r.Operator) + r.Value
+}
+
+// Selector matches objects that satisfy all of its requirements, an empty selector matches everything
+type Selector []Requirement
+
+func (s Selector) Matches(obj *meta.Object) bool {
+	for _, r := range s {
+		if !r.Matches(obj) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selector) Empty() bool {
+	return len(s) == 0
+}
+
+func (s Selector) String() string {
+	parts := make([]string, 0, len(s))
+	for _, r := range s {
+		parts = append(parts, r.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// Parse reads a comma separated list of requirements, for example
+// "key.namespace=default,spec.region==eu-west-1,system.deletionTime!="
+func Parse(selector string) (Selector, error) {
+	var result Selector
+
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		requirement, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, requirement)
+	}
+
+	return result, nil
+}
+
+func parseRequirement(term string) (Requirement, error) {
+	if i := strings.Index(term, "!="); i >= 0 {
+		return newRequirement(term[:i], OperatorNotEquals, term[i+2:])
+	}
+
+	if i := strings.Index(term, "=="); i >= 0 {
+		return newRequirement(term[:i], OperatorEquals, term[i+2:])
+	}
+
+	if i := strings.Index(term, "="); i >= 0 {
+		return newRequirement(term[:i], OperatorEquals, term[i+1:])
+	}
+
+	return Requirement{}, fmt.Errorf("invalid requirement %q: missing operator", term)
+}
+
+func newRequirement(field string, operator Operator, value string) (Requirement, error) {
+	field = strings.TrimSpace(field)
+	value = strings.TrimSpace(value)
+
+	if !IsSupportedField(field) {
+		return Requirement{}, fmt.Errorf("unsupported field %q", field)
+	}
+
+	if strings.ContainsAny(value, "=!") {
+		return Requirement{}, fmt.Errorf("invalid value %q of field %q", value, field)
+	}
+
+	return Requirement{Field: field, Operator: operator, Value: value}, nil
+}
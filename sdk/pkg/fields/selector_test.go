@@ -0,0 +1,87 @@
+package fields
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+		wantErr  bool
+	}{
+		{selector: "", want: ""},
+		{selector: "key.name=main", want: "key.name=main"},
+		{selector: "spec.region==eu-west-1, system.deletionTime!=", want: "spec.region=eu-west-1,system.deletionTime!="},
+		{selector: "status.phase.name=ready", want: "status.phase.name=ready"},
+		{selector: "meta.labels=x", wantErr: true},
+		{selector: "spec.=x", wantErr: true},
+		{selector: "spec.region", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := Parse(tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && selector.String() != tt.want {
+				t.Errorf("Parse() = %q, want %q", selector.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSelector_Matches(t *testing.T) {
+	deletionTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	obj := &meta.Object{
+		ObjectKey:  &meta.ObjectKey{ObjectType: meta.ObjectType{Namespace: "default"}, Name: "main"},
+		SystemMeta: &meta.SystemMeta{DeletionTime: &deletionTime},
+		Spec: map[string]interface{}{
+			"region":   "eu-west-1",
+			"replicas": float64(3),
+			"network":  map[string]interface{}{"ipv6": true},
+		},
+	}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "key.name=main,key.namespace=default", want: true},
+		{selector: "system.deletionTime!=", want: true},
+		{selector: "system.deletionTime=2026-01-02T03:04:05Z", want: true},
+		{selector: "spec.region=eu-west-1,spec.replicas=3", want: true},
+		{selector: "spec.network.ipv6=true", want: true},
+		{selector: "spec.region=us-east-1", want: false},
+		{selector: "spec.zone=", want: true},
+		{selector: "status.phase!=", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := Parse(tt.selector)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := selector.Matches(obj); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObjectValue_TypedSpec(t *testing.T) {
+	type spec struct {
+		Region string `json:"region"`
+	}
+	obj := &meta.Object{Spec: spec{Region: "eu-west-1"}}
+
+	value, ok := ObjectValue(obj, "spec.region")
+	if !ok || value != "eu-west-1" {
+		t.Errorf("ObjectValue() = %q, %v, want eu-west-1", value, ok)
+	}
+}
@@ -0,0 +1,90 @@
+package informer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/client"
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+// SharedInformerFactory hands out one informer per ObjectType, so controllers watching the same kind share a single watch
+type SharedInformerFactory struct {
+	client    client.Client
+	config    *Config
+	mu        sync.Mutex
+	informers map[meta.ObjectType]*sharedInformer
+	started   map[meta.ObjectType]bool
+}
+
+func NewSharedInformerFactory(c client.Client, config *Config) *SharedInformerFactory {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	return &SharedInformerFactory{
+		client:    c,
+		config:    config,
+		informers: make(map[meta.ObjectType]*sharedInformer),
+		started:   make(map[meta.ObjectType]bool),
+	}
+}
+
+// ForType returns the shared informer of the ObjectType, creating it on first use
+func (f *SharedInformerFactory) ForType(objType meta.ObjectType) Informer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if informer, ok := f.informers[objType]; ok {
+		return informer
+	}
+
+	informer := NewInformer(f.client, objType, f.config).(*sharedInformer)
+	f.informers[objType] = informer
+	return informer
+}
+
+// Start runs every informer that has not been started yet, it can be called again after new ForType calls
+func (f *SharedInformerFactory) Start(ctx context.Context) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for objType, informer := range f.informers {
+		if f.started[objType] {
+			continue
+		}
+		f.started[objType] = true
+		go informer.Run(ctx)
+	}
+}
+
+// WaitForCacheSync blocks until every informer has completed its initial list or ctx is done
+func (f *SharedInformerFactory) WaitForCacheSync(ctx context.Context) bool {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if f.allSynced() {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *SharedInformerFactory) allSynced() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, informer := range f.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package informer
+
+import "github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+
+// ResourceEventHandler receives notifications about changes of objects in the informer's store
+// Handlers are called sequentially from the informer goroutine and must not block for long
+type ResourceEventHandler interface {
+	OnAdd(obj *meta.Object)
+	OnUpdate(oldObj, newObj *meta.Object)
+	OnDelete(obj *meta.Object)
+}
+
+// ResourceEventHandlerFuncs adapts plain functions to ResourceEventHandler, nil functions are skipped
+type ResourceEventHandlerFuncs struct {
+	AddFunc    func(obj *meta.Object)
+	UpdateFunc func(oldObj, newObj *meta.Object)
+	DeleteFunc func(obj *meta.Object)
+}
+
+func (f ResourceEventHandlerFuncs) OnAdd(obj *meta.Object) {
+	if f.AddFunc != nil {
+		f.AddFunc(obj)
+	}
+}
+
+func (f ResourceEventHandlerFuncs) OnUpdate(oldObj, newObj *meta.Object) {
+	if f.UpdateFunc != nil {
+		f.UpdateFunc(oldObj, newObj)
+	}
+}
+
+func (f ResourceEventHandlerFuncs) OnDelete(obj *meta.Object) {
+	if f.DeleteFunc != nil {
+		f.DeleteFunc(obj)
+	}
+}
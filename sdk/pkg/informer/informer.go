@@ -0,0 +1,297 @@
+package informer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/client"
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+// Config holds configuration for informers
+type Config struct {
+	RelistInitialBackoff time.Duration
+	RelistMaxBackoff     time.Duration
+	// PageSize limits the number of objects fetched by one list request, zero lists all objects at once
+	PageSize int
+}
+
+// DefaultConfig returns default configuration for informers
+func DefaultConfig() *Config {
+	return &Config{
+		RelistInitialBackoff: 1 * time.Second,
+		RelistMaxBackoff:     30 * time.Second,
+		PageSize:             500,
+	}
+}
+
+// Informer keeps a local copy of all objects of one ObjectType up to date
+// It lists the objects, then watches from the list revision and relists whenever the watch fails
+type Informer interface {
+	// AddEventHandler registers a handler, if the store is already synced the handler receives OnAdd for every object
+	// It must not be called from within a handler
+	AddEventHandler(handler ResourceEventHandler)
+	AddIndexers(indexers Indexers) error
+	Store() Store
+	// Run blocks until ctx is done
+	Run(ctx context.Context)
+	HasSynced() bool
+	LastRevision() int64
+}
+
+type sharedInformer struct {
+	client     client.Client
+	objType    meta.ObjectType
+	config     *Config
+	store      *indexedStore
+	handlersMu sync.RWMutex
+	handlers   []ResourceEventHandler
+	synced     atomic.Bool
+	revision   atomic.Int64
+}
+
+// NewInformer creates an informer for a single ObjectType
+// An empty namespace of a namespaced kind selects objects across all namespaces
+func NewInformer(c client.Client, objType meta.ObjectType, config *Config) Informer {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	return &sharedInformer{
+		client:  c,
+		objType: objType,
+		config:  config,
+		store:   newIndexedStore(),
+	}
+}
+
+func (i *sharedInformer) AddEventHandler(handler ResourceEventHandler) {
+	i.handlersMu.Lock()
+	defer i.handlersMu.Unlock()
+
+	if i.HasSynced() {
+		for _, obj := range i.store.List() {
+			handler.OnAdd(obj)
+		}
+	}
+
+	i.handlers = append(i.handlers, handler)
+}
+
+func (i *sharedInformer) AddIndexers(indexers Indexers) error {
+	return i.store.addIndexers(indexers)
+}
+
+func (i *sharedInformer) Store() Store {
+	return i.store
+}
+
+func (i *sharedInformer) HasSynced() bool {
+	return i.synced.Load()
+}
+
+func (i *sharedInformer) LastRevision() int64 {
+	return i.revision.Load()
+}
+
+func (i *sharedInformer) Run(ctx context.Context) {
+	backoff := i.config.RelistInitialBackoff
+
+	for {
+		if err := i.listAndWatch(ctx, func() { backoff = i.config.RelistInitialBackoff }); err == nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > i.config.RelistMaxBackoff {
+			backoff = i.config.RelistMaxBackoff
+		}
+	}
+}
+
+// list reads all pages, they share the revision of the first one
+func (i *sharedInformer) list(ctx context.Context, params client.Params) (*client.ObjectList, error) {
+	options := client.ListOptions{Limit: i.config.PageSize}
+	result := &client.ObjectList{}
+
+	for {
+		page, err := i.client.ListResources(ctx, params, options)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Items = append(result.Items, page.Items...)
+		result.Revision = page.Revision
+
+		if page.Continue == "" {
+			result.Total = len(result.Items)
+			return result, nil
+		}
+		options.Continue = page.Continue
+	}
+}
+
+// listAndWatch returns nil only when ctx is done, any other outcome requires a relist
+func (i *sharedInformer) listAndWatch(ctx context.Context, onListed func()) error {
+	params := i.params()
+
+	list, err := i.list(ctx, params)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	i.replace(list.Items)
+	i.revision.Store(list.Revision)
+	i.synced.Store(true)
+	onListed()
+
+	events, err := i.client.WatchResource(ctx, params, client.WatchOptions{Revision: list.Revision})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to watch objects: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("watch channel closed")
+			}
+
+			if bookmark, ok := event.Payload.(*client.BookmarkEvent); ok {
+				if bookmark.Revision > i.revision.Load() {
+					i.revision.Store(bookmark.Revision)
+				}
+				continue
+			}
+
+			resourceEvent, ok := event.Payload.(*client.ResourceEvent)
+			if !ok {
+				continue
+			}
+
+			if err := i.handleEvent(resourceEvent); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (i *sharedInformer) handleEvent(event *client.ResourceEvent) error {
+	switch event.Type {
+	case client.ResourceEventTypeAdded, client.ResourceEventTypeModified:
+		if event.Object == nil || event.Object.ObjectKey == nil {
+			return nil
+		}
+		old := i.store.put(event.Object)
+		if old == nil {
+			i.dispatchAdd(event.Object)
+		} else {
+			i.dispatchUpdate(old, event.Object)
+		}
+	case client.ResourceEventTypeDeleted:
+		key := event.ObjectKey
+		if event.Object != nil && event.Object.ObjectKey != nil {
+			key = *event.Object.ObjectKey
+		}
+		old := i.store.delete(key)
+		if old != nil {
+			i.dispatchDelete(old)
+		}
+	case client.ResourceEventTypeError:
+		return fmt.Errorf("watch error: %s", event.Error)
+	}
+
+	if event.Revision > i.revision.Load() {
+		i.revision.Store(event.Revision)
+	}
+
+	return nil
+}
+
+// replace syncs the store with a fresh list and notifies handlers about the differences
+func (i *sharedInformer) replace(objects []*meta.Object) {
+	listed := make(map[meta.ObjectKey]struct{}, len(objects))
+
+	for _, obj := range objects {
+		if obj.ObjectKey == nil {
+			continue
+		}
+		listed[*obj.ObjectKey] = struct{}{}
+
+		old := i.store.put(obj)
+		if old == nil {
+			i.dispatchAdd(obj)
+		} else if modRevision(old) != modRevision(obj) {
+			i.dispatchUpdate(old, obj)
+		}
+	}
+
+	for _, key := range i.store.Keys() {
+		if _, ok := listed[key]; ok {
+			continue
+		}
+		if old := i.store.delete(key); old != nil {
+			i.dispatchDelete(old)
+		}
+	}
+}
+
+func (i *sharedInformer) params() client.Params {
+	return client.Params{
+		Group:     i.objType.Group,
+		Version:   i.objType.Version,
+		Kind:      i.objType.Kind,
+		Namespace: i.objType.Namespace,
+	}
+}
+
+func (i *sharedInformer) dispatchAdd(obj *meta.Object) {
+	i.handlersMu.RLock()
+	defer i.handlersMu.RUnlock()
+	for _, handler := range i.handlers {
+		handler.OnAdd(obj)
+	}
+}
+
+func (i *sharedInformer) dispatchUpdate(oldObj, newObj *meta.Object) {
+	i.handlersMu.RLock()
+	defer i.handlersMu.RUnlock()
+	for _, handler := range i.handlers {
+		handler.OnUpdate(oldObj, newObj)
+	}
+}
+
+func (i *sharedInformer) dispatchDelete(obj *meta.Object) {
+	i.handlersMu.RLock()
+	defer i.handlersMu.RUnlock()
+	for _, handler := range i.handlers {
+		handler.OnDelete(obj)
+	}
+}
+
+func modRevision(obj *meta.Object) int64 {
+	if obj.SystemMeta == nil {
+		return 0
+	}
+	return obj.SystemMeta.ModRevision
+}
@@ -0,0 +1,181 @@
+package informer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/client"
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+type fakeClient struct {
+	client.Client
+	mu             sync.Mutex
+	lists          []*client.ObjectList
+	listCalls      int
+	watchRevisions []int64
+	watches        []chan client.WatchEvent
+}
+
+func (c *fakeClient) ListResources(ctx context.Context, params client.Params, options client.ListOptions) (*client.ObjectList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	list := c.lists[c.listCalls]
+	c.listCalls++
+	return list, nil
+}
+
+func (c *fakeClient) WatchResource(ctx context.Context, params client.Params, options client.WatchOptions) (<-chan client.WatchEvent, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watchRevisions = append(c.watchRevisions, options.Revision)
+	ch := make(chan client.WatchEvent, 10)
+	c.watches = append(c.watches, ch)
+	return ch, nil
+}
+
+func (c *fakeClient) watch(i int) chan client.WatchEvent {
+	for {
+		c.mu.Lock()
+		if len(c.watches) > i {
+			ch := c.watches[i]
+			c.mu.Unlock()
+			return ch
+		}
+		c.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type recordingHandler struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (h *recordingHandler) record(event string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event)
+}
+
+func (h *recordingHandler) snapshot() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.events...)
+}
+
+func (h *recordingHandler) waitFor(t *testing.T, n int) []string {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if events := h.snapshot(); len(events) >= n {
+			return events
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d events, got %v", n, h.snapshot())
+	return nil
+}
+
+func testObject(name string, modRevision int64, namespace string) *meta.Object {
+	return &meta.Object{
+		ObjectKey: &meta.ObjectKey{
+			ObjectType: meta.ObjectType{Group: "example.com", Version: "v1", Kind: "Subnet", Namespace: namespace},
+			Name:       name,
+		},
+		ObjectMeta: &meta.ObjectMeta{},
+		SystemMeta: &meta.SystemMeta{ModRevision: modRevision},
+	}
+}
+
+func TestInformer_ListWatchRelist(t *testing.T) {
+	fc := &fakeClient{
+		lists: []*client.ObjectList{
+			{Items: []*meta.Object{testObject("a", 1, "ns1"), testObject("b", 2, "ns2")}, Revision: 10},
+			{Items: []*meta.Object{testObject("a", 12, "ns1"), testObject("c", 13, "ns1")}, Revision: 15},
+		},
+	}
+
+	config := &Config{RelistInitialBackoff: time.Millisecond, RelistMaxBackoff: time.Millisecond}
+	inf := NewInformer(fc, meta.ObjectType{Group: "example.com", Version: "v1", Kind: "Subnet"}, config)
+
+	handler := &recordingHandler{}
+	inf.AddEventHandler(ResourceEventHandlerFuncs{
+		AddFunc:    func(obj *meta.Object) { handler.record("add:" + obj.ObjectKey.Name) },
+		UpdateFunc: func(_, obj *meta.Object) { handler.record("update:" + obj.ObjectKey.Name) },
+		DeleteFunc: func(obj *meta.Object) { handler.record("delete:" + obj.ObjectKey.Name) },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go inf.Run(ctx)
+
+	handler.waitFor(t, 2)
+
+	watch := fc.watch(0)
+	watch <- client.WatchEvent{Type: client.WatchEventTypeEvent, Payload: &client.ResourceEvent{
+		Type: client.ResourceEventTypeDeleted, Object: testObject("b", 2, "ns2"), Revision: 11,
+	}}
+	handler.waitFor(t, 3)
+
+	if inf.LastRevision() != 11 {
+		t.Errorf("LastRevision() = %d, want 11", inf.LastRevision())
+	}
+
+	close(watch)
+	events := handler.waitFor(t, 5)
+
+	want := []string{"add:a", "add:b", "delete:b", "update:a", "add:c"}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("events = %v, want %v", events, want)
+		}
+	}
+
+	fc.watch(1)
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if fc.watchRevisions[0] != 10 || fc.watchRevisions[1] != 15 {
+		t.Errorf("watch revisions = %v, want [10 15]", fc.watchRevisions)
+	}
+
+	objs, err := inf.Store().ByIndex(NamespaceIndex, "ns1")
+	if err != nil || len(objs) != 2 {
+		t.Errorf("ByIndex() = %v, %v, want 2 objects", objs, err)
+	}
+}
+
+func TestSharedInformerFactory_SharesInformer(t *testing.T) {
+	factory := NewSharedInformerFactory(&fakeClient{}, nil)
+	objType := meta.ObjectType{Group: "example.com", Version: "v1", Kind: "Subnet"}
+
+	if factory.ForType(objType) != factory.ForType(objType) {
+		t.Error("ForType() returned different informers for the same type")
+	}
+}
+
+func TestInformer_ListsAllPages(t *testing.T) {
+	fc := &fakeClient{
+		lists: []*client.ObjectList{
+			{Items: []*meta.Object{testObject("a", 1, "ns1")}, Revision: 10, Continue: "page-2"},
+			{Items: []*meta.Object{testObject("b", 2, "ns1")}, Revision: 10},
+		},
+	}
+
+	config := &Config{RelistInitialBackoff: time.Millisecond, RelistMaxBackoff: time.Millisecond, PageSize: 1}
+	inf := NewInformer(fc, meta.ObjectType{Group: "example.com", Version: "v1", Kind: "Subnet"}, config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go inf.Run(ctx)
+
+	fc.watch(0)
+
+	if got := len(inf.Store().List()); got != 2 {
+		t.Errorf("store has %d objects, want 2", got)
+	}
+	if inf.LastRevision() != 10 {
+		t.Errorf("LastRevision() = %d, want 10", inf.LastRevision())
+	}
+}
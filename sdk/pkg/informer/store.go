@@ -0,0 +1,174 @@
+package informer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/tsamsiyu/themelio/sdk/pkg/types/meta"
+)
+
+const (
+	// NamespaceIndex indexes objects by their namespace, it is registered on every store
+	NamespaceIndex = "namespace"
+)
+
+// IndexFunc returns the index values an object is stored under
+type IndexFunc func(obj *meta.Object) []string
+
+// Indexers maps index names to their index functions
+type Indexers map[string]IndexFunc
+
+// Store is a read-only view of the local cache kept by an informer
+type Store interface {
+	Get(key meta.ObjectKey) (*meta.Object, bool)
+	List() []*meta.Object
+	Keys() []meta.ObjectKey
+	ByIndex(indexName string, indexValue string) ([]*meta.Object, error)
+}
+
+// NamespaceIndexFunc indexes objects by namespace
+func NamespaceIndexFunc(obj *meta.Object) []string {
+	return []string{obj.ObjectKey.Namespace}
+}
+
+type indexedStore struct {
+	mu       sync.RWMutex
+	items    map[meta.ObjectKey]*meta.Object
+	indexers Indexers
+	indices  map[string]map[string]map[meta.ObjectKey]struct{}
+}
+
+func newIndexedStore() *indexedStore {
+	return &indexedStore{
+		items:    make(map[meta.ObjectKey]*meta.Object),
+		indexers: Indexers{NamespaceIndex: NamespaceIndexFunc},
+		indices: map[string]map[string]map[meta.ObjectKey]struct{}{
+			NamespaceIndex: {},
+		},
+	}
+}
+
+func (s *indexedStore) Get(key meta.ObjectKey) (*meta.Object, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.items[key]
+	return obj, ok
+}
+
+func (s *indexedStore) List() []*meta.Object {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*meta.Object, 0, len(s.items))
+	for _, obj := range s.items {
+		result = append(result, obj)
+	}
+	return result
+}
+
+func (s *indexedStore) Keys() []meta.ObjectKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]meta.ObjectKey, 0, len(s.items))
+	for key := range s.items {
+		result = append(result, key)
+	}
+	return result
+}
+
+func (s *indexedStore) ByIndex(indexName string, indexValue string) ([]*meta.Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	index, ok := s.indices[indexName]
+	if !ok {
+		return nil, fmt.Errorf("index %s does not exist", indexName)
+	}
+
+	keys := index[indexValue]
+	result := make([]*meta.Object, 0, len(keys))
+	for key := range keys {
+		result = append(result, s.items[key])
+	}
+	return result, nil
+}
+
+// addIndexers registers new indexes and indexes the objects already in the store
+func (s *indexedStore) addIndexers(indexers Indexers) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name := range indexers {
+		if _, exists := s.indexers[name]; exists {
+			return fmt.Errorf("index %s already exists", name)
+		}
+	}
+
+	for name, indexFunc := range indexers {
+		s.indexers[name] = indexFunc
+		s.indices[name] = make(map[string]map[meta.ObjectKey]struct{})
+		for key, obj := range s.items {
+			s.addToIndex(name, indexFunc, key, obj)
+		}
+	}
+
+	return nil
+}
+
+// put stores the object and returns the previous one if any
+func (s *indexedStore) put(obj *meta.Object) *meta.Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := *obj.ObjectKey
+	old := s.items[key]
+	if old != nil {
+		s.removeFromIndices(key, old)
+	}
+
+	s.items[key] = obj
+	for name, indexFunc := range s.indexers {
+		s.addToIndex(name, indexFunc, key, obj)
+	}
+
+	return old
+}
+
+// delete removes the object and returns it if it was stored
+func (s *indexedStore) delete(key meta.ObjectKey) *meta.Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.items[key]
+	if !ok {
+		return nil
+	}
+
+	s.removeFromIndices(key, old)
+	delete(s.items, key)
+
+	return old
+}
+
+func (s *indexedStore) addToIndex(name string, indexFunc IndexFunc, key meta.ObjectKey, obj *meta.Object) {
+	index := s.indices[name]
+	for _, value := range indexFunc(obj) {
+		if index[value] == nil {
+			index[value] = make(map[meta.ObjectKey]struct{})
+		}
+		index[value][key] = struct{}{}
+	}
+}
+
+func (s *indexedStore) removeFromIndices(key meta.ObjectKey, obj *meta.Object) {
+	for name, indexFunc := range s.indexers {
+		index := s.indices[name]
+		for _, value := range indexFunc(obj) {
+			delete(index[value], key)
+			if len(index[value]) == 0 {
+				delete(index, value)
+			}
+		}
+	}
+}
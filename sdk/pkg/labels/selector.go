@@ -0,0 +1,209 @@
+package labels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Operator string
+
+const (
+	OperatorEquals       Operator = "="
+	OperatorNotEquals    Operator = "!="
+	OperatorIn           Operator = "in"
+	OperatorNotIn        Operator = "notin"
+	OperatorExists       Operator = "exists"
+	OperatorDoesNotExist Operator = "!"
+)
+
+// Requirement is a single condition on one label
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Values   []string
+}
+
+func (r Requirement) Matches(labels map[string]string) bool {
+	value, exists := labels[r.Key]
+
+	switch r.Operator {
+	case OperatorEquals, OperatorIn:
+		return exists && r.hasValue(value)
+	case OperatorNotEquals, OperatorNotIn:
+		return !exists || !r.hasValue(value)
+	case OperatorExists:
+		return exists
+	case OperatorDoesNotExist:
+		return !exists
+	default:
+		return false
+	}
+}
+
+func (r Requirement) String() string {
+	switch r.Operator {
+	case OperatorEquals, OperatorNotEquals:
+		return r.Key + string(r.Operator) + r.Values[0]
+	case OperatorIn, OperatorNotIn:
+		return fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ","))
+	case OperatorExists:
+		return r.Key
+	default:
+		return "!" + r.Key
+	}
+}
+
+func (r Requirement) hasValue(value string) bool {
+	for _, v := range r.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Selector matches labels that satisfy all of its requirements, an empty selector matches everything
+type Selector []Requirement
+
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selector) Empty() bool {
+	return len(s) == 0
+}
+
+func (s Selector) String() string {
+	parts := make([]string, 0, len(s))
+	for _, r := range s {
+		parts = append(parts, r.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// SelectorFromMap builds a selector requiring every label of the map to have its value
+func SelectorFromMap(labels map[string]string) Selector {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	selector := make(Selector, 0, len(keys))
+	for _, key := range keys {
+		selector = append(selector, Requirement{Key: key, Operator: OperatorEquals, Values: []string{labels[key]}})
+	}
+	return selector
+}
+
+// Parse reads a comma separated list of requirements, for example
+// "app=billing,env!=dev,tier in (web,api),track notin (canary),release,!legacy"
+func Parse(selector string) (Selector, error) {
+	var result Selector
+
+	for _, term := range splitTerms(selector) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		requirement, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, requirement)
+	}
+
+	return result, nil
+}
+
+// splitTerms splits on commas that are not inside parentheses
+func splitTerms(selector string) []string {
+	var terms []string
+	depth, start := 0, 0
+
+	for i, ch := range selector {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(terms, selector[start:])
+}
+
+func parseRequirement(term string) (Requirement, error) {
+	if strings.HasPrefix(term, "!") && !strings.Contains(term, "=") {
+		return newRequirement(strings.TrimSpace(term[1:]), OperatorDoesNotExist, nil)
+	}
+
+	if open := strings.Index(term, "("); open >= 0 {
+		if !strings.HasSuffix(term, ")") {
+			return Requirement{}, fmt.Errorf("invalid requirement %q: missing closing parenthesis", term)
+		}
+
+		fields := strings.Fields(term[:open])
+		if len(fields) != 2 {
+			return Requirement{}, fmt.Errorf("invalid requirement %q", term)
+		}
+
+		var values []string
+		for _, value := range strings.Split(term[open+1:len(term)-1], ",") {
+			values = append(values, strings.TrimSpace(value))
+		}
+
+		switch Operator(fields[1]) {
+		case OperatorIn:
+			return newRequirement(fields[0], OperatorIn, values)
+		case OperatorNotIn:
+			return newRequirement(fields[0], OperatorNotIn, values)
+		default:
+			return Requirement{}, fmt.Errorf("invalid requirement %q: unknown operator %q", term, fields[1])
+		}
+	}
+
+	if i := strings.Index(term, "!="); i >= 0 {
+		return newRequirement(strings.TrimSpace(term[:i]), OperatorNotEquals, []string{strings.TrimSpace(term[i+2:])})
+	}
+
+	if i := strings.Index(term, "=="); i >= 0 {
+		return newRequirement(strings.TrimSpace(term[:i]), OperatorEquals, []string{strings.TrimSpace(term[i+2:])})
+	}
+
+	if i := strings.Index(term, "="); i >= 0 {
+		return newRequirement(strings.TrimSpace(term[:i]), OperatorEquals, []string{strings.TrimSpace(term[i+1:])})
+	}
+
+	return newRequirement(term, OperatorExists, nil)
+}
+
+func newRequirement(key string, operator Operator, values []string) (Requirement, error) {
+	if key == "" || strings.ContainsAny(key, " \t,()=!") {
+		return Requirement{}, fmt.Errorf("invalid label key %q", key)
+	}
+
+	for _, value := range values {
+		if strings.ContainsAny(value, " \t,()=!") {
+			return Requirement{}, fmt.Errorf("invalid value %q of label %q", value, key)
+		}
+	}
+
+	if (operator == OperatorIn || operator == OperatorNotIn) && len(values) == 0 {
+		return Requirement{}, fmt.Errorf("label %q requires at least one value", key)
+	}
+
+	return Requirement{Key: key, Operator: operator, Values: values}, nil
+}
@@ -0,0 +1,65 @@
+package labels
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+		wantErr  bool
+	}{
+		{selector: "", want: ""},
+		{selector: "app=billing", want: "app=billing"},
+		{selector: "app==billing, env!=dev", want: "app=billing,env!=dev"},
+		{selector: "tier in (web, api),track notin (canary)", want: "tier in (web,api),track notin (canary)"},
+		{selector: "release,!legacy", want: "release,!legacy"},
+		{selector: "app.kubernetes.io/name=web", want: "app.kubernetes.io/name=web"},
+		{selector: "tier in (web", wantErr: true},
+		{selector: "tier within (web)", wantErr: true},
+		{selector: "=billing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := Parse(tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && selector.String() != tt.want {
+				t.Errorf("Parse() = %q, want %q", selector.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSelector_Matches(t *testing.T) {
+	labels := map[string]string{"app": "billing", "tier": "web"}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "", want: true},
+		{selector: "app=billing", want: true},
+		{selector: "app=shipping", want: false},
+		{selector: "app!=shipping", want: true},
+		{selector: "env!=prod", want: true},
+		{selector: "tier in (web,api)", want: true},
+		{selector: "tier notin (web)", want: false},
+		{selector: "env notin (prod)", want: true},
+		{selector: "app,!env", want: true},
+		{selector: "app,env", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := Parse(tt.selector)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := selector.Matches(labels); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
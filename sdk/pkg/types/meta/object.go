@@ -1,6 +1,12 @@
 package meta
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
+
+// ObservedGenerationField is the status field a controller sets to the generation of the spec it has reconciled
+const ObservedGenerationField = "observedGeneration"
 
 type ObjectType struct {
 	Group     string `json:"group" validate:"required"`
@@ -24,13 +30,28 @@ type ObjectMeta struct {
 type SystemMeta struct {
 	UID            string     `json:"uid"`
 	Version        int64      `json:"version"`
+	Generation     int64      `json:"generation"` // incremented by the server on every spec change
 	CreateRevision int64      `json:"createRevision"`
 	ModRevision    int64      `json:"modRevision"`
 	CreationTime   *time.Time `json:"creationTime"`
 	LastUpdateTime *time.Time `json:"lastUpdateTime"`
 	DeletionTime   *time.Time `json:"deletionTime"`
+	// PropagationPolicy is set together with DeletionTime, it tells what happens to the children of the object
+	PropagationPolicy PropagationPolicy `json:"propagationPolicy,omitempty"`
 }
 
+// PropagationPolicy decides what happens to the children of a deleted object
+type PropagationPolicy string
+
+const (
+	// PropagationPolicyBackground deletes the object right away and its children after it
+	PropagationPolicyBackground PropagationPolicy = "Background"
+	// PropagationPolicyForeground keeps the object until the children blocking its deletion are gone
+	PropagationPolicyForeground PropagationPolicy = "Foreground"
+	// PropagationPolicyOrphan deletes the object and keeps its children, their references to it are removed
+	PropagationPolicyOrphan PropagationPolicy = "Orphan"
+)
+
 type OwnerReference struct {
 	TypeMeta           *ObjectType `json:"typeMeta" validate:"required"`
 	Name               string      `json:"name" validate:"required"`
@@ -57,3 +78,30 @@ type Object struct {
 	Spec       interface{} `json:"spec"`
 	Status     interface{} `json:"status"`
 }
+
+// ObservedGeneration returns the generation reported by the status, false if the status doesn't report it
+func (o *Object) ObservedGeneration() (int64, bool) {
+	if o.Status == nil {
+		return 0, false
+	}
+
+	data, err := json.Marshal(o.Status)
+	if err != nil {
+		return 0, false
+	}
+
+	var status struct {
+		ObservedGeneration *int64 `json:"observedGeneration"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil || status.ObservedGeneration == nil {
+		return 0, false
+	}
+
+	return *status.ObservedGeneration, true
+}
+
+// IsObserved reports whether the status has caught up with the latest spec
+func (o *Object) IsObserved() bool {
+	observed, ok := o.ObservedGeneration()
+	return ok && o.SystemMeta != nil && observed >= o.SystemMeta.Generation
+}
@@ -0,0 +1,49 @@
+package meta
+
+import "testing"
+
+func TestObject_ObservedGeneration(t *testing.T) {
+	tests := []struct {
+		name         string
+		obj          Object
+		wantObserved int64
+		wantOK       bool
+		wantIsLatest bool
+	}{
+		{
+			name: "no status",
+			obj:  Object{SystemMeta: &SystemMeta{Generation: 1}},
+		},
+		{
+			name: "status without observed generation",
+			obj:  Object{SystemMeta: &SystemMeta{Generation: 1}, Status: map[string]interface{}{"ready": true}},
+		},
+		{
+			name:         "outdated status",
+			obj:          Object{SystemMeta: &SystemMeta{Generation: 3}, Status: map[string]interface{}{"observedGeneration": 2}},
+			wantObserved: 2,
+			wantOK:       true,
+		},
+		{
+			name: "typed status of latest generation",
+			obj: Object{SystemMeta: &SystemMeta{Generation: 3}, Status: struct {
+				ObservedGeneration int64 `json:"observedGeneration"`
+			}{ObservedGeneration: 3}},
+			wantObserved: 3,
+			wantOK:       true,
+			wantIsLatest: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observed, ok := tt.obj.ObservedGeneration()
+			if observed != tt.wantObserved || ok != tt.wantOK {
+				t.Errorf("ObservedGeneration() = %d, %v, want %d, %v", observed, ok, tt.wantObserved, tt.wantOK)
+			}
+			if got := tt.obj.IsObserved(); got != tt.wantIsLatest {
+				t.Errorf("IsObserved() = %v, want %v", got, tt.wantIsLatest)
+			}
+		})
+	}
+}
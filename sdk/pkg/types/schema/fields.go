@@ -0,0 +1,110 @@
+package schema
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// IndexMarker marks a spec or status property of a version schema the server keeps an index for,
+// field selectors requiring such a property to equal a value are answered without scanning
+//
+//	"region": {"type": "string", "x-themelio-index": true}
+const IndexMarker = "x-themelio-index"
+
+var indexableRoots = []string{"spec", "status"}
+
+// Version returns the schema of the version with the name
+func (s *ObjectSchema) Version(name string) (*ObjectSchemaVersion, bool) {
+	for i := range s.Versions {
+		if s.Versions[i].Name == name {
+			return &s.Versions[i], true
+		}
+	}
+	return nil, false
+}
+
+// HasField reports whether the dot separated spec or status path is declared by the schema properties
+func (v ObjectSchemaVersion) HasField(field string) bool {
+	return v.property(field) != nil
+}
+
+// IndexedFields returns the sorted dot separated paths of the properties carrying IndexMarker
+func (v ObjectSchemaVersion) IndexedFields() []string {
+	root, ok := toObject(v.Schema)
+	if !ok {
+		return nil
+	}
+
+	var fields []string
+	for _, name := range indexableRoots {
+		if property, ok := properties(root)[name]; ok {
+			fields = collectIndexed(property, name, fields)
+		}
+	}
+
+	sort.Strings(fields)
+	return fields
+}
+
+func (v ObjectSchemaVersion) property(field string) map[string]interface{} {
+	current, ok := toObject(v.Schema)
+	if !ok {
+		return nil
+	}
+
+	segments := strings.Split(field, ".")
+	if len(segments) < 2 || (segments[0] != "spec" && segments[0] != "status") {
+		return nil
+	}
+
+	for _, segment := range segments {
+		current, ok = properties(current)[segment].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+	}
+	return current
+}
+
+func collectIndexed(property interface{}, path string, fields []string) []string {
+	object, ok := property.(map[string]interface{})
+	if !ok {
+		return fields
+	}
+
+	if marked, _ := object[IndexMarker].(bool); marked {
+		fields = append(fields, path)
+	}
+
+	for name, child := range properties(object) {
+		fields = collectIndexed(child, path+"."+name, fields)
+	}
+	return fields
+}
+
+func properties(object map[string]interface{}) map[string]interface{} {
+	props, _ := object["properties"].(map[string]interface{})
+	return props
+}
+
+// toObject turns typed schemas into the maps they are decoded into on the server
+func toObject(value interface{}) (map[string]interface{}, bool) {
+	if object, ok := value.(map[string]interface{}); ok {
+		return object, true
+	}
+	if value == nil {
+		return nil, false
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, false
+	}
+	return object, true
+}
@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestObjectSchemaVersion_Fields(t *testing.T) {
+	var version ObjectSchemaVersion
+	err := json.Unmarshal([]byte(`{
+		"name": "v1",
+		"schema": {
+			"type": "object",
+			"properties": {
+				"spec": {
+					"type": "object",
+					"properties": {
+						"region": {"type": "string", "x-themelio-index": true},
+						"network": {
+							"type": "object",
+							"properties": {"cidr": {"type": "string", "x-themelio-index": true}}
+						},
+						"replicas": {"type": "integer"}
+					}
+				},
+				"status": {
+					"type": "object",
+					"properties": {"phase": {"type": "string", "x-themelio-index": true}}
+				}
+			}
+		}
+	}`), &version)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	want := []string{"spec.network.cidr", "spec.region", "status.phase"}
+	if got := version.IndexedFields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("IndexedFields() = %v, want %v", got, want)
+	}
+
+	for field, want := range map[string]bool{
+		"spec.replicas":     true,
+		"spec.network.cidr": true,
+		"status.phase":      true,
+		"spec.zone":         false,
+		"spec":              false,
+		"meta.labels":       false,
+	} {
+		if got := version.HasField(field); got != want {
+			t.Errorf("HasField(%q) = %v, want %v", field, got, want)
+		}
+	}
+}